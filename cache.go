@@ -1,6 +1,12 @@
 package go_cache
 
 import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand"
 	"sync"
 	"time"
@@ -8,15 +14,49 @@ import (
 	"github.com/wk331100/go-cache/types"
 )
 
+// Loader 全局的加载函数，GetOrLoad 没有传入加载函数时使用
+type Loader func(ctx context.Context, k string) (any, error)
+
+// Option 创建缓存服务的可选配置
+type Option func(c *Cache)
+
+// WithLoader 设置全局的加载函数
+func WithLoader(loader Loader) Option {
+	return func(c *Cache) {
+		c.loader = loader
+	}
+}
+
+// WithNegativeTTL 设置加载失败时缓存错误的时长，期间 GetOrLoad 直接返回该错误，为0时不缓存
+func WithNegativeTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		c.negativeTTL = d
+	}
+}
+
 // NewCache 创建新的缓存服务
-func NewCache() *Cache {
+func NewCache(opts ...Option) *Cache {
 	c := &Cache{
-		keyMap:  make(map[string]types.KeyType),
-		strings: types.NewStrings(),
-		lists:   types.NewLists(),
-		hashes:  types.NewHashes(),
-		sets:    types.NewSets(),
-		zSets:   types.NewZSets(),
+		keyMap:           make(map[string]types.KeyType),
+		strings:          types.NewStrings(),
+		lists:            types.NewLists(),
+		hashes:           types.NewHashes(),
+		sets:             types.NewSets(),
+		zSets:            types.NewZSets(),
+		hyperLogLogs:     types.NewHyperLogLogs(),
+		streams:          types.NewStreams(),
+		blooms:           types.NewBloomFilters(),
+		cuckoos:          types.NewCuckooFilters(),
+		countMinSketches: types.NewCountMinSketches(),
+		topKs:            types.NewTopKs(),
+		jsons:            types.NewJSONs(),
+		timeSeries:       types.NewTimeSeriesSet(),
+		rateLimiters:     types.NewRateLimiters(),
+		loads:            make(map[string]*loadCall),
+		negatives:        make(map[string]*negativeEntry),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 	c.gc = newRandomGC(c)
 	go c.gc.Clean()
@@ -26,14 +66,30 @@ func NewCache() *Cache {
 // Cache 缓存结构
 // items 为string类型的映射
 type Cache struct {
-	mu      sync.Mutex
-	gc      GC
-	keyMap  map[string]types.KeyType
-	strings *types.Strings
-	lists   *types.Lists
-	hashes  *types.Hashes
-	sets    *types.Sets
-	zSets   *types.ZSets
+	mu               sync.Mutex
+	gc               GC
+	keyMap           map[string]types.KeyType
+	strings          *types.Strings
+	lists            *types.Lists
+	hashes           *types.Hashes
+	sets             *types.Sets
+	zSets            *types.ZSets
+	hyperLogLogs     *types.HyperLogLogs
+	streams          *types.Streams
+	blooms           *types.BloomFilters
+	cuckoos          *types.CuckooFilters
+	countMinSketches *types.CountMinSketches
+	topKs            *types.TopKs
+	jsons            *types.JSONs
+	timeSeries       *types.TimeSeriesSet
+	rateLimiters     *types.RateLimiters
+
+	// GetOrLoad 相关，loads 为正在加载的key，negatives 为缓存的加载错误，都由loadMu保护
+	loader      Loader
+	negativeTTL time.Duration
+	loadMu      sync.Mutex
+	loads       map[string]*loadCall
+	negatives   map[string]*negativeEntry
 }
 
 // destroy 摧毁缓存
@@ -44,22 +100,48 @@ func (c *Cache) destroy() {
 
 // ======== 字符串 =======
 
-// Set 缓存k的值为v
+// Set 缓存k的值为v，并清除原有的过期时间
 func (c *Cache) Set(k string, v any) {
 	if exist := c.strings.Set(k, v); !exist {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		c.keyMap[k] = types.TypeString
+		c.storeKey(k, types.TypeString, 1)
 	}
 }
 
 // SetEx 缓存k的值为v,并且设置超时时间d
 func (c *Cache) SetEx(k string, v any, d time.Duration) {
 	if exist := c.strings.SetEx(k, v, d); !exist {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		c.keyMap[k] = types.TypeString
+		c.storeKey(k, types.TypeString, 1)
+	}
+}
+
+// SetNX k不存在时，缓存k的值为v，k为其他类型时也视为存在
+// return bool 表示是否写入
+func (c *Cache) SetNX(k string, v any) bool {
+	if c.existOther(k, types.TypeString) {
+		return false
+	}
+	set := c.strings.SetNX(k, v)
+	if set {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return set
+}
+
+// SetArgs 按照args中的条件（NX/XX）和过期时间（EX/PX/EXAT/KEEPTTL）缓存k的值为v
+// k为其他类型时NX/XX也视为k存在，XX写入时覆盖原有的数据
+// return any 为args.Get时写入前的值，k不存在时为nil；bool 表示是否写入
+func (c *Cache) SetArgs(k string, v any, args types.SetArgs) (any, bool, error) {
+	if (args.Mode == types.SetModeNX || args.Mode == types.SetModeXX) && c.existOther(k, types.TypeString) {
+		if args.Mode == types.SetModeNX {
+			return nil, false, nil
+		}
+		args.Mode = ""
+	}
+	old, set, exist, err := c.strings.SetArgs(k, v, args)
+	if set && !exist {
+		c.storeKey(k, types.TypeString, 1)
 	}
+	return old, set, err
 }
 
 // Get 获取一个string类型值
@@ -67,35 +149,186 @@ func (c *Cache) Get(k string) (any, error) {
 	return c.strings.Get(k)
 }
 
-// Incr 对k计数+1
-func (c *Cache) Incr(k string) {
-	c.strings.Incr(k)
+// GetSet 缓存k的值为v，并返回原有的值，k不存在时返回nil
+func (c *Cache) GetSet(k string, v any) any {
+	old, exist := c.strings.GetSet(k, v)
+	if !exist {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return old
+}
+
+// GetDel 获取k的值并删除k
+func (c *Cache) GetDel(k string) (any, error) {
+	return c.strings.GetDel(k)
+}
+
+// GetEx 获取k的值，并按照args修改过期时间（EX/PX/EXAT/PERSIST）
+func (c *Cache) GetEx(k string, args types.GetExArgs) (any, error) {
+	return c.strings.GetEx(k, args)
+}
+
+// MSet 在一次操作中缓存多个k的值
+func (c *Cache) MSet(values map[string]any) {
+	for _, k := range c.strings.MSet(values) {
+		c.storeKey(k, types.TypeString, 1)
+	}
+}
+
+// MSetNX 所有k都不存在时，在一次操作中缓存多个k的值，k为其他类型时也视为存在
+// return bool 表示是否写入
+func (c *Cache) MSetNX(values map[string]any) bool {
+	for k := range values {
+		if c.existOther(k, types.TypeString) {
+			return false
+		}
+	}
+	set := c.strings.MSetNX(values)
+	if set {
+		for k := range values {
+			c.storeKey(k, types.TypeString, 1)
+		}
+	}
+	return set
+}
+
+// MGet 在一次操作中获取多个k的值，不存在的k对应的值为nil
+func (c *Cache) MGet(keys ...string) []any {
+	return c.strings.MGet(keys...)
+}
+
+// Append 在k的值末尾追加v，k不存在时缓存k的值为v
+// v 和原有的值需为string或[]byte，否则返回 ErrNotString
+// return int 为追加后的长度
+func (c *Cache) Append(k string, v any) (int, error) {
+	n, exist, err := c.strings.Append(k, v)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return n, err
+}
+
+// StrLen 获取k的值的长度，k不存在时返回0
+func (c *Cache) StrLen(k string) (int, error) {
+	return c.strings.StrLen(k)
+}
+
+// GetRange 获取k的值中[start, end]区间的内容，支持负数下标
+func (c *Cache) GetRange(k string, start, end int) (string, error) {
+	return c.strings.GetRange(k, start, end)
+}
+
+// SetRange 从offset开始使用v覆盖k的值，长度不足时使用0字节填充
+// return int 为修改后的长度
+func (c *Cache) SetRange(k string, offset int, v any) (int, error) {
+	n, exist, err := c.strings.SetRange(k, offset, v)
+	if err == nil && !exist && n > 0 {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return n, err
+}
+
+// Incr 对k计数+1，k不存在时从0开始
+// 原有的值需为整数或可以解析为整数的字符串，否则返回 ErrNotInteger，溢出时返回 ErrOverflow
+func (c *Cache) Incr(k string) (int64, error) {
+	return c.incrBy(k, 1)
 }
 
 // Decr 对k计数-1
-func (c *Cache) Decr(k string) {
-	c.strings.Decr(k)
+func (c *Cache) Decr(k string) (int64, error) {
+	return c.incrBy(k, -1)
 }
 
 // IncrBy 对k计数+v
-func (c *Cache) IncrBy(k string, v int64) {
-	c.strings.IncrBy(k, v)
+func (c *Cache) IncrBy(k string, v int64) (int64, error) {
+	return c.incrBy(k, v)
 }
 
 // DecrBy 对k计数-v
-func (c *Cache) DecrBy(k string, v int64) {
-	c.strings.DecrBy(k, v)
+func (c *Cache) DecrBy(k string, v int64) (int64, error) {
+	n, exist, err := c.strings.DecrBy(k, v)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return n, err
+}
+
+// IncrByFloat 对k的浮点数计数+v，k不存在时从0开始
+// 原有的值需为数字或可以解析为数字的字符串，否则返回 ErrNotFloat
+func (c *Cache) IncrByFloat(k string, v float64) (float64, error) {
+	f, exist, err := c.strings.IncrByFloat(k, v)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return f, err
+}
+
+// ======== 位图 =======
+
+// SetBit 设置k的值中offset位置的位为value（0或1），长度不足时自动扩展
+// return int 为原来的位
+func (c *Cache) SetBit(k string, offset int64, value int) (int, error) {
+	old, exist, err := c.strings.SetBit(k, offset, value)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return old, err
+}
+
+// GetBit 获取k的值中offset位置的位，超出长度或k不存在时为0
+func (c *Cache) GetBit(k string, offset int64) (int, error) {
+	return c.strings.GetBit(k, offset)
+}
+
+// BitCount 统计k的值中为1的位的数量，r 为nil时统计全部，可以按字节或按位指定区间
+func (c *Cache) BitCount(k string, r *types.BitRange) (int64, error) {
+	return c.strings.BitCount(k, r)
+}
+
+// BitPos 查找k的值中第一个值为bit的位的位置，未找到时返回-1
+func (c *Cache) BitPos(k string, bit int, r *types.BitRange) (int64, error) {
+	return c.strings.BitPos(k, bit, r)
+}
+
+// BitOp 对多个k的值进行AND、OR、XOR或NOT运算，结果存储到dst中
+// return int 为dst中值的长度
+func (c *Cache) BitOp(op types.BitOp, dst string, keys ...string) (int, error) {
+	n, err := c.strings.BitOp(op, dst, keys...)
+	if err != nil {
+		return 0, err
+	}
+	c.storeKey(dst, types.TypeString, n)
+	return n, nil
+}
+
+// BitField 对k的值中任意位置、任意宽度的整数字段依次执行GET、SET、INCRBY操作
+// 返回每个操作的结果，溢出方式为FAIL且发生溢出时结果为nil
+func (c *Cache) BitField(k string, ops ...types.BitFieldOp) ([]any, error) {
+	result, exist, err := c.strings.BitField(k, ops...)
+	if err == nil && !exist && c.strings.Exist(k) {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return result, err
 }
 
 // ======== 列表 =======
 
-// LPush 从队列k的头部，添加一个元素v
-func (c *Cache) LPush(k string, v any) {
-	if exist := c.lists.LPush(k, v); !exist {
+// LPush 从队列k的头部，依次添加元素vs，所有元素在一次操作中原子地添加
+// return int 为添加后队列的长度
+func (c *Cache) LPush(k string, vs ...any) int {
+	n, exist := c.lists.LPush(k, vs...)
+	if !exist && n > 0 {
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		c.keyMap[k] = types.TypeList
 	}
+	return n
+}
+
+// LPushX 队列k存在时，从头部依次添加元素vs
+// return int 为添加后队列的长度，k不存在时返回0
+func (c *Cache) LPushX(k string, vs ...any) int {
+	return c.lists.LPushX(k, vs...)
 }
 
 // LPop 从队列k的头部，弹出一个元素
@@ -103,13 +336,27 @@ func (c *Cache) LPop(k string) (any, error) {
 	return c.lists.LPop(k)
 }
 
-// RPush 从队列k的尾部，添加一个元素
-func (c *Cache) RPush(k string, v any) {
-	if exist := c.lists.RPush(k, v); !exist {
+// LPopCount 从队列k的头部，弹出最多count个元素
+func (c *Cache) LPopCount(k string, count int) ([]any, error) {
+	return c.lists.LPopCount(k, count)
+}
+
+// RPush 从队列k的尾部，依次添加元素vs，所有元素在一次操作中原子地添加
+// return int 为添加后队列的长度
+func (c *Cache) RPush(k string, vs ...any) int {
+	n, exist := c.lists.RPush(k, vs...)
+	if !exist && n > 0 {
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		c.keyMap[k] = types.TypeList
 	}
+	return n
+}
+
+// RPushX 队列k存在时，从尾部依次添加元素vs
+// return int 为添加后队列的长度，k不存在时返回0
+func (c *Cache) RPushX(k string, vs ...any) int {
+	return c.lists.RPushX(k, vs...)
 }
 
 // RPop 从队列k的尾部，弹出一个元素
@@ -117,16 +364,98 @@ func (c *Cache) RPop(k string) (any, error) {
 	return c.lists.RPop(k)
 }
 
+// RPopCount 从队列k的尾部，弹出最多count个元素
+func (c *Cache) RPopCount(k string, count int) ([]any, error) {
+	return c.lists.RPopCount(k, count)
+}
+
+// BLPop 从多个队列的头部弹出一个元素，所有队列都为空时阻塞等待
+// timeout 为0时一直等待，直到ctx结束；超时返回 types.ErrTimeout
+// 多个调用方等待同一个队列时，按等待的先后顺序获取元素
+func (c *Cache) BLPop(ctx context.Context, timeout time.Duration, keys ...string) (string, any, error) {
+	return c.lists.BLPop(ctx, timeout, keys...)
+}
+
+// BRPop 从多个队列的尾部弹出一个元素，所有队列都为空时阻塞等待
+// timeout 为0时一直等待，直到ctx结束；超时返回 types.ErrTimeout
+func (c *Cache) BRPop(ctx context.Context, timeout time.Duration, keys ...string) (string, any, error) {
+	return c.lists.BRPop(ctx, timeout, keys...)
+}
+
+// BLMove 从队列src的whereFrom端弹出一个元素，添加到队列dst的whereTo端，src为空时阻塞等待
+// timeout 为0时一直等待，直到ctx结束；超时返回 types.ErrTimeout
+func (c *Cache) BLMove(ctx context.Context, src, dst string, whereFrom, whereTo types.ListDirection, timeout time.Duration) (any, error) {
+	v, err := c.lists.BLMove(ctx, src, dst, whereFrom, whereTo, timeout)
+	if err != nil {
+		return nil, err
+	}
+	c.storeKey(dst, types.TypeList, 1)
+	return v, nil
+}
+
 // LLen 获取队列k的长度
 func (c *Cache) LLen(k string) int {
 	return c.lists.LLen(k)
 }
 
-// LRange 获取队列元素列表
+// LRange 获取队列[start, stop]区间内的元素
+// 返回的切片为副本，区间超出范围时按Redis的规则截断，区间为空时返回空切片
 func (c *Cache) LRange(k string, start, stop int) ([]any, error) {
 	return c.lists.LRange(k, start, stop)
 }
 
+// LIndex 获取队列中下标为index的元素，index为负数时从尾部开始计算
+func (c *Cache) LIndex(k string, index int) (any, error) {
+	return c.lists.LIndex(k, index)
+}
+
+// LSet 设置队列中下标为index的元素
+func (c *Cache) LSet(k string, index int, v any) error {
+	return c.lists.LSet(k, index, v)
+}
+
+// LInsert 在队列中第一个等于pivot的元素之前或之后插入元素v
+// return int 为插入后队列的长度，k不存在时返回0，pivot不存在时返回-1
+func (c *Cache) LInsert(k string, where types.ListPosition, pivot, v any) (int, error) {
+	return c.lists.LInsert(k, where, pivot, v)
+}
+
+// LRem 从队列中删除count个等于v的元素
+// count 大于0时从头部开始删除，小于0时从尾部开始删除，等于0时删除全部
+func (c *Cache) LRem(k string, count int, v any) int {
+	return c.lists.LRem(k, count, v)
+}
+
+// LTrim 只保留队列中[start, stop]区间内的元素
+func (c *Cache) LTrim(k string, start, stop int) {
+	c.lists.LTrim(k, start, stop)
+}
+
+// LPos 获取队列中等于v的元素的下标，不存在时返回-1
+func (c *Cache) LPos(k string, v any, args types.LPosArgs) (int, error) {
+	return c.lists.LPos(k, v, args)
+}
+
+// LPosCount 获取队列中最多count个等于v的元素的下标，count为0时返回全部
+func (c *Cache) LPosCount(k string, v any, count int, args types.LPosArgs) ([]int, error) {
+	return c.lists.LPosCount(k, v, count, args)
+}
+
+// LMove 从队列src的whereFrom端弹出一个元素，原子地添加到队列dst的whereTo端
+func (c *Cache) LMove(src, dst string, whereFrom, whereTo types.ListDirection) (any, error) {
+	v, err := c.lists.LMove(src, dst, whereFrom, whereTo)
+	if err != nil {
+		return nil, err
+	}
+	c.storeKey(dst, types.TypeList, 1)
+	return v, nil
+}
+
+// RPopLPush 从队列src的尾部弹出一个元素，原子地添加到队列dst的头部
+func (c *Cache) RPopLPush(src, dst string) (any, error) {
+	return c.LMove(src, dst, types.ListRight, types.ListLeft)
+}
+
 // ======== 散列Hash =======
 
 // HSet 缓存数据到Hash中
@@ -145,9 +474,100 @@ func (c *Cache) HGet(k, field string) (any, error) {
 	return c.hashes.HGet(k, field)
 }
 
-// HDel 从Hash中删除元素field
-func (c *Cache) HDel(k, field string) {
-	c.hashes.HDel(k, field)
+// HMSet 批量缓存数据到Hash中
+// return int 为新增field的数量
+func (c *Cache) HMSet(k string, values map[string]any) int {
+	added, exist := c.hashes.HMSet(k, values)
+	if !exist && added > 0 {
+		c.storeKey(k, types.TypeHash, 1)
+	}
+	return added
+}
+
+// HSetNX field不存在时，缓存数据到Hash中
+// return bool 表示是否写入
+func (c *Cache) HSetNX(k, field string, v any) bool {
+	set, exist := c.hashes.HSetNX(k, field, v)
+	if !exist {
+		c.storeKey(k, types.TypeHash, 1)
+	}
+	return set
+}
+
+// HMGet 从Hash中批量获取存储的元素，不存在的field对应的值为nil
+func (c *Cache) HMGet(k string, fields ...string) ([]any, error) {
+	return c.hashes.HMGet(k, fields...)
+}
+
+// HGetAll 获取Hash中所有的field和内容，返回的map为副本
+func (c *Cache) HGetAll(k string) (map[string]any, error) {
+	return c.hashes.HGetAll(k)
+}
+
+// HLen 获取Hash中field的数量
+func (c *Cache) HLen(k string) int {
+	return c.hashes.HLen(k)
+}
+
+// HStrLen 获取Hash中field的值的长度，k或field不存在时返回0
+// 值不是string或[]byte时返回 types.ErrNotString
+func (c *Cache) HStrLen(k, field string) (int, error) {
+	return c.hashes.HStrLen(k, field)
+}
+
+// HIncrBy 对Hash中field的计数+incr，field不存在时从0开始
+// field的值不是整数时返回 types.ErrNotInteger，溢出时返回 types.ErrOverflow
+func (c *Cache) HIncrBy(k, field string, incr int64) (int64, error) {
+	n, exist, err := c.hashes.HIncrBy(k, field, incr)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeHash, 1)
+	}
+	return n, err
+}
+
+// HIncrByFloat 对Hash中field的浮点数计数+incr，field不存在时从0开始
+// field的值不是数字或结果为NaN、Inf时返回 types.ErrNotFloat
+func (c *Cache) HIncrByFloat(k, field string, incr float64) (float64, error) {
+	f, exist, err := c.hashes.HIncrByFloat(k, field, incr)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeHash, 1)
+	}
+	return f, err
+}
+
+// HRandField 从Hash中随机获取count个field，count为负数时field可能重复
+func (c *Cache) HRandField(k string, count int) ([]string, error) {
+	return c.hashes.HRandField(k, count)
+}
+
+// HDel 从Hash中删除多个field，Hash为空时删除k
+// return int 为删除的field数量
+func (c *Cache) HDel(k string, fields ...string) int {
+	return c.hashes.HDel(k, fields...)
+}
+
+// HExpire 设置Hash中多个field的超时时间，对应redis的HEXPIRE、HPEXPIRE
+// 返回每个field的结果：types.FieldNotExist、types.FieldExpireSet 或 types.FieldExpired
+// 过期的field会在访问时或由GC清理，所有field都过期后删除k
+func (c *Cache) HExpire(k string, d time.Duration, fields ...string) ([]int, error) {
+	return c.hashes.HExpireAt(k, time.Now().Add(d).UnixNano(), fields...)
+}
+
+// HExpireAt 设置Hash中多个field的过期时刻，对应redis的HEXPIREAT、HPEXPIREAT
+func (c *Cache) HExpireAt(k string, tm time.Time, fields ...string) ([]int, error) {
+	return c.hashes.HExpireAt(k, tm.UnixNano(), fields...)
+}
+
+// HTTL 获取Hash中多个field的剩余生存时间，单位为毫秒
+// field不存在时为 types.FieldNotExist，没有过期时间时为 types.FieldNoExpiration
+func (c *Cache) HTTL(k string, fields ...string) ([]int64, error) {
+	return c.hashes.HTTL(k, fields...)
+}
+
+// HPersist 移除Hash中多个field的过期时间
+// 返回每个field的结果：types.FieldNotExist、types.FieldNoExpiration 或 types.FieldPersisted
+func (c *Cache) HPersist(k string, fields ...string) ([]int, error) {
+	return c.hashes.HPersist(k, fields...)
 }
 
 // HKeys 获取Hash中的所有元素field
@@ -155,28 +575,40 @@ func (c *Cache) HKeys(k string) ([]string, error) {
 	return c.hashes.HKeys(k)
 }
 
-// HVals 获取Hash中所有元素的内容
+// HVals 获取Hash中所有元素的内容，返回的切片为副本
 func (c *Cache) HVals(k string) ([]any, error) {
 	return c.hashes.HVals(k)
 }
 
+// HScan 按游标遍历Hash中的field和内容，首次遍历时cursor为0，返回的游标为0表示遍历结束
+// 遍历期间一直存在的field至少会被返回一次，match 为空时不过滤，count 小于等于0时使用默认值
+func (c *Cache) HScan(k string, cursor uint64, match string, count int) (map[string]any, uint64, error) {
+	return c.hashes.HScan(k, cursor, match, count)
+}
+
 // ======== 集合 =======
 
 // SAdd 向集合中添加一个元素
-func (c *Cache) SAdd(k string, m any) {
-	if exist := c.sets.SAdd(k, m); !exist {
+// m 支持string、[]byte、数字和bool，统一按字符串存储
+func (c *Cache) SAdd(k string, m any) error {
+	exist, err := c.sets.SAdd(k, m)
+	if err != nil {
+		return err
+	}
+	if !exist {
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		c.keyMap[k] = types.TypeSet
 	}
+	return nil
 }
 
 // SRem 从集合中，删除一个元素
-func (c *Cache) SRem(k, m string) {
-	c.sets.SRem(k, m)
+func (c *Cache) SRem(k string, m any) error {
+	return c.sets.SRem(k, m)
 }
 
-// SMembers 获取集合中所有的元素列表
+// SMembers 获取集合中所有的元素列表，返回的切片为副本
 func (c *Cache) SMembers(k string) ([]any, error) {
 	return c.sets.SMembers(k)
 }
@@ -191,39 +623,106 @@ func (c *Cache) SCard(k string) int {
 	return c.sets.SCard(k)
 }
 
-// SUnion 获取集合s1和s2的并集
-func (c *Cache) SUnion(k1, k2 string) *types.Set {
-	return c.sets.SUnion(k1, k2)
+// SMIsMember 批量判断元素是否为集合中的元素
+func (c *Cache) SMIsMember(k string, members ...any) ([]bool, error) {
+	return c.sets.SMIsMember(k, members...)
+}
+
+// SPop 从集合中随机弹出count个元素
+func (c *Cache) SPop(k string, count int) ([]any, error) {
+	return c.sets.SPop(k, count)
+}
+
+// SRandMember 从集合中随机获取count个元素，count为负数时元素可能重复
+func (c *Cache) SRandMember(k string, count int) ([]any, error) {
+	return c.sets.SRandMember(k, count)
+}
+
+// SMove 将元素m从集合src原子地移动到集合dst
+func (c *Cache) SMove(src, dst string, m any) (bool, error) {
+	moved, exist, err := c.sets.SMove(src, dst, m)
+	if moved && !exist {
+		c.storeKey(dst, types.TypeSet, 1)
+	}
+	return moved, err
+}
+
+// SUnion 获取多个集合的并集
+func (c *Cache) SUnion(keys ...string) []any {
+	return c.sets.SUnion(keys...)
+}
+
+// SUnionStore 计算多个集合的并集，并存储到dst中
+func (c *Cache) SUnionStore(dst string, keys ...string) int {
+	n := c.sets.SUnionStore(dst, keys...)
+	c.storeKey(dst, types.TypeSet, n)
+	return n
+}
+
+// SInter 获取多个集合的交集
+func (c *Cache) SInter(keys ...string) []any {
+	return c.sets.SInter(keys...)
+}
+
+// SInterStore 计算多个集合的交集，并存储到dst中
+func (c *Cache) SInterStore(dst string, keys ...string) int {
+	n := c.sets.SInterStore(dst, keys...)
+	c.storeKey(dst, types.TypeSet, n)
+	return n
+}
+
+// SInterCard 统计多个集合交集的元素数量，limit大于0时最多统计到limit
+func (c *Cache) SInterCard(limit int, keys ...string) int {
+	return c.sets.SInterCard(limit, keys...)
+}
+
+// SDiff 获取第一个集合与其他集合的差集
+func (c *Cache) SDiff(keys ...string) []any {
+	return c.sets.SDiff(keys...)
 }
 
-// SInter 获取集合s1和s2的交集
-func (c *Cache) SInter(k1, k2 string) *types.Set {
-	return c.sets.SInter(k1, k2)
+// SDiffStore 计算第一个集合与其他集合的差集，并存储到dst中
+func (c *Cache) SDiffStore(dst string, keys ...string) int {
+	n := c.sets.SDiffStore(dst, keys...)
+	c.storeKey(dst, types.TypeSet, n)
+	return n
+}
+
+// SScan 按游标遍历集合中的元素，首次遍历时cursor为0，返回的游标为0表示遍历结束
+// 遍历期间一直存在的元素至少会被返回一次，match 为空时不过滤，count 小于等于0时使用默认值
+func (c *Cache) SScan(k string, cursor uint64, match string, count int) ([]any, uint64, error) {
+	return c.sets.SScan(k, cursor, match, count)
 }
 
 // ======== 有序集合 =======
 
 // ZAdd 向有序集合中添加一个元素
-func (c *Cache) ZAdd(key, element string, score float64) {
-	if exist := c.zSets.ZAdd(key, element, score); !exist {
+// element 与集合元素相同，支持string、[]byte、数字和bool，统一按字符串存储
+func (c *Cache) ZAdd(key string, element any, score float64) error {
+	exist, err := c.zSets.ZAdd(key, element, score)
+	if err != nil {
+		return err
+	}
+	if !exist {
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		c.keyMap[key] = types.TypeZSet
 	}
+	return nil
 }
 
 // ZRem 从有序集合中，删除一个元素
-func (c *Cache) ZRem(key, element string) {
-	c.zSets.ZRem(key, element)
+func (c *Cache) ZRem(key string, element any) error {
+	return c.zSets.ZRem(key, element)
 }
 
 // ZIncrBy 向有序集合中一个元素,增加score
-func (c *Cache) ZIncrBy(key, element string, score float64) float64 {
+func (c *Cache) ZIncrBy(key string, element any, score float64) (float64, error) {
 	return c.zSets.ZIncrBy(key, element, score)
 }
 
 // ZDecrBy 向有序集合中一个元素,减少score
-func (c *Cache) ZDecrBy(key, element string, score float64) float64 {
+func (c *Cache) ZDecrBy(key string, element any, score float64) (float64, error) {
 	return c.zSets.ZDecrBy(key, element, score)
 }
 
@@ -233,26 +732,26 @@ func (c *Cache) ZCard(key string) int {
 }
 
 // ZRank 获取有序集合的元素排名
-func (c *Cache) ZRank(key, element string) int {
+func (c *Cache) ZRank(key string, element any) int {
 	return c.zSets.ZRank(key, element)
 }
 
 // ZRankWithScore 获取有序集合的元素排名和score
-func (c *Cache) ZRankWithScore(key, element string) (int, float64) {
+func (c *Cache) ZRankWithScore(key string, element any) (int, float64) {
 	return c.zSets.ZRankWithScore(key, element)
 }
 
 // ZRevRank 获取有序集合的元素倒数排名
-func (c *Cache) ZRevRank(key, element string) int {
+func (c *Cache) ZRevRank(key string, element any) int {
 	return c.zSets.ZRevRank(key, element)
 }
 
 // ZRevRankWithScore 获取有序集合的元素倒数排名和score
-func (c *Cache) ZRevRankWithScore(key, element string) (int, float64) {
+func (c *Cache) ZRevRankWithScore(key string, element any) (int, float64) {
 	return c.zSets.ZRevRankWithScore(key, element)
 }
 
-// ZRange 获取有序集合区间元素
+// ZRange 获取有序集合区间元素，返回的切片为副本，区间规则与LRange相同
 func (c *Cache) ZRange(key string, start, stop int) ([]string, error) {
 	return c.zSets.ZRange(key, start, stop)
 }
@@ -262,7 +761,7 @@ func (c *Cache) ZRangeWithScore(key string, start, stop int) (map[string]float64
 	return c.zSets.ZRangeWithScore(key, start, stop)
 }
 
-// ZRevRange 获取有序集合倒排区间元素
+// ZRevRange 获取有序集合倒排区间元素，返回的切片为副本，区间规则与LRange相同
 func (c *Cache) ZRevRange(key string, start, stop int) ([]string, error) {
 	return c.zSets.ZRevRange(key, start, stop)
 }
@@ -272,14 +771,819 @@ func (c *Cache) ZRevRangeWithScore(key string, start, stop int) (map[string]floa
 	return c.zSets.ZRevRangeWithScore(key, start, stop)
 }
 
+// ZScan 按游标遍历有序集合中的元素和score，首次遍历时cursor为0，返回的游标为0表示遍历结束
+// 遍历期间一直存在的元素至少会被返回一次，match 为空时不过滤，count 小于等于0时使用默认值
+func (c *Cache) ZScan(key string, cursor uint64, match string, count int) (map[string]float64, uint64, error) {
+	return c.zSets.ZScan(key, cursor, match, count)
+}
+
+// ZUnion 获取多个有序集合的并集，普通集合中的元素score视为1
+func (c *Cache) ZUnion(store *types.ZStore) (map[string]float64, error) {
+	return c.zSets.ZUnion(store, c.sets)
+}
+
+// ZUnionStore 计算多个有序集合的并集，并存储到dst中
+func (c *Cache) ZUnionStore(dst string, store *types.ZStore) (int, error) {
+	n, err := c.zSets.ZUnionStore(dst, store, c.sets)
+	if err != nil {
+		return 0, err
+	}
+	c.storeKey(dst, types.TypeZSet, n)
+	return n, nil
+}
+
+// ZInter 获取多个有序集合的交集，普通集合中的元素score视为1
+func (c *Cache) ZInter(store *types.ZStore) (map[string]float64, error) {
+	return c.zSets.ZInter(store, c.sets)
+}
+
+// ZInterStore 计算多个有序集合的交集，并存储到dst中
+func (c *Cache) ZInterStore(dst string, store *types.ZStore) (int, error) {
+	n, err := c.zSets.ZInterStore(dst, store, c.sets)
+	if err != nil {
+		return 0, err
+	}
+	c.storeKey(dst, types.TypeZSet, n)
+	return n, nil
+}
+
+// ZDiff 获取第一个有序集合与其他集合的差集
+func (c *Cache) ZDiff(keys ...string) (map[string]float64, error) {
+	return c.zSets.ZDiff(keys, c.sets)
+}
+
+// ZDiffStore 计算第一个有序集合与其他集合的差集，并存储到dst中
+func (c *Cache) ZDiffStore(dst string, keys ...string) (int, error) {
+	n, err := c.zSets.ZDiffStore(dst, keys, c.sets)
+	if err != nil {
+		return 0, err
+	}
+	c.storeKey(dst, types.TypeZSet, n)
+	return n, nil
+}
+
+// ======== 地理位置 =======
+
+// GeoAdd 添加成员的地理位置，使用有序集合存储，score为位置的52位geohash
+// return int 为新添加的成员数量，经纬度超出范围时返回 types.ErrCoordinate
+func (c *Cache) GeoAdd(k string, locations ...types.GeoLocation) (int, error) {
+	added, exist, err := c.zSets.GeoAdd(k, locations...)
+	if err == nil && !exist && added > 0 {
+		c.storeKey(k, types.TypeZSet, added)
+	}
+	return added, err
+}
+
+// GeoPos 获取成员的地理位置，成员不存在时对应的位置为nil
+func (c *Cache) GeoPos(k string, members ...string) []*types.GeoLocation {
+	return c.zSets.GeoPos(k, members...)
+}
+
+// GeoDist 获取两个成员之间的距离，unit 为m、km、mi或ft，为空时为m
+func (c *Cache) GeoDist(k, member1, member2 string, unit types.GeoUnit) (float64, error) {
+	return c.zSets.GeoDist(k, member1, member2, unit)
+}
+
+// GeoHash 获取成员位置的11位geohash字符串，成员不存在时为空字符串
+func (c *Cache) GeoHash(k string, members ...string) []string {
+	return c.zSets.GeoHash(k, members...)
+}
+
+// GeoSearch 以成员或经纬度为中心，搜索圆形或矩形区域内的成员
+func (c *Cache) GeoSearch(k string, args *types.GeoSearchArgs) ([]types.GeoSearchResult, error) {
+	return c.zSets.GeoSearch(k, args)
+}
+
+// GeoSearchStore 搜索区域内的成员，并存储到有序集合dst中
+// storeDist 为true时score为到中心的距离，否则为位置的geohash
+func (c *Cache) GeoSearchStore(dst, src string, args *types.GeoSearchArgs, storeDist bool) (int, error) {
+	n, err := c.zSets.GeoSearchStore(dst, src, args, storeDist)
+	if err != nil {
+		return 0, err
+	}
+	c.storeKey(dst, types.TypeZSet, n)
+	return n, nil
+}
+
+// ======== HyperLogLog =======
+
+// PFAdd 向HyperLogLog中添加元素，用于估算不重复元素的数量，标准误差约为0.81%
+// 元素支持string、[]byte、数字和bool
+// return bool 表示估算的基数是否可能发生变化
+func (c *Cache) PFAdd(k string, elements ...any) (bool, error) {
+	changed, exist, err := c.hyperLogLogs.PFAdd(k, elements...)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeHyperLogLog, 1)
+	}
+	return changed, err
+}
+
+// PFCount 估算一个或多个HyperLogLog并集的基数
+func (c *Cache) PFCount(keys ...string) int64 {
+	return c.hyperLogLogs.PFCount(keys...)
+}
+
+// PFMerge 将多个HyperLogLog合并到dst中，dst原有的元素会保留
+func (c *Cache) PFMerge(dst string, keys ...string) {
+	if exist := c.hyperLogLogs.PFMerge(dst, keys...); !exist {
+		c.storeKey(dst, types.TypeHyperLogLog, 1)
+	}
+}
+
+// ======== 流 =======
+
+// XAdd 向流k中添加一条消息，并按照args删除旧的消息
+// return string 为消息的ID
+func (c *Cache) XAdd(k string, args *types.XAddArgs) (string, error) {
+	id, exist, err := c.streams.XAdd(k, args)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeStream, 1)
+	}
+	return id, err
+}
+
+// XLen 获取流中消息的数量
+func (c *Cache) XLen(k string) int {
+	return c.streams.XLen(k)
+}
+
+// XRange 按ID从小到大获取[start, end]范围内最多count条消息，count为0时不限制
+// start、end 支持"-"、"+"，以及"("前缀表示不包含该ID
+func (c *Cache) XRange(k, start, end string, count int) ([]types.StreamEntry, error) {
+	return c.streams.XRange(k, start, end, count)
+}
+
+// XRevRange 按ID从大到小获取[start, end]范围内最多count条消息，count为0时不限制
+func (c *Cache) XRevRange(k, end, start string, count int) ([]types.StreamEntry, error) {
+	return c.streams.XRevRange(k, end, start, count)
+}
+
+// XRead 从多个流中读取ID大于指定ID的消息
+// args.Block 为true时没有新消息则阻塞等待，直到ctx结束；超时返回 types.ErrTimeout
+func (c *Cache) XRead(ctx context.Context, args *types.XReadArgs) ([]types.XStream, error) {
+	return c.streams.XRead(ctx, args)
+}
+
+// XGroupCreate 为流k创建消费者组，id 为"$"时只消费新的消息
+// mkStream 为true时k不存在则创建空的流
+func (c *Cache) XGroupCreate(k, group, id string, mkStream bool) error {
+	exist, err := c.streams.XGroupCreate(k, group, id, mkStream)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeStream, 1)
+	}
+	return err
+}
+
+// XReadGroup 以消费者组中消费者的身份读取消息，ID为">"时读取新的消息
+// args.Block 为true时没有新消息则阻塞等待，直到ctx结束；超时返回 types.ErrTimeout
+func (c *Cache) XReadGroup(ctx context.Context, args *types.XReadGroupArgs) ([]types.XStream, error) {
+	return c.streams.XReadGroup(ctx, args)
+}
+
+// XAck 确认消费者组中的消息
+// return int 为确认成功的消息数量
+func (c *Cache) XAck(k, group string, ids ...string) (int, error) {
+	return c.streams.XAck(k, group, ids...)
+}
+
+// XPending 获取消费者组中待确认消息的概况
+func (c *Cache) XPending(k, group string) (*types.XPendingSummary, error) {
+	return c.streams.XPending(k, group)
+}
+
+// XPendingExt 获取消费者组中待确认消息的详情
+func (c *Cache) XPendingExt(k string, args *types.XPendingArgs) ([]types.XPendingEntry, error) {
+	return c.streams.XPendingExt(k, args)
+}
+
+// XClaim 将空闲时间足够长的待确认消息转移给其他消费者
+func (c *Cache) XClaim(k string, args *types.XClaimArgs) ([]types.StreamEntry, error) {
+	return c.streams.XClaim(k, args)
+}
+
+// XAutoClaim 扫描待确认列表，将空闲时间足够长的消息转移给其他消费者
+// return string 为下一次扫描的起始ID，为"0-0"时表示扫描结束
+func (c *Cache) XAutoClaim(k string, args *types.XAutoClaimArgs) ([]types.StreamEntry, string, error) {
+	return c.streams.XAutoClaim(k, args)
+}
+
+// ======== 布隆过滤器 =======
+
+// BFReserve 创建一个空的布隆过滤器，errorRate 为误判率，capacity 为预计添加的元素数量
+// 元素数量超过容量时自动扩容，k已存在时返回 types.ErrFilterExists
+func (c *Cache) BFReserve(k string, errorRate float64, capacity int64) error {
+	return c.BFReserveArgs(k, types.BFReserveArgs{ErrorRate: errorRate, Capacity: capacity})
+}
+
+// BFReserveArgs 按照args创建一个空的布隆过滤器，可以指定扩容倍数或不扩容
+func (c *Cache) BFReserveArgs(k string, args types.BFReserveArgs) error {
+	if err := c.blooms.BFReserve(k, args); err != nil {
+		return err
+	}
+	c.storeKey(k, types.TypeBloom, 1)
+	return nil
+}
+
+// BFAdd 向布隆过滤器中添加一个元素，k不存在时按误判率0.01、容量100创建
+// return bool 表示元素之前是否一定不存在
+func (c *Cache) BFAdd(k string, item any) (bool, error) {
+	added, exist, err := c.blooms.BFAdd(k, item)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeBloom, 1)
+	}
+	return added, err
+}
+
+// BFMAdd 向布隆过滤器中添加多个元素，k不存在时按默认参数创建
+func (c *Cache) BFMAdd(k string, items ...any) ([]bool, error) {
+	results, exist, err := c.blooms.BFMAdd(k, items...)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeBloom, 1)
+	}
+	return results, err
+}
+
+// BFExists 判断元素是否可能存在于布隆过滤器中，返回false时一定不存在
+func (c *Cache) BFExists(k string, item any) (bool, error) {
+	return c.blooms.BFExists(k, item)
+}
+
+// BFMExists 判断多个元素是否可能存在于布隆过滤器中
+func (c *Cache) BFMExists(k string, items ...any) ([]bool, error) {
+	return c.blooms.BFMExists(k, items...)
+}
+
+// BFInfo 获取布隆过滤器的容量、占用空间、层数和元素数量
+func (c *Cache) BFInfo(k string) (*types.BFInfo, error) {
+	return c.blooms.BFInfo(k)
+}
+
+// ======== 布谷鸟过滤器 =======
+
+// CFReserve 创建一个空的布谷鸟过滤器，capacity 为预计添加的元素数量，空间不足时自动扩容
+func (c *Cache) CFReserve(k string, capacity int64) error {
+	return c.CFReserveArgs(k, types.CFReserveArgs{Capacity: capacity})
+}
+
+// CFReserveArgs 按照args创建一个空的布谷鸟过滤器
+func (c *Cache) CFReserveArgs(k string, args types.CFReserveArgs) error {
+	if err := c.cuckoos.CFReserve(k, args); err != nil {
+		return err
+	}
+	c.storeKey(k, types.TypeCuckoo, 1)
+	return nil
+}
+
+// CFAdd 向布谷鸟过滤器中添加一个元素，元素可以重复添加，k不存在时按容量1024创建
+func (c *Cache) CFAdd(k string, item any) error {
+	exist, err := c.cuckoos.CFAdd(k, item)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeCuckoo, 1)
+	}
+	return err
+}
+
+// CFAddNX 元素可能已存在时不添加
+// return bool 表示是否添加了元素
+func (c *Cache) CFAddNX(k string, item any) (bool, error) {
+	added, exist, err := c.cuckoos.CFAddNX(k, item)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeCuckoo, 1)
+	}
+	return added, err
+}
+
+// CFExists 判断元素是否可能存在于布谷鸟过滤器中，返回false时一定不存在
+func (c *Cache) CFExists(k string, item any) (bool, error) {
+	return c.cuckoos.CFExists(k, item)
+}
+
+// CFMExists 判断多个元素是否可能存在于布谷鸟过滤器中
+func (c *Cache) CFMExists(k string, items ...any) ([]bool, error) {
+	return c.cuckoos.CFMExists(k, items...)
+}
+
+// CFCount 获取元素在布谷鸟过滤器中可能的数量
+func (c *Cache) CFCount(k string, item any) (int, error) {
+	return c.cuckoos.CFCount(k, item)
+}
+
+// CFDel 从布谷鸟过滤器中删除一个已添加过的元素
+// return bool 表示是否找到并删除了元素
+func (c *Cache) CFDel(k string, item any) (bool, error) {
+	return c.cuckoos.CFDel(k, item)
+}
+
+// CFInfo 获取布谷鸟过滤器的占用空间、桶数量、层数和元素数量
+func (c *Cache) CFInfo(k string) (*types.CFInfo, error) {
+	return c.cuckoos.CFInfo(k)
+}
+
+// ======== Count-Min Sketch =======
+
+// CMSInitByDim 按照宽度和深度创建Count-Min Sketch，k已存在时返回 types.ErrSketchExists
+func (c *Cache) CMSInitByDim(k string, width, depth int) error {
+	if err := c.countMinSketches.CMSInitByDim(k, width, depth); err != nil {
+		return err
+	}
+	c.storeKey(k, types.TypeCountMinSketch, 1)
+	return nil
+}
+
+// CMSInitByProb 按照误差和概率创建Count-Min Sketch
+// errorRate 为估算值超出的数量占总数的比例，probability 为超出该误差的概率
+func (c *Cache) CMSInitByProb(k string, errorRate, probability float64) error {
+	if err := c.countMinSketches.CMSInitByProb(k, errorRate, probability); err != nil {
+		return err
+	}
+	c.storeKey(k, types.TypeCountMinSketch, 1)
+	return nil
+}
+
+// CMSIncrBy 将元素的计数增加increment，返回增加后的估算值
+func (c *Cache) CMSIncrBy(k string, item any, increment int64) (int64, error) {
+	return c.countMinSketches.CMSIncrBy(k, item, increment)
+}
+
+// CMSQuery 获取元素计数的估算值，估算值不小于实际值
+func (c *Cache) CMSQuery(k string, items ...any) ([]int64, error) {
+	return c.countMinSketches.CMSQuery(k, items...)
+}
+
+// CMSMerge 将多个Count-Min Sketch按权重合并到dst中，weights 为空时权重都为1
+func (c *Cache) CMSMerge(dst string, keys []string, weights []int64) error {
+	return c.countMinSketches.CMSMerge(dst, keys, weights)
+}
+
+// CMSInfo 获取Count-Min Sketch的宽度、深度和总数
+func (c *Cache) CMSInfo(k string) (*types.CMSInfo, error) {
+	return c.countMinSketches.CMSInfo(k)
+}
+
+// ======== Top-K =======
+
+// TopKReserve 创建保留topK个元素的Top-K，使用默认的宽度、深度和衰减
+func (c *Cache) TopKReserve(k string, topK int) error {
+	return c.TopKReserveArgs(k, types.TopKReserveArgs{TopK: topK})
+}
+
+// TopKReserveArgs 按照args创建Top-K，k已存在时返回 types.ErrSketchExists
+func (c *Cache) TopKReserveArgs(k string, args types.TopKReserveArgs) error {
+	if err := c.topKs.TopKReserve(k, args); err != nil {
+		return err
+	}
+	c.storeKey(k, types.TypeTopK, 1)
+	return nil
+}
+
+// TopKAdd 向Top-K中添加元素，每个元素计数加1
+// return []string 为每个元素添加后被挤出Top-K的元素，没有被挤出时为空字符串
+func (c *Cache) TopKAdd(k string, items ...any) ([]string, error) {
+	return c.topKs.TopKAdd(k, items...)
+}
+
+// TopKIncrBy 将元素的计数增加increment，返回被挤出Top-K的元素
+func (c *Cache) TopKIncrBy(k string, item any, increment int64) (string, error) {
+	return c.topKs.TopKIncrBy(k, item, increment)
+}
+
+// TopKQuery 判断元素是否在Top-K中
+func (c *Cache) TopKQuery(k string, items ...any) ([]bool, error) {
+	return c.topKs.TopKQuery(k, items...)
+}
+
+// TopKCount 获取元素计数的估算值
+func (c *Cache) TopKCount(k string, items ...any) ([]int64, error) {
+	return c.topKs.TopKCount(k, items...)
+}
+
+// TopKList 获取Top-K中的元素和估算的计数，按计数从高到低排列
+func (c *Cache) TopKList(k string) ([]types.TopKItem, error) {
+	return c.topKs.TopKList(k)
+}
+
+// ======== JSON =======
+
+// JSONSet 设置path匹配的值，value 为[]byte或json.RawMessage时按JSON文本解析，其他类型按json.Marshal转换
+// k不存在时只能在根路径"$"创建；mode 为NX时只在path不存在时设置，为XX时只在path存在时设置
+// return bool 表示是否设置成功
+func (c *Cache) JSONSet(k, path string, value any, mode types.SetMode) (bool, error) {
+	set, exist, err := c.jsons.JSONSet(k, path, value, mode)
+	if err == nil && set && !exist {
+		c.storeKey(k, types.TypeJSON, 1)
+	}
+	return set, err
+}
+
+// JSONGet 获取path匹配的值，返回JSON文本
+// 没有path时返回整个文档；path 以"$"开头时返回所有匹配值组成的数组，否则返回第一个匹配的值
+func (c *Cache) JSONGet(k string, paths ...string) ([]byte, error) {
+	return c.jsons.JSONGet(k, paths...)
+}
+
+// JSONDel 删除path匹配的值，path 为根路径时删除k，返回删除的数量
+func (c *Cache) JSONDel(k, path string) (int, error) {
+	n, deleted, err := c.jsons.JSONDel(k, path)
+	if deleted {
+		c.storeKey(k, types.TypeJSON, 0)
+	}
+	return n, err
+}
+
+// JSONArrAppend 向path匹配的数组末尾添加元素
+// return []*int 为添加后数组的长度，匹配的值不是数组时为nil
+func (c *Cache) JSONArrAppend(k, path string, values ...any) ([]*int, error) {
+	return c.jsons.JSONArrAppend(k, path, values...)
+}
+
+// JSONArrPop 从path匹配的数组中弹出下标为index的元素，index 为-1时弹出最后一个
+// return [][]byte 为弹出元素的JSON文本，匹配的值不是数组或数组为空时为nil
+func (c *Cache) JSONArrPop(k, path string, index int) ([][]byte, error) {
+	return c.jsons.JSONArrPop(k, path, index)
+}
+
+// JSONNumIncrBy 将path匹配的数字增加n
+// return []json.Number 为增加后的值，匹配的值不是数字时为空字符串
+func (c *Cache) JSONNumIncrBy(k, path string, n float64) ([]json.Number, error) {
+	return c.jsons.JSONNumIncrBy(k, path, n)
+}
+
+// JSONType 获取path匹配的值的类型
+func (c *Cache) JSONType(k, path string) ([]string, error) {
+	return c.jsons.JSONType(k, path)
+}
+
+// JSONObjKeys 获取path匹配的对象的所有字段，匹配的值不是对象时为nil
+func (c *Cache) JSONObjKeys(k, path string) ([][]string, error) {
+	return c.jsons.JSONObjKeys(k, path)
+}
+
+// ======== 时间序列 =======
+
+// TSCreate 创建一个空的时间序列，不清理样本，时间戳重复时返回 types.ErrTSDuplicate
+func (c *Cache) TSCreate(k string) error {
+	return c.TSCreateArgs(k, types.TSCreateArgs{})
+}
+
+// TSCreateArgs 按照args创建一个空的时间序列，可以指定保留时长、重复时间戳的处理方式和标签
+func (c *Cache) TSCreateArgs(k string, args types.TSCreateArgs) error {
+	if err := c.timeSeries.TSCreate(k, args); err != nil {
+		return err
+	}
+	c.storeKey(k, types.TypeTimeSeries, 1)
+	return nil
+}
+
+// TSAdd 添加一个样本，timestamp 为毫秒时间戳，为0时使用当前时间，k不存在时按默认参数创建
+// return int64 为样本的时间戳
+func (c *Cache) TSAdd(k string, timestamp int64, value float64) (int64, error) {
+	return c.TSAddArgs(k, timestamp, value, types.TSAddArgs{})
+}
+
+// TSAddArgs 添加一个样本，k不存在时按args创建，args.OnDuplicate 可以覆盖本次添加重复时间戳的处理方式
+func (c *Cache) TSAddArgs(k string, timestamp int64, value float64, args types.TSAddArgs) (int64, error) {
+	timestamp, exist, err := c.timeSeries.TSAdd(k, timestamp, value, &args)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeTimeSeries, 1)
+	}
+	return timestamp, err
+}
+
+// TSMAdd 向多个已存在的时间序列添加样本
+// return []int64 为样本的时间戳，[]error 为每个样本添加的错误
+func (c *Cache) TSMAdd(samples ...types.TSKeySample) ([]int64, []error) {
+	return c.timeSeries.TSMAdd(samples...)
+}
+
+// TSGet 获取最新的样本，时间序列为空时返回nil
+func (c *Cache) TSGet(k string) (*types.TSSample, error) {
+	return c.timeSeries.TSGet(k)
+}
+
+// TSRange 获取时间戳在[from, to]之间的样本，按时间戳从小到大排列
+// args 为nil时返回原始样本，否则可以按时间桶聚合并限制数量
+func (c *Cache) TSRange(k string, from, to int64, args *types.TSRangeArgs) ([]types.TSSample, error) {
+	return c.timeSeries.TSRange(k, from, to, args)
+}
+
+// TSRevRange 获取时间戳在[from, to]之间的样本，按时间戳从大到小排列
+func (c *Cache) TSRevRange(k string, from, to int64, args *types.TSRangeArgs) ([]types.TSSample, error) {
+	return c.timeSeries.TSRevRange(k, from, to, args)
+}
+
+// TSMRange 获取标签满足所有filters的时间序列中时间戳在[from, to]之间的样本，按key排列
+// filter 支持label=value、label!=value、label=(v1,v2)、label!=(v1,v2)、label=、label!=，至少需要一个label=value
+func (c *Cache) TSMRange(from, to int64, filters []string, args *types.TSRangeArgs) ([]types.TSSeries, error) {
+	return c.timeSeries.TSMRange(from, to, filters, args)
+}
+
+// TSMRevRange 与TSMRange相同，每个时间序列的样本按时间戳从大到小排列
+func (c *Cache) TSMRevRange(from, to int64, filters []string, args *types.TSRangeArgs) ([]types.TSSeries, error) {
+	return c.timeSeries.TSMRevRange(from, to, filters, args)
+}
+
+// TSCreateRule 创建压缩规则，src中每个bucket宽度的时间桶结束后，按aggregation聚合写入dst
+func (c *Cache) TSCreateRule(src, dst string, aggregation types.TSAggregation, bucket time.Duration) error {
+	return c.timeSeries.TSCreateRule(src, dst, aggregation, bucket)
+}
+
+// TSDeleteRule 删除src到dst的压缩规则
+func (c *Cache) TSDeleteRule(src, dst string) error {
+	return c.timeSeries.TSDeleteRule(src, dst)
+}
+
+// ======== 限流 =======
+
+// RateLimit 按滑动窗口计数限流，判断window内是否还允许1个请求，允许时计入窗口
+// 状态保存在k中，窗口结束后自动过期
+func (c *Cache) RateLimit(k string, limit int64, window time.Duration) (*types.RateLimitResult, error) {
+	return c.RateLimitArgs(k, types.RateLimitArgs{Limit: limit, Window: window})
+}
+
+// RateLimitArgs 按args限流，可以指定请求的数量和使用滑动窗口日志
+func (c *Cache) RateLimitArgs(k string, args types.RateLimitArgs) (*types.RateLimitResult, error) {
+	res, exist, err := c.rateLimiters.SlidingWindow(k, args)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeRateLimiter, 1)
+	}
+	return res, err
+}
+
+// TokenBucket 获取k对应的令牌桶，rate 为每秒生成的令牌数量，burst 为桶的容量
+func (c *Cache) TokenBucket(k string, rate float64, burst int64) *TokenBucket {
+	return c.TokenBucketArgs(k, types.TokenBucketArgs{Rate: rate, Burst: burst})
+}
+
+// TokenBucketArgs 按args获取k对应的令牌桶，可以指定使用GCRA算法
+func (c *Cache) TokenBucketArgs(k string, args types.TokenBucketArgs) *TokenBucket {
+	return &TokenBucket{c: c, key: k, args: args}
+}
+
+// TokenBucket 令牌桶，状态保存在缓存的key中，多个实例使用同一个key时共享令牌
+type TokenBucket struct {
+	c    *Cache
+	key  string
+	args types.TokenBucketArgs
+}
+
+// Allow 判断是否允许n个请求，允许时消耗n个令牌，n 不能超过桶的容量
+// 状态在令牌恢复满桶后自动过期
+func (b *TokenBucket) Allow(n int64) (*types.RateLimitResult, error) {
+	res, exist, err := b.c.rateLimiters.TokenBucket(b.key, b.args, n)
+	if err == nil && !exist {
+		b.c.storeKey(b.key, types.TypeRateLimiter, 1)
+	}
+	return res, err
+}
+
+// ======== 锁 =======
+
+// Lock 获取k对应的锁，锁在ttl后自动释放，k的值为随机的持有者token
+// k已存在时阻塞等待k被删除或过期后重试，ctx 取消时返回 ctx.Err()，k为其他类型时返回 types.ErrLocked
+func (c *Cache) Lock(ctx context.Context, k string, ttl time.Duration) (*Lease, error) {
+	return c.LockTimeout(ctx, k, ttl, 0)
+}
+
+// LockTimeout 与Lock相同，等待超过timeout时返回 types.ErrTimeout，timeout 为0时一直等待
+func (c *Cache) LockTimeout(ctx context.Context, k string, ttl, timeout time.Duration) (*Lease, error) {
+	if c.existOther(k, types.TypeString) {
+		return nil, types.ErrLocked
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.strings.SetNXWait(ctx, k, token, ttl, timeout); err != nil {
+		return nil, err
+	}
+	c.storeKey(k, types.TypeString, 1)
+	return &Lease{c: c, key: k, token: token}, nil
+}
+
+// TryLock 获取k对应的锁，不阻塞，k已存在时返回 types.ErrLocked
+func (c *Cache) TryLock(k string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, types.ErrExpireArgs
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	_, set, err := c.SetArgs(k, token, types.SetArgs{Mode: types.SetModeNX, TTL: ttl})
+	if err != nil {
+		return nil, err
+	}
+	if !set {
+		return nil, types.ErrLocked
+	}
+	return &Lease{c: c, key: k, token: token}, nil
+}
+
+// Lease 锁的租约，token 为持有者的随机token，只有k的值仍为token时才能释放或续期
+type Lease struct {
+	c     *Cache
+	key   string
+	token string
+}
+
+// Key 获取锁的key
+func (l *Lease) Key() string {
+	return l.key
+}
+
+// Token 获取持有者的token
+func (l *Lease) Token() string {
+	return l.token
+}
+
+// Unlock 释放锁，锁已过期或被其他持有者获取时返回 types.ErrLockNotHeld
+// 释放后等待的调用方可能立即获取锁，因此不删除keyMap中的记录
+func (l *Lease) Unlock() error {
+	if !l.c.strings.CompareAndDelete(l.key, l.token) {
+		return types.ErrLockNotHeld
+	}
+	return nil
+}
+
+// Extend 将锁的过期时间设置为ttl之后，锁已过期或被其他持有者获取时返回 types.ErrLockNotHeld
+func (l *Lease) Extend(ttl time.Duration) error {
+	if ttl <= 0 {
+		return types.ErrExpireArgs
+	}
+	if !l.c.strings.CompareAndExpire(l.key, l.token, ttl) {
+		return types.ErrLockNotHeld
+	}
+	return nil
+}
+
+// newLockToken 生成随机的持有者token
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ======== 加载 =======
+
+// GetOrLoad 获取k的值，k不存在时调用loader加载，并按ttl缓存加载的结果，ttl 为0时不过期
+// 多个调用方同时加载同一个k时，只有一个调用方执行loader，其他调用方等待并共享结果
+// loader 为nil时使用 WithLoader 设置的全局加载函数，都没有设置时返回 types.ErrNoLoader
+// 设置了 WithNegativeTTL 时，加载失败的错误会被缓存，期间直接返回该错误
+func (c *Cache) GetOrLoad(ctx context.Context, k string, ttl time.Duration, loader func(ctx context.Context) (any, error)) (any, error) {
+	if v, err := c.strings.Get(k); err == nil {
+		return v, nil
+	}
+	if loader == nil {
+		if c.loader == nil {
+			return nil, types.ErrNoLoader
+		}
+		loader = func(ctx context.Context) (any, error) {
+			return c.loader(ctx, k)
+		}
+	}
+	c.loadMu.Lock()
+	if e := c.negatives[k]; e != nil {
+		if time.Now().UnixNano() <= e.expiration {
+			c.loadMu.Unlock()
+			return nil, e.err
+		}
+		delete(c.negatives, k)
+	}
+	call, loading := c.loads[k]
+	if !loading {
+		call = &loadCall{done: make(chan struct{})}
+		c.loads[k] = call
+		// loader 在单独的goroutine中执行，不受发起加载的调用方取消的影响
+		go c.load(detachedContext{ctx}, k, ttl, call, loader)
+	}
+	c.loadMu.Unlock()
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load 执行loader并保存结果，加载前再检查一次k，避免其他调用方刚加载完成时重复加载
+// loader 的错误不是取消或超时时，按negativeTTL缓存；loader panic时所有调用方返回 types.ErrLoaderPanic
+func (c *Cache) load(ctx context.Context, k string, ttl time.Duration, call *loadCall, loader func(ctx context.Context) (any, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.val, call.err = nil, fmt.Errorf("%w: %v", types.ErrLoaderPanic, r)
+		}
+		c.loadMu.Lock()
+		delete(c.loads, k)
+		if call.err != nil && c.negativeTTL > 0 &&
+			!errors.Is(call.err, context.Canceled) && !errors.Is(call.err, context.DeadlineExceeded) {
+			c.negatives[k] = &negativeEntry{err: call.err, expiration: time.Now().Add(c.negativeTTL).UnixNano()}
+		}
+		c.loadMu.Unlock()
+		close(call.done)
+	}()
+	if v, err := c.strings.Get(k); err == nil {
+		call.val = v
+		return
+	}
+	call.val, call.err = loader(ctx)
+	if call.err != nil {
+		return
+	}
+	if ttl > 0 {
+		c.SetEx(k, call.val, ttl)
+	} else {
+		c.Set(k, call.val)
+	}
+}
+
+// detachedContext 保留调用方ctx中的值，但不会被取消，也没有截止时间
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key any) any {
+	return d.parent.Value(key)
+}
+
+// clearNegatives 清理过期的加载错误
+func (c *Cache) clearNegatives() {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+	now := time.Now().UnixNano()
+	for k, e := range c.negatives {
+		if now > e.expiration {
+			delete(c.negatives, k)
+		}
+	}
+}
+
+// loadCall 一次正在进行的加载，done 关闭后val和err可读
+type loadCall struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// negativeEntry 缓存的加载错误
+type negativeEntry struct {
+	err        error
+	expiration int64
+}
+
 // ======== 全局 =======
 
 // Exists 判断key是否存在
 func (c *Cache) Exists(k string) bool {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	if _, exist := c.keyMap[k]; exist {
-		return true
+	t, exist := c.keyMap[k]
+	c.mu.Unlock()
+	if !exist {
+		return false
+	}
+	// 过期或元素被清空的key，可能仍记录在keyMap中
+	switch t {
+	case types.TypeString:
+		return c.strings.Exist(k)
+	case types.TypeHash:
+		return c.hashes.Exist(k)
+	case types.TypeList:
+		return c.lists.Exist(k)
+	case types.TypeSet:
+		return c.sets.Exist(k)
+	case types.TypeZSet:
+		return c.zSets.Exist(k)
+	case types.TypeHyperLogLog:
+		return c.hyperLogLogs.Exist(k)
+	case types.TypeStream:
+		return c.streams.Exist(k)
+	case types.TypeBloom:
+		return c.blooms.Exist(k)
+	case types.TypeCuckoo:
+		return c.cuckoos.Exist(k)
+	case types.TypeCountMinSketch:
+		return c.countMinSketches.Exist(k)
+	case types.TypeTopK:
+		return c.topKs.Exist(k)
+	case types.TypeJSON:
+		return c.jsons.Exist(k)
+	case types.TypeTimeSeries:
+		return c.timeSeries.Exist(k)
+	case types.TypeRateLimiter:
+		return c.rateLimiters.Exist(k)
 	}
 	return false
 }
@@ -296,19 +1600,9 @@ func (c *Cache) HExists(k, field string) bool {
 func (c *Cache) Del(k string) {
 	c.mu.Lock()
 	t := c.keyMap[k]
+	delete(c.keyMap, k)
 	c.mu.Unlock()
-	switch t {
-	case types.TypeString:
-		c.strings.Del(k)
-	case types.TypeHash:
-		c.hashes.Del(k)
-	case types.TypeList:
-		c.lists.Del(k)
-	case types.TypeSet:
-		c.sets.Del(k)
-	case types.TypeZSet:
-		c.zSets.Del(k)
-	}
+	c.del(k, t)
 }
 
 // Expiration 设置超时时间
@@ -328,6 +1622,24 @@ func (c *Cache) Expiration(k string, d time.Duration) error {
 		err = c.sets.Expiration(k, d)
 	case types.TypeZSet:
 		err = c.zSets.Expiration(k, d)
+	case types.TypeHyperLogLog:
+		err = c.hyperLogLogs.Expiration(k, d)
+	case types.TypeStream:
+		err = c.streams.Expiration(k, d)
+	case types.TypeBloom:
+		err = c.blooms.Expiration(k, d)
+	case types.TypeCuckoo:
+		err = c.cuckoos.Expiration(k, d)
+	case types.TypeCountMinSketch:
+		err = c.countMinSketches.Expiration(k, d)
+	case types.TypeTopK:
+		err = c.topKs.Expiration(k, d)
+	case types.TypeJSON:
+		err = c.jsons.Expiration(k, d)
+	case types.TypeTimeSeries:
+		err = c.timeSeries.Expiration(k, d)
+	case types.TypeRateLimiter:
+		err = c.rateLimiters.Expiration(k, d)
 	}
 	return err
 }
@@ -341,10 +1653,89 @@ func (c *Cache) Flush() {
 	c.hashes.Flush()
 	c.sets.Flush()
 	c.zSets.Flush()
+	c.hyperLogLogs.Flush()
+	c.streams.Flush()
+	c.blooms.Flush()
+	c.cuckoos.Flush()
+	c.countMinSketches.Flush()
+	c.topKs.Flush()
+	c.jsons.Flush()
+	c.timeSeries.Flush()
+	c.rateLimiters.Flush()
+	c.loadMu.Lock()
+	c.negatives = make(map[string]*negativeEntry)
+	c.loadMu.Unlock()
 }
 
 // ======== 私有 =======
 
+// incrBy 对k计数+v，k为新建时记录到keyMap
+func (c *Cache) incrBy(k string, v int64) (int64, error) {
+	n, exist, err := c.strings.IncrBy(k, v)
+	if err == nil && !exist {
+		c.storeKey(k, types.TypeString, 1)
+	}
+	return n, err
+}
+
+// del 从类型t对应的存储中删除k
+func (c *Cache) del(k string, t types.KeyType) {
+	switch t {
+	case types.TypeString:
+		c.strings.Del(k)
+	case types.TypeHash:
+		c.hashes.Del(k)
+	case types.TypeList:
+		c.lists.Del(k)
+	case types.TypeSet:
+		c.sets.Del(k)
+	case types.TypeZSet:
+		c.zSets.Del(k)
+	case types.TypeHyperLogLog:
+		c.hyperLogLogs.Del(k)
+	case types.TypeStream:
+		c.streams.Del(k)
+	case types.TypeBloom:
+		c.blooms.Del(k)
+	case types.TypeCuckoo:
+		c.cuckoos.Del(k)
+	case types.TypeCountMinSketch:
+		c.countMinSketches.Del(k)
+	case types.TypeTopK:
+		c.topKs.Del(k)
+	case types.TypeJSON:
+		c.jsons.Del(k)
+	case types.TypeTimeSeries:
+		c.timeSeries.Del(k)
+	case types.TypeRateLimiter:
+		c.rateLimiters.Del(k)
+	}
+}
+
+// storeKey 在运算结果写入dst后，维护dst在keyMap中的类型
+// dst原本为其他类型时，删除原有的数据；结果为空时dst已被删除
+func (c *Cache) storeKey(dst string, t types.KeyType, n int) {
+	c.mu.Lock()
+	old, exist := c.keyMap[dst]
+	if n > 0 {
+		c.keyMap[dst] = t
+	} else {
+		delete(c.keyMap, dst)
+	}
+	c.mu.Unlock()
+	if exist && old != t {
+		c.del(dst, old)
+	}
+}
+
+// existOther 判断k是否作为t以外的类型存在
+func (c *Cache) existOther(k string, t types.KeyType) bool {
+	c.mu.Lock()
+	old, exist := c.keyMap[k]
+	c.mu.Unlock()
+	return exist && old != t && c.Exists(k)
+}
+
 type GC interface {
 	Clean()
 	Stop()
@@ -374,13 +1765,23 @@ func (c *randomGC) Clean() {
 		c.cache.lists.RandomClearExpiration,
 		c.cache.sets.RandomClearExpiration,
 		c.cache.zSets.RandomClearExpiration,
+		c.cache.hyperLogLogs.RandomClearExpiration,
+		c.cache.streams.RandomClearExpiration,
+		c.cache.blooms.RandomClearExpiration,
+		c.cache.cuckoos.RandomClearExpiration,
+		c.cache.countMinSketches.RandomClearExpiration,
+		c.cache.topKs.RandomClearExpiration,
+		c.cache.jsons.RandomClearExpiration,
+		c.cache.timeSeries.RandomClearExpiration,
+		c.cache.rateLimiters.RandomClearExpiration,
+		c.cache.clearNegatives,
 	}
 	for {
 		select {
 		case <-c.stopC:
 			return
 		case <-ticker.C:
-			index := rand.Intn(5)
+			index := rand.Intn(len(clearList))
 			go clearList[index]()
 		}
 	}
@@ -1,14 +1,19 @@
 package go_cache
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"go-cache/types"
 	"math"
+	"math/rand"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/wk331100/go-cache/types"
 )
 
 var (
@@ -24,13 +29,339 @@ func TestGetSet(t *testing.T) {
 	require.Equal(t, name1, name)
 }
 
+func TestSetNXAndArgs(t *testing.T) {
+	key := "test_set_args"
+	require.True(t, c.SetNX(key, "v1"))
+	require.False(t, c.SetNX(key, "v2"))
+
+	old, set, err := c.SetArgs(key, "v3", types.SetArgs{Mode: types.SetModeNX, Get: true})
+	require.Nil(t, err)
+	require.False(t, set)
+	require.Equal(t, "v1", old)
+
+	old, set, err = c.SetArgs(key, "v4", types.SetArgs{Mode: types.SetModeXX, TTL: time.Hour, Get: true})
+	require.Nil(t, err)
+	require.True(t, set)
+	require.Equal(t, "v1", old)
+
+	// KEEPTTL 保留过期时间，普通的Set清除过期时间
+	_, _, err = c.SetArgs(key, "v5", types.SetArgs{KeepTTL: true})
+	require.Nil(t, err)
+	_, _, err = c.SetArgs(key, "v6", types.SetArgs{ExpireAt: time.Now().Add(-time.Second)})
+	require.Nil(t, err)
+	_, err = c.Get(key)
+	require.Equal(t, types.ErrKeyNotExist, err)
+
+	_, set, err = c.SetArgs(key, "v7", types.SetArgs{Mode: types.SetModeXX})
+	require.Nil(t, err)
+	require.False(t, set)
+	require.False(t, c.Exists(key))
+
+	_, _, err = c.SetArgs(key, "v", types.SetArgs{TTL: time.Second, KeepTTL: true})
+	require.Equal(t, types.ErrExpireArgs, err)
+	_, _, err = c.SetArgs(key, "v", types.SetArgs{Mode: "AB"})
+	require.Equal(t, types.ErrSetMode, err)
+
+	// 其他类型的k也视为存在，NX不写入，XX覆盖原有的数据
+	hk := "test_set_args_hash"
+	c.HSet(hk, "f", "v")
+	require.False(t, c.SetNX(hk, "v"))
+	_, set, err = c.SetArgs(hk, "v", types.SetArgs{Mode: types.SetModeNX})
+	require.Nil(t, err)
+	require.False(t, set)
+	v, err := c.HGet(hk, "f")
+	require.Nil(t, err)
+	require.Equal(t, "v", v)
+	_, set, err = c.SetArgs(hk, "v", types.SetArgs{Mode: types.SetModeXX})
+	require.Nil(t, err)
+	require.True(t, set)
+	_, err = c.HGet(hk, "f")
+	require.NotNil(t, err)
+	v, err = c.Get(hk)
+	require.Nil(t, err)
+	require.Equal(t, "v", v)
+	c.Del(hk)
+
+	c.SetEx(key, "v8", time.Millisecond)
+	c.Set(key, "v9")
+	time.Sleep(time.Millisecond * 5)
+	v, err = c.Get(key)
+	require.Nil(t, err)
+	require.Equal(t, "v9", v)
+}
+
+func TestGetSetDelEx(t *testing.T) {
+	key := "test_get_set_del"
+	require.Nil(t, c.GetSet(key, "a"))
+	require.True(t, c.Exists(key))
+	require.Equal(t, "a", c.GetSet(key, "b"))
+
+	v, err := c.GetEx(key, types.GetExArgs{TTL: time.Millisecond})
+	require.Nil(t, err)
+	require.Equal(t, "b", v)
+	_, err = c.GetEx(key, types.GetExArgs{Persist: true})
+	require.Nil(t, err)
+	time.Sleep(time.Millisecond * 5)
+
+	v, err = c.GetDel(key)
+	require.Nil(t, err)
+	require.Equal(t, "b", v)
+	_, err = c.GetDel(key)
+	require.Equal(t, types.ErrKeyNotExist, err)
+	_, err = c.GetEx(key, types.GetExArgs{TTL: -time.Second})
+	require.Equal(t, types.ErrExpireArgs, err)
+}
+
+func TestMSetMGet(t *testing.T) {
+	c.MSet(map[string]any{"test_mset_a": 1, "test_mset_b": "2"})
+	require.Equal(t, []any{1, nil, "2"}, c.MGet("test_mset_a", "test_mset_c", "test_mset_b"))
+	require.False(t, c.MSetNX(map[string]any{"test_mset_b": 3, "test_mset_c": 3}))
+	_, err := c.Get("test_mset_c")
+	require.Equal(t, types.ErrKeyNotExist, err)
+	require.True(t, c.MSetNX(map[string]any{"test_mset_c": 3, "test_mset_d": 4}))
+	require.Equal(t, []any{3, 4}, c.MGet("test_mset_c", "test_mset_d"))
+	require.True(t, c.Exists("test_mset_d"))
+
+	// 其他类型的k也视为存在，不写入任何k
+	c.HSet("test_mset_hash", "f", 1)
+	c.RPush("test_mset_list", 1)
+	require.Nil(t, c.SAdd("test_mset_set", 1))
+	for _, k := range []string{"test_mset_hash", "test_mset_list", "test_mset_set"} {
+		require.False(t, c.MSetNX(map[string]any{k: "x", "test_mset_e": 1}))
+		require.True(t, c.Exists(k))
+	}
+	require.False(t, c.Exists("test_mset_e"))
+	v, err := c.HGet("test_mset_hash", "f")
+	require.Nil(t, err)
+	require.Equal(t, 1, v)
+	require.Equal(t, 1, c.LLen("test_mset_list"))
+	require.Equal(t, 1, c.SCard("test_mset_set"))
+	c.Del("test_mset_hash")
+	c.Del("test_mset_list")
+	c.Del("test_mset_set")
+}
+
+func TestAppendAndRange(t *testing.T) {
+	key := "test_append"
+	n, err := c.Append(key, "Hello")
+	require.Nil(t, err)
+	require.Equal(t, 5, n)
+	n, err = c.Append(key, []byte(" World"))
+	require.Nil(t, err)
+	require.Equal(t, 11, n)
+	v, _ := c.Get(key)
+	require.Equal(t, "Hello World", v)
+
+	n, err = c.StrLen(key)
+	require.Nil(t, err)
+	require.Equal(t, 11, n)
+	r, err := c.GetRange(key, 0, 4)
+	require.Nil(t, err)
+	require.Equal(t, "Hello", r)
+	r, err = c.GetRange(key, -5, -1)
+	require.Nil(t, err)
+	require.Equal(t, "World", r)
+	r, err = c.GetRange(key, 20, 30)
+	require.Nil(t, err)
+	require.Equal(t, "", r)
+
+	n, err = c.SetRange(key, 6, "Redis")
+	require.Nil(t, err)
+	require.Equal(t, 11, n)
+	v, _ = c.Get(key)
+	require.Equal(t, "Hello Redis", v)
+
+	bytesKey := "test_setrange_bytes"
+	raw := []byte("ab")
+	c.Set(bytesKey, raw)
+	n, err = c.SetRange(bytesKey, 4, []byte("cd"))
+	require.Nil(t, err)
+	require.Equal(t, 6, n)
+	v, _ = c.Get(bytesKey)
+	require.Equal(t, []byte("ab\x00\x00cd"), v)
+	require.Equal(t, []byte("ab"), raw)
+
+	n, err = c.SetRange("test_setrange_empty", 3, "")
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	require.False(t, c.Exists("test_setrange_empty"))
+	_, err = c.SetRange(key, -1, "x")
+	require.Equal(t, types.ErrOffset, err)
+
+	c.Set("test_append_int", 1)
+	_, err = c.Append("test_append_int", "x")
+	require.Equal(t, types.ErrNotString, err)
+	_, err = c.StrLen("test_append_int")
+	require.Equal(t, types.ErrNotString, err)
+}
+
+func TestBitmap(t *testing.T) {
+	key := "test_bitmap"
+	old, err := c.SetBit(key, 7, 1)
+	require.Nil(t, err)
+	require.Equal(t, 0, old)
+	old, err = c.SetBit(key, 7, 0)
+	require.Nil(t, err)
+	require.Equal(t, 1, old)
+	for _, offset := range []int64{1, 2, 20, 100} {
+		_, err = c.SetBit(key, offset, 1)
+		require.Nil(t, err)
+	}
+	require.True(t, c.Exists(key))
+	n, err := c.StrLen(key)
+	require.Nil(t, err)
+	require.Equal(t, 13, n)
+
+	bit, err := c.GetBit(key, 20)
+	require.Nil(t, err)
+	require.Equal(t, 1, bit)
+	bit, err = c.GetBit(key, 10000)
+	require.Nil(t, err)
+	require.Equal(t, 0, bit)
+
+	count, err := c.BitCount(key, nil)
+	require.Nil(t, err)
+	require.Equal(t, int64(4), count)
+	count, err = c.BitCount(key, &types.BitRange{Start: 0, End: 0})
+	require.Nil(t, err)
+	require.Equal(t, int64(2), count)
+	count, err = c.BitCount(key, &types.BitRange{Start: 2, End: 20, Unit: types.BitUnitBit})
+	require.Nil(t, err)
+	require.Equal(t, int64(2), count)
+	count, err = c.BitCount(key, &types.BitRange{Start: -1, End: -1})
+	require.Nil(t, err)
+	require.Equal(t, int64(1), count)
+	_, err = c.BitCount(key, &types.BitRange{Unit: "WORD"})
+	require.Equal(t, types.ErrBitUnit, err)
+
+	pos, err := c.BitPos(key, 1, nil)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), pos)
+	pos, err = c.BitPos(key, 1, &types.BitRange{Start: 1, End: -1})
+	require.Nil(t, err)
+	require.Equal(t, int64(20), pos)
+	pos, err = c.BitPos(key, 0, nil)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), pos)
+	pos, err = c.BitPos("test_bitmap_not_exist", 1, nil)
+	require.Nil(t, err)
+	require.Equal(t, int64(-1), pos)
+
+	c.Set("test_bitmap_ones", []byte{0xff})
+	pos, err = c.BitPos("test_bitmap_ones", 0, nil)
+	require.Nil(t, err)
+	require.Equal(t, int64(8), pos)
+	pos, err = c.BitPos("test_bitmap_ones", 0, &types.BitRange{Start: 0, End: -1})
+	require.Nil(t, err)
+	require.Equal(t, int64(-1), pos)
+
+	_, err = c.SetBit(key, -1, 1)
+	require.Equal(t, types.ErrOffset, err)
+	_, err = c.SetBit(key, 1, 2)
+	require.Equal(t, types.ErrBitValue, err)
+}
+
+func TestBitmapCopyOnWrite(t *testing.T) {
+	key := "test_bitmap_cow"
+	raw := []byte{0x00}
+	c.Set(key, raw)
+	_, err := c.SetBit(key, 0, 1)
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x00}, raw)
+
+	v, err := c.Get(key)
+	require.Nil(t, err)
+	_, err = c.SetBit(key, 1, 1)
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x80}, v)
+	v, _ = c.Get(key)
+	require.Equal(t, []byte{0xc0}, v)
+}
+
+func TestBitOp(t *testing.T) {
+	c.Set("test_bitop_a", []byte{0xf0, 0x0f})
+	c.Set("test_bitop_b", "\x3c")
+	dst := "test_bitop_dst"
+
+	n, err := c.BitOp(types.BitAnd, dst, "test_bitop_a", "test_bitop_b")
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	v, _ := c.Get(dst)
+	require.Equal(t, []byte{0x30, 0x00}, v)
+
+	_, err = c.BitOp(types.BitOr, dst, "test_bitop_a", "test_bitop_b", "test_bitop_none")
+	require.Nil(t, err)
+	v, _ = c.Get(dst)
+	require.Equal(t, []byte{0xfc, 0x0f}, v)
+
+	_, err = c.BitOp(types.BitXor, dst, "test_bitop_a", "test_bitop_b")
+	require.Nil(t, err)
+	v, _ = c.Get(dst)
+	require.Equal(t, []byte{0xcc, 0x0f}, v)
+
+	_, err = c.BitOp(types.BitNot, dst, "test_bitop_a")
+	require.Nil(t, err)
+	v, _ = c.Get(dst)
+	require.Equal(t, []byte{0x0f, 0xf0}, v)
+
+	_, err = c.BitOp(types.BitNot, dst, "test_bitop_a", "test_bitop_b")
+	require.Equal(t, types.ErrBitOp, err)
+
+	n, err = c.BitOp(types.BitAnd, dst, "test_bitop_none")
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	require.False(t, c.Exists(dst))
+}
+
+func TestBitField(t *testing.T) {
+	key := "test_bitfield"
+	result, err := c.BitField(key, types.BitFieldOp{Cmd: types.BitFieldGet, Type: "u8"})
+	require.Nil(t, err)
+	require.Equal(t, []any{int64(0)}, result)
+	require.False(t, c.Exists(key))
+
+	result, err = c.BitField(key,
+		types.BitFieldOp{Cmd: types.BitFieldSet, Type: "i8", Offset: 0, Value: -100},
+		types.BitFieldOp{Cmd: types.BitFieldGet, Type: "i8", Offset: 0},
+		types.BitFieldOp{Cmd: types.BitFieldGet, Type: "u8", Offset: 0},
+		types.BitFieldOp{Cmd: types.BitFieldIncrBy, Type: "u4", Offset: 8, Value: 17},
+		types.BitFieldOp{Cmd: types.BitFieldIncrBy, Type: "u4", Offset: 12, Value: 20, Overflow: types.BitOverflowSat},
+		types.BitFieldOp{Cmd: types.BitFieldIncrBy, Type: "i8", Offset: 0, Value: -100, Overflow: types.BitOverflowFail},
+		types.BitFieldOp{Cmd: types.BitFieldIncrBy, Type: "i8", Offset: 0, Value: -100, Overflow: types.BitOverflowSat},
+	)
+	require.Nil(t, err)
+	require.Equal(t, []any{int64(0), int64(-100), int64(156), int64(1), int64(15), nil, int64(-128)}, result)
+	require.True(t, c.Exists(key))
+	v, _ := c.Get(key)
+	require.Equal(t, []byte{0x80, 0x1f}, v)
+
+	result, err = c.BitField(key,
+		types.BitFieldOp{Cmd: types.BitFieldIncrBy, Type: "i64", Offset: 16, Value: math.MaxInt64},
+		types.BitFieldOp{Cmd: types.BitFieldIncrBy, Type: "i64", Offset: 16, Value: 1},
+		types.BitFieldOp{Cmd: types.BitFieldIncrBy, Type: "u63", Offset: 80, Value: math.MinInt64, Overflow: types.BitOverflowSat},
+	)
+	require.Nil(t, err)
+	require.Equal(t, []any{int64(math.MaxInt64), int64(math.MinInt64), int64(0)}, result)
+
+	_, err = c.BitField(key, types.BitFieldOp{Cmd: types.BitFieldGet, Type: "u64"})
+	require.Equal(t, types.ErrBitField, err)
+	_, err = c.BitField(key, types.BitFieldOp{Cmd: "DEL", Type: "u8"})
+	require.Equal(t, types.ErrBitField, err)
+	_, err = c.BitField(key, types.BitFieldOp{Cmd: types.BitFieldGet, Type: "u8", Offset: -1})
+	require.Equal(t, types.ErrOffset, err)
+}
+
 func TestIncrDecr(t *testing.T) {
 	key := "count"
-	c.Incr(key)
+	n, err := c.Incr(key)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), n)
 	num, err := c.Get(key)
 	require.Nil(t, err)
 	require.Equal(t, int64(1), num)
-	c.Decr(key)
+	n, err = c.Decr(key)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), n)
 	num, err = c.Get(key)
 	require.Nil(t, err)
 	require.Equal(t, int64(0), num)
@@ -38,16 +369,84 @@ func TestIncrDecr(t *testing.T) {
 
 func TestIncrByDecrBy(t *testing.T) {
 	key := "count"
-	c.IncrBy(key, 100)
-	num, err := c.Get(key)
+	n, err := c.IncrBy(key, 100)
 	require.Nil(t, err)
-	require.Equal(t, int64(100), num)
-	c.DecrBy(key, 50)
-	num, err = c.Get(key)
+	require.Equal(t, int64(100), n)
+	n, err = c.DecrBy(key, 50)
+	require.Nil(t, err)
+	require.Equal(t, int64(50), n)
+	num, err := c.Get(key)
 	require.Nil(t, err)
 	require.Equal(t, int64(50), num)
 }
 
+func TestIncrTypes(t *testing.T) {
+	key := "test_incr_types"
+	c.Set(key, 5)
+	n, err := c.Incr(key)
+	require.Nil(t, err)
+	require.Equal(t, int64(6), n)
+
+	c.Set(key, "10")
+	n, err = c.IncrBy(key, 5)
+	require.Nil(t, err)
+	require.Equal(t, int64(15), n)
+
+	c.Set(key, []byte("-3"))
+	n, err = c.Decr(key)
+	require.Nil(t, err)
+	require.Equal(t, int64(-4), n)
+
+	c.Set(key, "abc")
+	_, err = c.Incr(key)
+	require.Equal(t, types.ErrNotInteger, err)
+	c.Set(key, " 1")
+	_, err = c.Incr(key)
+	require.Equal(t, types.ErrNotInteger, err)
+
+	c.Set(key, int64(math.MaxInt64))
+	_, err = c.Incr(key)
+	require.Equal(t, types.ErrOverflow, err)
+	c.Set(key, 0)
+	_, err = c.DecrBy(key, math.MinInt64)
+	require.Equal(t, types.ErrOverflow, err)
+
+	// 过期的key从0开始计数
+	c.SetEx(key, int64(100), time.Millisecond)
+	time.Sleep(time.Millisecond * 5)
+	n, err = c.IncrBy(key, 2)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), n)
+
+	newKey := "test_incr_new"
+	_, err = c.Incr(newKey)
+	require.Nil(t, err)
+	require.True(t, c.Exists(newKey))
+}
+
+func TestIncrByFloat(t *testing.T) {
+	key := "test_incr_float"
+	f, err := c.IncrByFloat(key, 10.5)
+	require.Nil(t, err)
+	require.Equal(t, 10.5, f)
+	c.Set(key, "5.0e3")
+	f, err = c.IncrByFloat(key, 200)
+	require.Nil(t, err)
+	require.Equal(t, float64(5200), f)
+	v, _ := c.Get(key)
+	require.Equal(t, float64(5200), v)
+	c.Set(key, 3)
+	f, err = c.IncrByFloat(key, -0.5)
+	require.Nil(t, err)
+	require.Equal(t, 2.5, f)
+	c.Set(key, "x")
+	_, err = c.IncrByFloat(key, 1)
+	require.Equal(t, types.ErrNotFloat, err)
+	c.Set(key, math.MaxFloat64)
+	_, err = c.IncrByFloat(key, math.MaxFloat64)
+	require.Equal(t, types.ErrNotFloat, err)
+}
+
 func TestLPushRPopLLen(t *testing.T) {
 	key := "queue"
 	c.LPush(key, 5)
@@ -91,6 +490,305 @@ func TestLRange(t *testing.T) {
 	}
 }
 
+func TestListDeque(t *testing.T) {
+	key := "deque"
+	loop := 1000
+	for i := 0; i < loop; i++ {
+		if i%2 == 0 {
+			c.LPush(key, -i)
+		} else {
+			c.RPush(key, i)
+		}
+	}
+	require.Equal(t, loop, c.LLen(key))
+	l, err := c.LRange(key, 0, 2)
+	require.Nil(t, err)
+	require.Equal(t, []any{-998, -996, -994}, l)
+	l, err = c.LRange(key, -3, -1)
+	require.Nil(t, err)
+	require.Equal(t, []any{995, 997, 999}, l)
+	for i := loop - 1; i > 0; i -= 2 {
+		v, err := c.RPop(key)
+		require.Nil(t, err)
+		require.Equal(t, i, v)
+	}
+	for i := loop - 2; i >= 0; i -= 2 {
+		v, err := c.LPop(key)
+		require.Nil(t, err)
+		require.Equal(t, -i, v)
+	}
+	require.Equal(t, 0, c.LLen(key))
+}
+
+func TestLIndexLSetLInsert(t *testing.T) {
+	key := "letters"
+	c.RPush(key, "a")
+	c.RPush(key, "c")
+	v, err := c.LIndex(key, -1)
+	require.Nil(t, err)
+	require.Equal(t, "c", v)
+	_, err = c.LIndex(key, 2)
+	require.Equal(t, types.ErrIndex, err)
+
+	n, err := c.LInsert(key, types.ListBefore, "c", "b")
+	require.Nil(t, err)
+	require.Equal(t, 3, n)
+	n, err = c.LInsert(key, types.ListAfter, "c", "d")
+	require.Nil(t, err)
+	require.Equal(t, 4, n)
+	n, err = c.LInsert(key, types.ListAfter, "x", "y")
+	require.Nil(t, err)
+	require.Equal(t, -1, n)
+	n, err = c.LInsert("notExist", types.ListAfter, "x", "y")
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+
+	require.Nil(t, c.LSet(key, 0, "A"))
+	require.Equal(t, types.ErrIndex, c.LSet(key, 4, "E"))
+	l, err := c.LRange(key, 0, 3)
+	require.Nil(t, err)
+	require.Equal(t, []any{"A", "b", "c", "d"}, l)
+}
+
+func TestLRemLTrimLPos(t *testing.T) {
+	key := "events"
+	for _, v := range []any{"a", "b", "a", "c", "a", []byte("b")} {
+		c.RPush(key, v)
+	}
+	pos, err := c.LPos(key, "a", types.LPosArgs{})
+	require.Nil(t, err)
+	require.Equal(t, 0, pos)
+	pos, err = c.LPos(key, "a", types.LPosArgs{Rank: -1})
+	require.Nil(t, err)
+	require.Equal(t, 4, pos)
+	pos, err = c.LPos(key, "a", types.LPosArgs{Rank: 2, MaxLen: 2})
+	require.Nil(t, err)
+	require.Equal(t, -1, pos)
+	positions, err := c.LPosCount(key, "a", 0, types.LPosArgs{Rank: 2})
+	require.Nil(t, err)
+	require.Equal(t, []int{2, 4}, positions)
+	positions, err = c.LPosCount(key, []byte("b"), 1, types.LPosArgs{})
+	require.Nil(t, err)
+	require.Equal(t, []int{5}, positions)
+
+	require.Equal(t, 1, c.LRem(key, -1, "a"))
+	require.Equal(t, 2, c.LRem(key, 0, "a"))
+	l, err := c.LRange(key, 0, 10)
+	require.Nil(t, err)
+	require.Equal(t, []any{"b", "c", []byte("b")}, l)
+
+	// 只保留最近的N条记录
+	capped := "lastEvents"
+	for i := 0; i < 10; i++ {
+		c.LPush(capped, i)
+		c.LTrim(capped, 0, 2)
+	}
+	l, err = c.LRange(capped, 0, 10)
+	require.Nil(t, err)
+	require.Equal(t, []any{9, 8, 7}, l)
+	c.LTrim(capped, 5, 10)
+	require.False(t, c.Exists(capped))
+
+	// 接口字段保存切片的结构体按深度比较
+	type S struct {
+		X any
+	}
+	sk := "test_lrem_struct"
+	c.RPush(sk, S{X: []int{1}}, S{X: []int{2}}, S{X: 1})
+	pos, err = c.LPos(sk, S{X: []int{2}}, types.LPosArgs{})
+	require.Nil(t, err)
+	require.Equal(t, 1, pos)
+	require.Equal(t, 1, c.LRem(sk, 0, S{X: []int{1}}))
+	l, err = c.LRange(sk, 0, -1)
+	require.Nil(t, err)
+	require.Equal(t, []any{S{X: []int{2}}, S{X: 1}}, l)
+	c.Del(sk)
+}
+
+func TestLMove(t *testing.T) {
+	src := "moveSrc"
+	dst := "moveDst"
+	c.RPush(src, 1)
+	c.RPush(src, 2)
+	v, err := c.LMove(src, dst, types.ListLeft, types.ListRight)
+	require.Nil(t, err)
+	require.Equal(t, 1, v)
+	v, err = c.RPopLPush(src, dst)
+	require.Nil(t, err)
+	require.Equal(t, 2, v)
+	require.False(t, c.Exists(src))
+	l, err := c.LRange(dst, 0, 1)
+	require.Nil(t, err)
+	require.Equal(t, []any{2, 1}, l)
+	_, err = c.RPopLPush(src, dst)
+	require.Equal(t, types.ErrKeyNotExist, err)
+
+	// dst为其他类型时删除原有的数据
+	sk := "moveDstString"
+	c.Set(sk, "str")
+	c.RPush(src, 3)
+	v, err = c.LMove(src, sk, types.ListLeft, types.ListRight)
+	require.Nil(t, err)
+	require.Equal(t, 3, v)
+	_, err = c.Get(sk)
+	require.Equal(t, types.ErrKeyNotExist, err)
+	l, err = c.LRange(sk, 0, -1)
+	require.Nil(t, err)
+	require.Equal(t, []any{3}, l)
+	c.Del(sk)
+}
+
+func TestMultiPushPop(t *testing.T) {
+	key := "batch"
+	require.Equal(t, 0, c.LPushX(key, "x"))
+	require.Equal(t, 0, c.RPushX(key, "x"))
+	require.False(t, c.Exists(key))
+
+	require.Equal(t, 3, c.LPush(key, 1, 2, 3))
+	require.Equal(t, 5, c.RPush(key, 4, 5))
+	require.Equal(t, 6, c.LPushX(key, 0))
+	require.Equal(t, 7, c.RPushX(key, 6))
+	l, err := c.LRange(key, 0, 10)
+	require.Nil(t, err)
+	require.Equal(t, []any{0, 3, 2, 1, 4, 5, 6}, l)
+
+	vs, err := c.LPopCount(key, 2)
+	require.Nil(t, err)
+	require.Equal(t, []any{0, 3}, vs)
+	vs, err = c.RPopCount(key, 2)
+	require.Nil(t, err)
+	require.Equal(t, []any{6, 5}, vs)
+	vs, err = c.LPopCount(key, 10)
+	require.Nil(t, err)
+	require.Equal(t, []any{2, 1, 4}, vs)
+	require.False(t, c.Exists(key))
+	_, err = c.RPopCount(key, 1)
+	require.Equal(t, types.ErrKeyNotExist, err)
+}
+
+func TestPopEmptyList(t *testing.T) {
+	key := "emptyQueue"
+	c.RPush(key, 1)
+	v, err := c.LPop(key)
+	require.Nil(t, err)
+	require.Equal(t, 1, v)
+	_, err = c.LPop(key)
+	require.Equal(t, types.ErrKeyNotExist, err)
+	_, err = c.RPop(key)
+	require.Equal(t, types.ErrKeyNotExist, err)
+	require.False(t, c.Exists(key))
+}
+
+func TestBLPopBRPop(t *testing.T) {
+	k1 := "jobs1"
+	k2 := "jobs2"
+	c.RPush(k2, "a")
+	key, v, err := c.BLPop(context.Background(), time.Second, k1, k2)
+	require.Nil(t, err)
+	require.Equal(t, k2, key)
+	require.Equal(t, "a", v)
+
+	_, _, err = c.BRPop(context.Background(), 10*time.Millisecond, k1, k2)
+	require.Equal(t, types.ErrTimeout, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = c.BLPop(ctx, 0, k1)
+	require.Equal(t, context.Canceled, err)
+
+	// 先等待的调用方先获取元素
+	results := make(chan any, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, v, err := c.BRPop(context.Background(), time.Second, k1)
+			if err != nil {
+				v = err
+			}
+			results <- v
+		}()
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.LPush(k1, "first")
+	require.Equal(t, "first", <-results)
+	c.LPush(k1, "second")
+	require.Equal(t, "second", <-results)
+	require.Equal(t, 0, c.LLen(k1))
+}
+
+func TestBLMove(t *testing.T) {
+	src := "pending"
+	dst := "processing"
+	done := make(chan any)
+	go func() {
+		v, err := c.BLMove(context.Background(), src, dst, types.ListRight, types.ListLeft, time.Second)
+		if err != nil {
+			v = err
+		}
+		done <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+	c.LPush(src, "job1")
+	require.Equal(t, "job1", <-done)
+	require.Equal(t, 0, c.LLen(src))
+	require.Equal(t, 1, c.LLen(dst))
+	require.True(t, c.Exists(dst))
+
+	c.RPush(src, "job2")
+	v, err := c.BLMove(context.Background(), src, dst, types.ListLeft, types.ListRight, time.Second)
+	require.Nil(t, err)
+	require.Equal(t, "job2", v)
+	l, err := c.LRange(dst, 0, 1)
+	require.Nil(t, err)
+	require.Equal(t, []any{"job1", "job2"}, l)
+
+	// dst为其他类型时删除原有的数据
+	sk := "processingString"
+	c.Set(sk, "str")
+	c.RPush(src, "job3")
+	v, err = c.BLMove(context.Background(), src, sk, types.ListLeft, types.ListRight, time.Second)
+	require.Nil(t, err)
+	require.Equal(t, "job3", v)
+	_, err = c.Get(sk)
+	require.Equal(t, types.ErrKeyNotExist, err)
+	require.Equal(t, 1, c.LLen(sk))
+	c.Del(sk)
+}
+
+func TestLRangeCopy(t *testing.T) {
+	key := "rangeQueue"
+	c.RPush(key, 1, 2, 3)
+	l, err := c.LRange(key, 0, -1)
+	require.Nil(t, err)
+	require.Equal(t, []any{1, 2, 3}, l)
+	l[0] = 100
+	l, err = c.LRange(key, -100, 100)
+	require.Nil(t, err)
+	require.Equal(t, []any{1, 2, 3}, l)
+	l, err = c.LRange(key, 2, 1)
+	require.Nil(t, err)
+	require.Equal(t, []any{}, l)
+	l, err = c.LRange(key, 5, 10)
+	require.Nil(t, err)
+	require.Equal(t, []any{}, l)
+
+	// 并发读写时，读取到的结果不受后续写入影响
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			c.LPush(key, i)
+			_, _ = c.RPop(key)
+		}
+		close(done)
+	}()
+	for i := 0; i < 1000; i++ {
+		l, _ := c.LRange(key, 0, -1)
+		for j := range l {
+			l[j] = nil
+		}
+	}
+	<-done
+}
+
 func TestHGetHSet(t *testing.T) {
 	key := "hKey"
 	c.HSet(key, "name", name1)
@@ -109,16 +807,166 @@ func TestHKeysValsDel(t *testing.T) {
 	c.HSet(key, "age", 18)
 	keys, err := c.HKeys(key)
 	require.Nil(t, err)
-	require.Equal(t, []string{"name", "age"}, keys)
+	require.ElementsMatch(t, []string{"name", "age"}, keys)
 	vals, err := c.HVals(key)
 	require.Nil(t, err)
-	require.Equal(t, []any{name1, 18}, vals)
+	require.ElementsMatch(t, []any{name1, 18}, vals)
 	c.HDel(key, "age")
 	keys, err = c.HKeys(key)
 	require.Nil(t, err)
 	require.Equal(t, []string{"name"}, keys)
 }
 
+func TestHStrLen(t *testing.T) {
+	key := "test_hstrlen"
+	c.HSet(key, "name", "hello")
+	c.HSet(key, "bytes", []byte("abc"))
+	c.HSet(key, "age", 18)
+	n, err := c.HStrLen(key, "name")
+	require.Nil(t, err)
+	require.Equal(t, 5, n)
+	n, err = c.HStrLen(key, "bytes")
+	require.Nil(t, err)
+	require.Equal(t, 3, n)
+	_, err = c.HStrLen(key, "age")
+	require.Equal(t, types.ErrNotString, err)
+	n, err = c.HStrLen(key, "none")
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	n, err = c.HStrLen("test_hstrlen_none", "name")
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	c.Del(key)
+}
+
+func TestHMSetHMGet(t *testing.T) {
+	key := "profile"
+	require.Equal(t, 2, c.HMSet(key, map[string]any{"name": name1, "age": 18}))
+	require.Equal(t, 1, c.HMSet(key, map[string]any{"name": name2, "city": "beijing"}))
+	require.Equal(t, 3, c.HLen(key))
+	vals, err := c.HMGet(key, "name", "gender", "age")
+	require.Nil(t, err)
+	require.Equal(t, []any{name2, nil, 18}, vals)
+	all, err := c.HGetAll(key)
+	require.Nil(t, err)
+	require.Equal(t, map[string]any{"name": name2, "age": 18, "city": "beijing"}, all)
+	all["name"] = "changed"
+	name, err := c.HGet(key, "name")
+	require.Nil(t, err)
+	require.Equal(t, name2, name)
+
+	require.False(t, c.HSetNX(key, "name", name1))
+	require.True(t, c.HSetNX(key, "gender", "male"))
+	require.True(t, c.HSetNX("newProfile", "name", name1))
+	require.True(t, c.Exists("newProfile"))
+
+	require.Equal(t, 2, c.HDel(key, "name", "age", "notExist"))
+	require.Equal(t, 2, c.HLen(key))
+	require.Equal(t, 2, c.HDel(key, "city", "gender"))
+	require.False(t, c.Exists(key))
+	_, err = c.HGetAll(key)
+	require.Equal(t, types.ErrHashKey, err)
+
+	// k为其他类型时删除原有的数据
+	writers := []func(k string){
+		func(k string) { c.HMSet(k, map[string]any{"f": 1}) },
+		func(k string) { c.HSetNX(k, "f", 1) },
+		func(k string) { _, _ = c.HIncrBy(k, "f", 1) },
+		func(k string) { _, _ = c.HIncrByFloat(k, "f", 1) },
+	}
+	for i, write := range writers {
+		k := "profileString" + strconv.Itoa(i)
+		c.Set(k, "str")
+		write(k)
+		_, err = c.Get(k)
+		require.Equal(t, types.ErrKeyNotExist, err)
+		require.Equal(t, 1, c.HLen(k))
+		c.Del(k)
+	}
+}
+
+func TestHIncrBy(t *testing.T) {
+	key := "stats"
+	n, err := c.HIncrBy(key, "views", 10)
+	require.Nil(t, err)
+	require.Equal(t, int64(10), n)
+	require.True(t, c.Exists(key))
+	c.HSet(key, "likes", "5")
+	n, err = c.HIncrBy(key, "likes", -2)
+	require.Nil(t, err)
+	require.Equal(t, int64(3), n)
+	c.HSet(key, "name", name1)
+	_, err = c.HIncrBy(key, "name", 1)
+	require.Equal(t, types.ErrNotInteger, err)
+	c.HSet(key, "max", int64(math.MaxInt64))
+	_, err = c.HIncrBy(key, "max", 1)
+	require.Equal(t, types.ErrOverflow, err)
+
+	f, err := c.HIncrByFloat(key, "score", 1.5)
+	require.Nil(t, err)
+	require.Equal(t, 1.5, f)
+	f, err = c.HIncrByFloat(key, "views", 0.5)
+	require.Nil(t, err)
+	require.Equal(t, 10.5, f)
+	_, err = c.HIncrBy(key, "views", 1)
+	require.Equal(t, types.ErrNotInteger, err)
+	_, err = c.HIncrByFloat(key, "name", 1)
+	require.Equal(t, types.ErrNotFloat, err)
+}
+
+func TestHRandField(t *testing.T) {
+	key := "randHash"
+	c.HMSet(key, map[string]any{"a": 1, "b": 2, "c": 3})
+	fields, err := c.HRandField(key, 2)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(fields))
+	require.NotEqual(t, fields[0], fields[1])
+	fields, err = c.HRandField(key, 5)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"a", "b", "c"}, fields)
+	fields, err = c.HRandField(key, -5)
+	require.Nil(t, err)
+	require.Equal(t, 5, len(fields))
+}
+
+func TestHExpire(t *testing.T) {
+	key := "flags"
+	c.HMSet(key, map[string]any{"a": 1, "b": 2, "c": 3})
+	rs, err := c.HExpire(key, 50*time.Millisecond, "a", "b", "notExist")
+	require.Nil(t, err)
+	require.Equal(t, []int{types.FieldExpireSet, types.FieldExpireSet, types.FieldNotExist}, rs)
+	rs, err = c.HPersist(key, "b", "c", "notExist")
+	require.Nil(t, err)
+	require.Equal(t, []int{types.FieldPersisted, types.FieldNoExpiration, types.FieldNotExist}, rs)
+	ttl, err := c.HTTL(key, "a", "b", "notExist")
+	require.Nil(t, err)
+	require.True(t, ttl[0] > 0 && ttl[0] <= 50)
+	require.Equal(t, []int64{types.FieldNoExpiration, types.FieldNotExist}, ttl[1:])
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = c.HGet(key, "a")
+	require.Equal(t, types.ErrHashField, err)
+	require.Equal(t, 2, c.HLen(key))
+
+	// 覆盖写入field时清除过期时间
+	c.HExpire(key, 10*time.Millisecond, "b")
+	c.HSet(key, "b", 20)
+	rs, err = c.HExpireAt(key, time.Now().Add(-time.Second), "c")
+	require.Nil(t, err)
+	require.Equal(t, []int{types.FieldExpired}, rs)
+	time.Sleep(20 * time.Millisecond)
+	all, err := c.HGetAll(key)
+	require.Nil(t, err)
+	require.Equal(t, map[string]any{"b": 20}, all)
+
+	// 最后一个field过期后删除整个Hash
+	c.HExpire(key, 10*time.Millisecond, "b")
+	time.Sleep(20 * time.Millisecond)
+	require.False(t, c.Exists(key))
+	_, err = c.HTTL(key, "b")
+	require.Equal(t, types.ErrHashKey, err)
+}
+
 func TestSAddSRem(t *testing.T) {
 	key := "class1"
 	m1 := "zhangSan"
@@ -150,18 +998,161 @@ func TestSUnionSInter(t *testing.T) {
 	c.SAdd(key2, m3)
 	ms, err := c.SMembers(key1)
 	require.Nil(t, err)
-	require.Equal(t, []any{m1, m2}, ms)
+	require.ElementsMatch(t, []any{m1, m2}, ms)
 	union := c.SUnion(key1, key2)
-	um, _ := union.SMembers()
-	require.Equal(t, 3, len(um))
+	require.ElementsMatch(t, []any{m1, m2, m3}, union)
 	inter := c.SInter(key1, key2)
-	im, _ := inter.SMembers()
-	require.Equal(t, 1, len(im))
+	require.Equal(t, []any{m2}, inter)
+	diff := c.SDiff(key1, key2)
+	require.Equal(t, []any{m1}, diff)
+	require.Equal(t, 1, c.SInterCard(0, key1, key2))
+	require.Equal(t, 0, c.SInterCard(0, key1, key2, "notExist"))
+
+	// 修改返回结果不应影响缓存中的数据
+	union = c.SUnion(key1, "notExist")
+	union[0] = "changed"
+	ms, err = c.SMembers(key1)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []any{m1, m2}, ms)
 }
 
-func TestZAddZRem(t *testing.T) {
-	key := "english"
-	e1 := "zhangSan"
+func TestSStore(t *testing.T) {
+	key1 := "group1"
+	key2 := "group2"
+	key3 := "group3"
+	dst := "groupAll"
+	c.SAdd(key1, "a")
+	c.SAdd(key1, "b")
+	c.SAdd(key2, "b")
+	c.SAdd(key2, "c")
+	c.SAdd(key3, "b")
+	c.HSet(dst, "field", "v")
+
+	require.Equal(t, 3, c.SUnionStore(dst, key1, key2, key3))
+	require.Equal(t, 3, c.SCard(dst))
+	_, err := c.HGet(dst, "field")
+	require.Equal(t, types.ErrHashKey, err)
+	require.Equal(t, 2, c.SInterCard(0, dst, key1))
+	require.Equal(t, 1, c.SInterCard(1, dst, key1))
+
+	require.Equal(t, 1, c.SInterStore(dst, key1, key2, key3))
+	ms, err := c.SMembers(dst)
+	require.Nil(t, err)
+	require.Equal(t, []any{"b"}, ms)
+
+	require.Equal(t, 1, c.SDiffStore(dst, key2, key1))
+	ms, err = c.SMembers(dst)
+	require.Nil(t, err)
+	require.Equal(t, []any{"c"}, ms)
+
+	require.Equal(t, 0, c.SDiffStore(dst, key3, key1))
+	require.False(t, c.Exists(dst))
+}
+
+func TestSPopSRandMember(t *testing.T) {
+	key := "pool"
+	for i := 0; i < 10; i++ {
+		c.SAdd(key, i)
+	}
+	ms, err := c.SRandMember(key, 3)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(ms))
+	seen := make(map[any]struct{})
+	for _, m := range ms {
+		seen[m] = struct{}{}
+	}
+	require.Equal(t, 3, len(seen))
+	ms, err = c.SRandMember(key, 20)
+	require.Nil(t, err)
+	require.Equal(t, 10, len(ms))
+	ms, err = c.SRandMember(key, -20)
+	require.Nil(t, err)
+	require.Equal(t, 20, len(ms))
+	require.Equal(t, 10, c.SCard(key))
+
+	ms, err = c.SPop(key, 4)
+	require.Nil(t, err)
+	require.Equal(t, 4, len(ms))
+	require.Equal(t, 6, c.SCard(key))
+	for _, m := range ms {
+		isMember, _ := c.SIsMember(key, m)
+		require.False(t, isMember)
+	}
+	ms, err = c.SPop(key, 10)
+	require.Nil(t, err)
+	require.Equal(t, 6, len(ms))
+	require.False(t, c.Exists(key))
+	_, err = c.SPop(key, 1)
+	require.Equal(t, types.ErrSetKey, err)
+}
+
+func TestSMoveSMIsMember(t *testing.T) {
+	src := "bucketA"
+	dst := "bucketB"
+	c.SAdd(src, "u1")
+	c.SAdd(src, "u2")
+	moved, err := c.SMove(src, dst, "u1")
+	require.Nil(t, err)
+	require.True(t, moved)
+	moved, err = c.SMove(src, dst, "u3")
+	require.Nil(t, err)
+	require.False(t, moved)
+	rs, err := c.SMIsMember(src, "u1", "u2", "u3")
+	require.Nil(t, err)
+	require.Equal(t, []bool{false, true, false}, rs)
+	rs, err = c.SMIsMember(dst, "u1", "u2")
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, false}, rs)
+	require.True(t, c.Exists(dst))
+	moved, err = c.SMove(src, dst, "u2")
+	require.Nil(t, err)
+	require.True(t, moved)
+	require.False(t, c.Exists(src))
+	require.Equal(t, 2, c.SCard(dst))
+
+	// dst为其他类型时删除原有的数据
+	sk := "bucketString"
+	c.Set(sk, "s")
+	moved, err = c.SMove(dst, sk, "u1")
+	require.Nil(t, err)
+	require.True(t, moved)
+	_, err = c.Get(sk)
+	require.Equal(t, types.ErrKeyNotExist, err)
+	require.Equal(t, 1, c.SCard(sk))
+	c.Del(sk)
+}
+
+func TestMemberEncoding(t *testing.T) {
+	key := "numbers"
+	require.Nil(t, c.SAdd(key, 1))
+	require.Nil(t, c.SAdd(key, int64(2)))
+	require.Nil(t, c.SAdd(key, []byte("3")))
+	require.Nil(t, c.SAdd(key, "1"))
+	require.Equal(t, 3, c.SCard(key))
+	isMember, err := c.SIsMember(key, uint8(2))
+	require.Nil(t, err)
+	require.True(t, isMember)
+	require.Nil(t, c.SRem(key, 1))
+	require.Equal(t, 2, c.SCard(key))
+	require.Equal(t, types.ErrMemberType, c.SAdd(key, []int{1}))
+	require.Equal(t, types.ErrMemberType, c.SRem(key, map[string]int{}))
+	_, err = c.SIsMember(key, struct{}{})
+	require.Equal(t, types.ErrMemberType, err)
+
+	zKey := "scores"
+	require.Nil(t, c.ZAdd(zKey, 1001, 90))
+	require.Nil(t, c.ZAdd(zKey, "1002", 80))
+	require.Equal(t, 1, c.ZRank(zKey, "1001"))
+	require.Equal(t, 2, c.ZRank(zKey, 1002))
+	require.Equal(t, types.ErrMemberType, c.ZAdd(zKey, []string{"a"}, 1))
+	require.Equal(t, types.ErrorRank, c.ZRank(zKey, []string{"a"}))
+	require.Nil(t, c.ZRem(zKey, 1001))
+	require.Equal(t, 1, c.ZCard(zKey))
+}
+
+func TestZAddZRem(t *testing.T) {
+	key := "english"
+	e1 := "zhangSan"
 	e2 := "liSi"
 	e3 := "wangWu"
 	c.ZAdd(key, e1, 100)
@@ -181,9 +1172,11 @@ func TestZIncrDecr(t *testing.T) {
 	c.ZAdd(key, e1, 100)
 	c.ZAdd(key, e2, 90)
 
-	res1 := c.ZIncrBy(key, e1, 20)
+	res1, err := c.ZIncrBy(key, e1, 20)
+	require.Nil(t, err)
 	require.Equal(t, float64(120), res1)
-	res2 := c.ZDecrBy(key, e2, 10)
+	res2, err := c.ZDecrBy(key, e2, 10)
+	require.Nil(t, err)
 	require.Equal(t, float64(80), res2)
 }
 
@@ -234,6 +1227,1362 @@ func TestRange(t *testing.T) {
 	require.Equal(t, map[string]float64{e3: 95}, m)
 }
 
+func TestZUnionZInter(t *testing.T) {
+	k1 := "daily1"
+	k2 := "daily2"
+	k3 := "dailyVip"
+	c.ZAdd(k1, "zhangSan", 10)
+	c.ZAdd(k1, "liSi", 20)
+	c.ZAdd(k2, "liSi", 5)
+	c.ZAdd(k2, "wangWu", 30)
+	c.SAdd(k3, "liSi")
+
+	union, err := c.ZUnion(&types.ZStore{Keys: []string{k1, k2}})
+	require.Nil(t, err)
+	require.Equal(t, map[string]float64{"zhangSan": 10, "liSi": 25, "wangWu": 30}, union)
+	union, err = c.ZUnion(&types.ZStore{Keys: []string{k1, k2}, Weights: []float64{2, 1}, Aggregate: types.AggregateMax})
+	require.Nil(t, err)
+	require.Equal(t, map[string]float64{"zhangSan": 20, "liSi": 40, "wangWu": 30}, union)
+
+	inter, err := c.ZInter(&types.ZStore{Keys: []string{k1, k2, k3}, Aggregate: types.AggregateMin})
+	require.Nil(t, err)
+	require.Equal(t, map[string]float64{"liSi": 1}, inter)
+
+	_, err = c.ZUnion(&types.ZStore{Keys: []string{k1, k2}, Weights: []float64{1}})
+	require.Equal(t, types.ErrWeights, err)
+	_, err = c.ZInter(&types.ZStore{Keys: []string{k1}, Aggregate: "AVG"})
+	require.Equal(t, types.ErrAggregate, err)
+}
+
+func TestZStore(t *testing.T) {
+	k1 := "weekly1"
+	k2 := "weekly2"
+	dst := "weeklyRank"
+	c.ZAdd(k1, "zhangSan", 10)
+	c.ZAdd(k1, "liSi", 20)
+	c.ZAdd(k2, "liSi", 5)
+	c.Set(dst, "old")
+
+	n, err := c.ZUnionStore(dst, &types.ZStore{Keys: []string{k1, k2}})
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, 2, c.ZCard(dst))
+	require.Equal(t, 1, c.ZRank(dst, "liSi"))
+	_, err = c.Get(dst)
+	require.Equal(t, types.ErrKeyNotExist, err)
+
+	diff, err := c.ZDiff(k1, k2)
+	require.Nil(t, err)
+	require.Equal(t, map[string]float64{"zhangSan": 10}, diff)
+
+	n, err = c.ZInterStore(dst, &types.ZStore{Keys: []string{k1, "notExist"}})
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	require.False(t, c.Exists(dst))
+}
+
+func TestZRangeCopy(t *testing.T) {
+	key := "rangeZSet"
+	c.ZAdd(key, "a", 3)
+	c.ZAdd(key, "b", 2)
+	c.ZAdd(key, "c", 1)
+	elements, err := c.ZRange(key, 0, -1)
+	require.Nil(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, elements)
+	elements[0] = "changed"
+	elements, err = c.ZRange(key, -100, 0)
+	require.Nil(t, err)
+	require.Equal(t, []string{"a"}, elements)
+	elements, err = c.ZRevRange(key, 1, -1)
+	require.Nil(t, err)
+	require.Equal(t, []string{"b", "a"}, elements)
+	elements, err = c.ZRange(key, 3, 5)
+	require.Nil(t, err)
+	require.Equal(t, []string{}, elements)
+	m, err := c.ZRangeWithScore(key, 2, 1)
+	require.Nil(t, err)
+	require.Equal(t, map[string]float64{}, m)
+}
+
+func TestPFAddCount(t *testing.T) {
+	key := "test_pf"
+	changed, err := c.PFAdd(key)
+	require.Nil(t, err)
+	require.True(t, changed)
+	require.True(t, c.Exists(key))
+	require.Equal(t, int64(0), c.PFCount(key))
+
+	changed, err = c.PFAdd(key, "a", "b", "c", 1, []byte("a"))
+	require.Nil(t, err)
+	require.True(t, changed)
+	changed, err = c.PFAdd(key, "a", "1")
+	require.Nil(t, err)
+	require.False(t, changed)
+	require.Equal(t, int64(4), c.PFCount(key))
+	_, err = c.PFAdd(key, struct{}{})
+	require.Equal(t, types.ErrMemberType, err)
+
+	// 稀疏表示转换为密集表示后，误差仍在范围内
+	for _, n := range []int{1000, 100000} {
+		k := "test_pf_" + strconv.Itoa(n)
+		for i := 0; i < n; i++ {
+			_, err = c.PFAdd(k, "visitor:"+strconv.Itoa(i))
+			require.Nil(t, err)
+		}
+		count := c.PFCount(k)
+		require.InDelta(t, n, count, float64(n)*0.03, "n=%d count=%d", n, count)
+	}
+	require.Equal(t, int64(0), c.PFCount("test_pf_not_exist"))
+}
+
+func TestPFMerge(t *testing.T) {
+	k1, k2, dst := "test_pf_merge1", "test_pf_merge2", "test_pf_merge_dst"
+	for i := 0; i < 3000; i++ {
+		_, err := c.PFAdd(k1, i)
+		require.Nil(t, err)
+		_, err = c.PFAdd(k2, i+2000)
+		require.Nil(t, err)
+	}
+	union := c.PFCount(k1, k2, "test_pf_merge_none")
+	require.InDelta(t, 5000, union, 5000*0.03)
+
+	_, err := c.PFAdd(dst, "extra")
+	require.Nil(t, err)
+	c.PFMerge(dst, k1, k2)
+	require.InDelta(t, 5001, c.PFCount(dst), 5001*0.03)
+
+	c.PFMerge("test_pf_merge_new", "test_pf_merge_none")
+	require.True(t, c.Exists("test_pf_merge_new"))
+	require.Equal(t, int64(0), c.PFCount("test_pf_merge_new"))
+
+	require.Nil(t, c.Expiration(dst, time.Millisecond))
+	time.Sleep(time.Millisecond * 5)
+	require.False(t, c.Exists(dst))
+	require.Equal(t, int64(0), c.PFCount(dst))
+	c.Del(k1)
+	require.False(t, c.Exists(k1))
+}
+
+func TestXAddRange(t *testing.T) {
+	k := "test_stream"
+	for i := 1; i <= 5; i++ {
+		id, err := c.XAdd(k, &types.XAddArgs{ID: strconv.Itoa(i) + "-*", Values: map[string]any{"n": i}})
+		require.Nil(t, err)
+		require.Equal(t, strconv.Itoa(i)+"-0", id)
+	}
+	id, err := c.XAdd(k, &types.XAddArgs{ID: "5-*", Values: map[string]any{"n": 6}})
+	require.Nil(t, err)
+	require.Equal(t, "5-1", id)
+	_, err = c.XAdd(k, &types.XAddArgs{ID: "5-1", Values: map[string]any{"n": 7}})
+	require.Equal(t, types.ErrStreamID, err)
+	_, err = c.XAdd(k, &types.XAddArgs{})
+	require.Equal(t, types.ErrValues, err)
+	id, err = c.XAdd(k, &types.XAddArgs{Values: map[string]any{"n": 7}})
+	require.Nil(t, err)
+	require.NotEqual(t, "5-2", id)
+	require.Equal(t, 7, c.XLen(k))
+	require.True(t, c.Exists(k))
+
+	entries, err := c.XRange(k, "2", "5", 0)
+	require.Nil(t, err)
+	require.Len(t, entries, 5)
+	require.Equal(t, types.StreamEntry{ID: "2-0", Values: map[string]any{"n": 2}}, entries[0])
+	require.Equal(t, "5-1", entries[4].ID)
+	entries, err = c.XRange(k, "(2-0", "(5-1", 2)
+	require.Nil(t, err)
+	require.Equal(t, []string{"3-0", "4-0"}, streamIDs(entries))
+	entries, err = c.XRevRange(k, "+", "-", 3)
+	require.Nil(t, err)
+	require.Equal(t, []string{id, "5-1", "5-0"}, streamIDs(entries))
+	entries, err = c.XRange(k, "4", "3", 0)
+	require.Nil(t, err)
+	require.Empty(t, entries)
+	_, err = c.XRange(k, "a", "+", 0)
+	require.Equal(t, types.ErrStreamID, err)
+
+	// 返回的消息为副本，修改不影响流中的数据
+	entries, _ = c.XRange(k, "1", "1", 0)
+	entries[0].Values["n"] = 100
+	entries, _ = c.XRange(k, "1", "1", 0)
+	require.Equal(t, 1, entries[0].Values["n"])
+
+	_, err = c.XAdd("test_stream_none", &types.XAddArgs{NoMkStream: true, Values: map[string]any{"n": 1}})
+	require.Equal(t, types.ErrStreamKey, err)
+	require.False(t, c.Exists("test_stream_none"))
+
+	require.Nil(t, c.Expiration(k, time.Millisecond))
+	time.Sleep(time.Millisecond * 5)
+	require.False(t, c.Exists(k))
+	require.Equal(t, 0, c.XLen(k))
+}
+
+func TestXAddTrim(t *testing.T) {
+	k := "test_stream_trim"
+	for i := 1; i <= 1000; i++ {
+		_, err := c.XAdd(k, &types.XAddArgs{ID: strconv.Itoa(i), Values: map[string]any{"n": i}, MaxLen: 300})
+		require.Nil(t, err)
+	}
+	require.Equal(t, 300, c.XLen(k))
+	entries, err := c.XRange(k, "-", "+", 1)
+	require.Nil(t, err)
+	require.Equal(t, "701-0", entries[0].ID)
+
+	// 近似删除时只释放完整的分块，保留的消息不少于MaxLen
+	_, err = c.XAdd(k, &types.XAddArgs{ID: "1001", Values: map[string]any{"n": 1001}, MaxLen: 100, Approx: true})
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, c.XLen(k), 100)
+	require.Less(t, c.XLen(k), 301)
+
+	_, err = c.XAdd(k, &types.XAddArgs{ID: "1002", Values: map[string]any{"n": 1002}, MinID: "950"})
+	require.Nil(t, err)
+	require.Equal(t, 53, c.XLen(k))
+	entries, err = c.XRevRange(k, "+", "-", 0)
+	require.Nil(t, err)
+	require.Len(t, entries, 53)
+	require.Equal(t, "950-0", entries[52].ID)
+}
+
+func TestXRead(t *testing.T) {
+	k1, k2 := "test_xread1", "test_xread2"
+	_, err := c.XAdd(k1, &types.XAddArgs{ID: "1", Values: map[string]any{"a": 1}})
+	require.Nil(t, err)
+	_, err = c.XAdd(k1, &types.XAddArgs{ID: "2", Values: map[string]any{"a": 2}})
+	require.Nil(t, err)
+
+	streams, err := c.XRead(context.Background(), &types.XReadArgs{Keys: []string{k1, k2}, IDs: []string{"1", "0"}})
+	require.Nil(t, err)
+	require.Len(t, streams, 1)
+	require.Equal(t, k1, streams[0].Key)
+	require.Equal(t, []string{"2-0"}, streamIDs(streams[0].Entries))
+
+	streams, err = c.XRead(context.Background(), &types.XReadArgs{Keys: []string{k1}, IDs: []string{"$"}})
+	require.Nil(t, err)
+	require.Empty(t, streams)
+	_, err = c.XRead(context.Background(), &types.XReadArgs{Keys: []string{k1, k2}, IDs: []string{"$"}})
+	require.Equal(t, types.ErrStreamArgs, err)
+
+	_, err = c.XRead(context.Background(), &types.XReadArgs{Keys: []string{k1}, IDs: []string{"$"}, Block: true, Timeout: 10 * time.Millisecond})
+	require.Equal(t, types.ErrTimeout, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.XRead(ctx, &types.XReadArgs{Keys: []string{k1}, IDs: []string{"$"}, Block: true})
+	require.Equal(t, context.Canceled, err)
+
+	// 阻塞等待的调用方都会收到新消息
+	results := make(chan any, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			streams, err := c.XRead(context.Background(), &types.XReadArgs{Keys: []string{k1, k2}, IDs: []string{"$", "$"}, Block: true, Timeout: time.Second})
+			if err != nil {
+				results <- err
+				return
+			}
+			results <- streams[0].Key + "/" + streams[0].Entries[0].ID
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	_, err = c.XAdd(k2, &types.XAddArgs{ID: "5", Values: map[string]any{"b": 1}})
+	require.Nil(t, err)
+	require.Equal(t, k2+"/5-0", <-results)
+	require.Equal(t, k2+"/5-0", <-results)
+}
+
+func TestXReadGroup(t *testing.T) {
+	k, group := "test_xgroup", "workers"
+	require.Equal(t, types.ErrStreamKey, c.XGroupCreate(k, group, "$", false))
+	require.Nil(t, c.XGroupCreate(k, group, "$", true))
+	require.True(t, c.Exists(k))
+	require.Equal(t, types.ErrGroupExists, c.XGroupCreate(k, group, "0", false))
+	for i := 1; i <= 4; i++ {
+		_, err := c.XAdd(k, &types.XAddArgs{ID: strconv.Itoa(i), Values: map[string]any{"n": i}})
+		require.Nil(t, err)
+	}
+
+	read := func(consumer, id string, count int) []string {
+		streams, err := c.XReadGroup(context.Background(), &types.XReadGroupArgs{
+			Group: group, Consumer: consumer, Keys: []string{k}, IDs: []string{id}, Count: count,
+		})
+		require.Nil(t, err)
+		if len(streams) == 0 {
+			return nil
+		}
+		return streamIDs(streams[0].Entries)
+	}
+	require.Equal(t, []string{"1-0", "2-0", "3-0"}, read("alice", ">", 3))
+	require.Equal(t, []string{"4-0"}, read("bob", ">", 0))
+	require.Nil(t, read("bob", ">", 0))
+	// 读取待确认的消息
+	require.Equal(t, []string{"2-0", "3-0"}, read("alice", "1", 0))
+
+	n, err := c.XAck(k, group, "1", "1", "9")
+	require.Nil(t, err)
+	require.Equal(t, 1, n)
+	summary, err := c.XPending(k, group)
+	require.Nil(t, err)
+	require.Equal(t, &types.XPendingSummary{
+		Count: 3, Lower: "2-0", Higher: "4-0", Consumers: map[string]int{"alice": 2, "bob": 1},
+	}, summary)
+
+	pending, err := c.XPendingExt(k, &types.XPendingArgs{Group: group, Start: "-", End: "+", Consumer: "alice"})
+	require.Nil(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, "2-0", pending[0].ID)
+	require.Equal(t, 1, pending[0].RetryCount)
+
+	// 空闲时间不足时不会转移
+	entries, err := c.XClaim(k, &types.XClaimArgs{Group: group, Consumer: "bob", MinIdle: time.Hour, IDs: []string{"2"}})
+	require.Nil(t, err)
+	require.Empty(t, entries)
+	entries, err = c.XClaim(k, &types.XClaimArgs{Group: group, Consumer: "bob", IDs: []string{"2"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{"2-0"}, streamIDs(entries))
+	require.Equal(t, []string{"2-0", "4-0"}, read("bob", "0", 0))
+
+	entries, next, err := c.XAutoClaim(k, &types.XAutoClaimArgs{Group: group, Consumer: "carol", Start: "0", Count: 2})
+	require.Nil(t, err)
+	require.Equal(t, []string{"2-0", "3-0"}, streamIDs(entries))
+	require.Equal(t, "4-0", next)
+	entries, next, err = c.XAutoClaim(k, &types.XAutoClaimArgs{Group: group, Consumer: "carol", Start: next})
+	require.Nil(t, err)
+	require.Equal(t, []string{"4-0"}, streamIDs(entries))
+	require.Equal(t, "0-0", next)
+	pending, err = c.XPendingExt(k, &types.XPendingArgs{Group: group, Start: "-", End: "+", Count: 1})
+	require.Nil(t, err)
+	require.Equal(t, "carol", pending[0].Consumer)
+	require.Equal(t, 3, pending[0].RetryCount)
+
+	// 阻塞等待新消息
+	done := make(chan []types.XStream)
+	go func() {
+		streams, _ := c.XReadGroup(context.Background(), &types.XReadGroupArgs{
+			Group: group, Consumer: "dave", Keys: []string{k}, IDs: []string{">"}, NoAck: true, Block: true,
+		})
+		done <- streams
+	}()
+	time.Sleep(20 * time.Millisecond)
+	_, err = c.XAdd(k, &types.XAddArgs{ID: "5", Values: map[string]any{"n": 5}})
+	require.Nil(t, err)
+	streams := <-done
+	require.Equal(t, []string{"5-0"}, streamIDs(streams[0].Entries))
+	summary, err = c.XPending(k, group)
+	require.Nil(t, err)
+	require.Equal(t, 3, summary.Count)
+
+	_, err = c.XReadGroup(context.Background(), &types.XReadGroupArgs{
+		Group: "none", Consumer: "alice", Keys: []string{k}, IDs: []string{">"},
+	})
+	require.Equal(t, types.ErrNoGroup, err)
+}
+
+// streamIDs 获取消息的ID列表
+func streamIDs(entries []types.StreamEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestGeoAddPosDist(t *testing.T) {
+	k := "test_geo_sicily"
+	n, err := c.GeoAdd(k,
+		types.GeoLocation{Member: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		types.GeoLocation{Member: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+	)
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	require.True(t, c.Exists(k))
+	n, err = c.GeoAdd(k, types.GeoLocation{Member: "Palermo", Longitude: 13.361389, Latitude: 38.115556})
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	_, err = c.GeoAdd(k, types.GeoLocation{Member: "pole", Longitude: 0, Latitude: 89})
+	require.Equal(t, types.ErrCoordinate, err)
+	require.Equal(t, 2, c.ZCard(k))
+
+	positions := c.GeoPos(k, "Palermo", "none")
+	require.Nil(t, positions[1])
+	require.InDelta(t, 13.361389338970184, positions[0].Longitude, 1e-9)
+	require.InDelta(t, 38.1155563954963, positions[0].Latitude, 1e-9)
+
+	dist, err := c.GeoDist(k, "Palermo", "Catania", "")
+	require.Nil(t, err)
+	require.InDelta(t, 166274.1516, dist, 1e-3)
+	dist, err = c.GeoDist(k, "Palermo", "Catania", types.GeoUnitKM)
+	require.Nil(t, err)
+	require.InDelta(t, 166.2742, dist, 1e-4)
+	dist, err = c.GeoDist(k, "Palermo", "Catania", types.GeoUnitMI)
+	require.Nil(t, err)
+	require.InDelta(t, 103.3182, dist, 1e-4)
+	_, err = c.GeoDist(k, "Palermo", "none", types.GeoUnitKM)
+	require.Equal(t, types.ErrGeoMember, err)
+	_, err = c.GeoDist(k, "Palermo", "Catania", "cm")
+	require.Equal(t, types.ErrGeoUnit, err)
+
+	require.Equal(t, []string{"sqc8b49rny0", "sqdtr74hyu0", ""}, c.GeoHash(k, "Palermo", "Catania", "none"))
+}
+
+func TestGeoSearch(t *testing.T) {
+	k := "test_geo_search"
+	_, err := c.GeoAdd(k,
+		types.GeoLocation{Member: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		types.GeoLocation{Member: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+		types.GeoLocation{Member: "edge1", Longitude: 12.758489, Latitude: 38.788135},
+		types.GeoLocation{Member: "edge2", Longitude: 17.241510, Latitude: 38.788135},
+	)
+	require.Nil(t, err)
+
+	members := func(results []types.GeoSearchResult) []string {
+		names := make([]string, len(results))
+		for i, r := range results {
+			names[i] = r.Member
+		}
+		return names
+	}
+	results, err := c.GeoSearch(k, &types.GeoSearchArgs{Longitude: 15, Latitude: 37, Radius: 200, Unit: types.GeoUnitKM, Sort: types.GeoSortAsc})
+	require.Nil(t, err)
+	require.Equal(t, []string{"Catania", "Palermo"}, members(results))
+	require.Zero(t, results[0].Dist)
+
+	results, err = c.GeoSearch(k, &types.GeoSearchArgs{
+		Longitude: 15, Latitude: 37, Width: 400, Height: 400, Unit: types.GeoUnitKM,
+		Sort: types.GeoSortAsc, WithDist: true, WithCoord: true,
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"Catania", "Palermo", "edge2", "edge1"}, members(results))
+	for i, dist := range []float64{56.4413, 190.4424, 279.7403, 279.7405} {
+		require.InDelta(t, dist, results[i].Dist, 1e-4)
+	}
+	require.InDelta(t, 17.241510, results[2].Longitude, 1e-5)
+	require.InDelta(t, 38.788135, results[2].Latitude, 1e-5)
+
+	// 指定Count未指定排序时返回最近的成员
+	results, err = c.GeoSearch(k, &types.GeoSearchArgs{Member: "Palermo", Radius: 300, Unit: types.GeoUnitKM, Count: 2, WithDist: true})
+	require.Nil(t, err)
+	require.Equal(t, []string{"Palermo", "edge1"}, members(results))
+	results, err = c.GeoSearch(k, &types.GeoSearchArgs{Member: "Palermo", Radius: 400, Unit: types.GeoUnitKM, Sort: types.GeoSortDesc})
+	require.Nil(t, err)
+	require.Equal(t, []string{"edge2", "Catania", "edge1", "Palermo"}, members(results))
+	results, err = c.GeoSearch(k, &types.GeoSearchArgs{Member: "Palermo", Radius: 300, Unit: types.GeoUnitKM, Count: 1, Any: true})
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+
+	_, err = c.GeoSearch(k, &types.GeoSearchArgs{Member: "none", Radius: 1})
+	require.Equal(t, types.ErrGeoMember, err)
+	_, err = c.GeoSearch(k, &types.GeoSearchArgs{Longitude: 15, Latitude: 37})
+	require.Equal(t, types.ErrGeoArgs, err)
+	results, err = c.GeoSearch("test_geo_none", &types.GeoSearchArgs{Longitude: 15, Latitude: 37, Radius: 1})
+	require.Nil(t, err)
+	require.Empty(t, results)
+
+	dst := "test_geo_store"
+	n, err := c.GeoSearchStore(dst, k, &types.GeoSearchArgs{Longitude: 15, Latitude: 37, Radius: 200, Unit: types.GeoUnitKM}, false)
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	dist, err := c.GeoDist(dst, "Palermo", "Catania", types.GeoUnitKM)
+	require.Nil(t, err)
+	require.InDelta(t, 166.2742, dist, 1e-4)
+	n, err = c.GeoSearchStore(dst, k, &types.GeoSearchArgs{Longitude: 15, Latitude: 37, Radius: 200, Unit: types.GeoUnitKM}, true)
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	_, score := c.ZRankWithScore(dst, "Catania")
+	require.InDelta(t, 56.4413, score, 1e-4)
+	n, err = c.GeoSearchStore(dst, k, &types.GeoSearchArgs{Longitude: 0, Latitude: 0, Radius: 1}, false)
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	require.False(t, c.Exists(dst))
+}
+
+func TestGeoSearchMatchesScan(t *testing.T) {
+	k := "test_geo_random"
+	r := rand.New(rand.NewSource(1))
+	locations := make([]types.GeoLocation, 2000)
+	for i := range locations {
+		// 包含跨越180度经线和靠近两极的位置
+		locations[i] = types.GeoLocation{
+			Member:    "courier" + strconv.Itoa(i),
+			Longitude: r.Float64()*360 - 180,
+			Latitude:  r.Float64()*170 - 85,
+		}
+	}
+	_, err := c.GeoAdd(k, locations...)
+	require.Nil(t, err)
+	names := make([]string, len(locations))
+	for i, l := range locations {
+		names[i] = l.Member
+	}
+	positions := c.GeoPos(k, names...)
+
+	for i := 0; i < 200; i++ {
+		args := &types.GeoSearchArgs{
+			Longitude: r.Float64()*360 - 180,
+			Latitude:  r.Float64()*170 - 85,
+			Unit:      types.GeoUnitKM,
+		}
+		if i%2 == 0 {
+			args.Radius = r.Float64() * 3000
+		} else {
+			args.Width, args.Height = r.Float64()*6000, r.Float64()*6000
+		}
+		results, err := c.GeoSearch(k, args)
+		require.Nil(t, err)
+		found := make(map[string]bool, len(results))
+		for _, result := range results {
+			found[result.Member] = true
+		}
+		// 与逐个计算距离的结果一致
+		expected := 0
+		for j, l := range locations {
+			pos := positions[j]
+			in := false
+			if args.Radius > 0 {
+				in = geoTestDistance(args.Longitude, args.Latitude, pos.Longitude, pos.Latitude) <= args.Radius*1000
+			} else {
+				in = geoTestDistance(args.Longitude, pos.Latitude, pos.Longitude, pos.Latitude) <= args.Width*500 &&
+					6372797.560856*math.Abs(pos.Latitude-args.Latitude)*math.Pi/180 <= args.Height*500
+			}
+			if in {
+				expected++
+				require.True(t, found[l.Member], "search %+v missing %+v", args, pos)
+			}
+		}
+		require.Equal(t, expected, len(results))
+	}
+}
+
+// geoTestDistance 使用haversine公式计算距离，单位为米
+func geoTestDistance(lon1, lat1, lon2, lat2 float64) float64 {
+	rad := math.Pi / 180
+	u := math.Sin((lat2 - lat1) * rad / 2)
+	v := math.Sin((lon2 - lon1) * rad / 2)
+	return 2 * 6372797.560856 * math.Asin(math.Sqrt(u*u+math.Cos(lat1*rad)*math.Cos(lat2*rad)*v*v))
+}
+
+func TestBloomFilter(t *testing.T) {
+	k := "test_bloom"
+	require.Nil(t, c.BFReserve(k, 0.01, 1000))
+	require.Equal(t, types.ErrFilterExists, c.BFReserve(k, 0.01, 1000))
+	require.Equal(t, types.ErrFilterArgs, c.BFReserve("test_bloom_args", 1, 1000))
+	require.True(t, c.Exists(k))
+
+	for i := 0; i < 10000; i++ {
+		_, err := c.BFAdd(k, "msg"+strconv.Itoa(i))
+		require.Nil(t, err)
+	}
+	added, err := c.BFAdd(k, "msg1")
+	require.Nil(t, err)
+	require.False(t, added)
+	results, err := c.BFMExists(k, "msg0", "msg9999", 42)
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, true}, results[:2])
+
+	// 扩容后误判率仍不超过设定值
+	var falsePositives int
+	for i := 0; i < 10000; i++ {
+		exist, err := c.BFExists(k, "other"+strconv.Itoa(i))
+		require.Nil(t, err)
+		if exist {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 100)
+
+	info, err := c.BFInfo(k)
+	require.Nil(t, err)
+	require.Equal(t, 2, info.Expansion)
+	require.Equal(t, 4, info.Filters)
+	require.Equal(t, int64(15000), info.Capacity)
+	require.InDelta(t, 10000, info.Items, 100)
+
+	n := "test_bloom_nonscaling"
+	require.Nil(t, c.BFReserveArgs(n, types.BFReserveArgs{ErrorRate: 0.001, Capacity: 10, NonScaling: true}))
+	_, err = c.BFMAdd(n, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	require.Nil(t, err)
+	_, err = c.BFAdd(n, 10)
+	require.Equal(t, types.ErrFilterFull, err)
+	results, err = c.BFMAdd(n, 1, 2)
+	require.Nil(t, err)
+	require.Equal(t, []bool{false, false}, results)
+
+	// 自动创建，支持过期和删除
+	a := "test_bloom_auto"
+	added, err = c.BFAdd(a, "x")
+	require.Nil(t, err)
+	require.True(t, added)
+	require.Nil(t, c.Expiration(a, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	require.False(t, c.Exists(a))
+	_, err = c.BFInfo(a)
+	require.Equal(t, types.ErrFilterKey, err)
+	c.Del(k)
+	exist, err := c.BFExists(k, "msg1")
+	require.Nil(t, err)
+	require.False(t, exist)
+}
+
+func TestCuckooFilter(t *testing.T) {
+	k := "test_cuckoo"
+	require.Nil(t, c.CFReserve(k, 1000))
+	require.Equal(t, types.ErrFilterExists, c.CFReserve(k, 1000))
+	require.Equal(t, types.ErrFilterArgs, c.CFReserveArgs("test_cuckoo_args", types.CFReserveArgs{Capacity: 10, BucketSize: 256}))
+
+	for i := 0; i < 5000; i++ {
+		require.Nil(t, c.CFAdd(k, "msg"+strconv.Itoa(i)))
+	}
+	// 空间不足时自动扩容，已添加的元素都能找到
+	for i := 0; i < 5000; i++ {
+		exist, err := c.CFExists(k, "msg"+strconv.Itoa(i))
+		require.Nil(t, err)
+		require.True(t, exist, i)
+	}
+	info, err := c.CFInfo(k)
+	require.Nil(t, err)
+	require.Greater(t, info.Filters, 1)
+	require.Equal(t, int64(5000), info.Items)
+
+	added, err := c.CFAddNX(k, "msg1")
+	require.Nil(t, err)
+	require.False(t, added)
+	require.Nil(t, c.CFAdd(k, "msg1"))
+	count, err := c.CFCount(k, "msg1")
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, count, 2)
+
+	// 删除后不再存在
+	for i := 0; i < 5000; i++ {
+		deleted, err := c.CFDel(k, "msg"+strconv.Itoa(i))
+		require.Nil(t, err)
+		require.True(t, deleted)
+	}
+	deleted, err := c.CFDel(k, "msg1")
+	require.Nil(t, err)
+	require.True(t, deleted)
+	results, err := c.CFMExists(k, "msg1", "msg2")
+	require.Nil(t, err)
+	require.Equal(t, []bool{false, false}, results)
+	info, err = c.CFInfo(k)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), info.Items)
+	require.Equal(t, int64(5001), info.Deleted)
+
+	a := "test_cuckoo_auto"
+	added, err = c.CFAddNX(a, "x")
+	require.Nil(t, err)
+	require.True(t, added)
+	require.True(t, c.Exists(a))
+	c.Del(a)
+	require.False(t, c.Exists(a))
+	_, err = c.CFDel(a, "x")
+	require.Equal(t, types.ErrFilterKey, err)
+}
+
+func TestCountMinSketch(t *testing.T) {
+	k1, k2, dst := "test_cms1", "test_cms2", "test_cms_dst"
+	require.Nil(t, c.CMSInitByProb(k1, 0.001, 0.01))
+	require.Equal(t, types.ErrSketchExists, c.CMSInitByDim(k1, 10, 10))
+	require.Equal(t, types.ErrSketchArgs, c.CMSInitByProb(k2, 0, 0.01))
+	info, err := c.CMSInfo(k1)
+	require.Nil(t, err)
+	require.Equal(t, &types.CMSInfo{Width: 2000, Depth: 7}, info)
+	require.True(t, c.Exists(k1))
+
+	for i := 0; i < 1000; i++ {
+		_, err = c.CMSIncrBy(k1, "endpoint"+strconv.Itoa(i%100), 1)
+		require.Nil(t, err)
+	}
+	n, err := c.CMSIncrBy(k1, "/api/hot", 500)
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, n, int64(500))
+	counts, err := c.CMSQuery(k1, "/api/hot", "endpoint1", "none")
+	require.Nil(t, err)
+	require.InDelta(t, 500, counts[0], 2)
+	require.InDelta(t, 10, counts[1], 2)
+	require.InDelta(t, 0, counts[2], 2)
+	_, err = c.CMSIncrBy("test_cms_none", "a", 1)
+	require.Equal(t, types.ErrSketchKey, err)
+
+	require.Nil(t, c.CMSInitByDim(k2, 2000, 7))
+	_, err = c.CMSIncrBy(k2, "/api/hot", 100)
+	require.Nil(t, err)
+	require.Nil(t, c.CMSInitByDim(dst, 2000, 7))
+	require.Nil(t, c.CMSMerge(dst, []string{k1, k2}, []int64{1, 2}))
+	counts, err = c.CMSQuery(dst, "/api/hot")
+	require.Nil(t, err)
+	require.InDelta(t, 700, counts[0], 2)
+	info, err = c.CMSInfo(dst)
+	require.Nil(t, err)
+	require.Equal(t, int64(1700), info.Count)
+	require.Nil(t, c.CMSInitByDim("test_cms_small", 10, 7))
+	require.Equal(t, types.ErrSketchDims, c.CMSMerge(dst, []string{k1, "test_cms_small"}, nil))
+	require.Equal(t, types.ErrWeights, c.CMSMerge(dst, []string{k1, k2}, []int64{1}))
+
+	require.Nil(t, c.Expiration(k1, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	require.False(t, c.Exists(k1))
+	_, err = c.CMSQuery(k1, "/api/hot")
+	require.Equal(t, types.ErrSketchKey, err)
+}
+
+func TestTopK(t *testing.T) {
+	k := "test_topk"
+	require.Nil(t, c.TopKReserveArgs(k, types.TopKReserveArgs{TopK: 5, Width: 100, Depth: 5}))
+	require.Equal(t, types.ErrSketchExists, c.TopKReserve(k, 5))
+	require.Equal(t, types.ErrSketchArgs, c.TopKReserve("test_topk_args", 0))
+	require.True(t, c.Exists(k))
+
+	// key0 ~ key4 为热点，其余为长尾
+	for round := 0; round < 100; round++ {
+		for i := 0; i < 5; i++ {
+			_, err := c.TopKAdd(k, "key"+strconv.Itoa(i))
+			require.Nil(t, err)
+		}
+		for i := 0; i < 50; i++ {
+			_, err := c.TopKAdd(k, "tail"+strconv.Itoa(round*50+i))
+			require.Nil(t, err)
+		}
+	}
+	_, err := c.TopKIncrBy(k, "key0", 100)
+	require.Nil(t, err)
+
+	list, err := c.TopKList(k)
+	require.Nil(t, err)
+	require.Len(t, list, 5)
+	require.Equal(t, "key0", list[0].Item)
+	require.InDelta(t, 200, list[0].Count, 20)
+	hot := make([]any, 5)
+	for i := range hot {
+		hot[i] = "key" + strconv.Itoa(i)
+	}
+	results, err := c.TopKQuery(k, append(hot, "tail1")...)
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, true, true, true, true, false}, results)
+	counts, err := c.TopKCount(k, "key1", "none")
+	require.Nil(t, err)
+	require.InDelta(t, 100, counts[0], 20)
+	require.Equal(t, int64(0), counts[1])
+
+	// 新的热点挤出计数最小的元素
+	expelled, err := c.TopKIncrBy(k, "new_hot", 1000)
+	require.Nil(t, err)
+	require.Contains(t, []string{"key1", "key2", "key3", "key4"}, expelled)
+	results, err = c.TopKQuery(k, "new_hot", expelled)
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, false}, results)
+
+	_, err = c.TopKAdd("test_topk_none", "a")
+	require.Equal(t, types.ErrSketchKey, err)
+	c.Del(k)
+	require.False(t, c.Exists(k))
+	_, err = c.TopKList(k)
+	require.Equal(t, types.ErrSketchKey, err)
+}
+
+func TestJSONSetGet(t *testing.T) {
+	k := "test_json"
+	_, err := c.JSONSet(k, "$.a", 1, "")
+	require.Equal(t, types.ErrJSONKey, err)
+	set, err := c.JSONSet(k, "$", []byte(`{"name":"go","tags":["a","b"],"n":1,"nested":{"n":2.5,"ok":true}}`), "")
+	require.Nil(t, err)
+	require.True(t, set)
+	require.True(t, c.Exists(k))
+
+	data, err := c.JSONGet(k)
+	require.Nil(t, err)
+	require.Equal(t, `{"name":"go","tags":["a","b"],"n":1,"nested":{"n":2.5,"ok":true}}`, string(data))
+	data, err = c.JSONGet(k, "$..n")
+	require.Nil(t, err)
+	require.Equal(t, `[1,2.5]`, string(data))
+	data, err = c.JSONGet(k, ".tags[-1]")
+	require.Nil(t, err)
+	require.Equal(t, `"b"`, string(data))
+	data, err = c.JSONGet(k, "$.name", "$['nested'].ok")
+	require.Nil(t, err)
+	require.Equal(t, `{"$.name":["go"],"$['nested'].ok":[true]}`, string(data))
+	_, err = c.JSONGet(k, ".none")
+	require.Equal(t, types.ErrJSONPath, err)
+	data, err = c.JSONGet(k, "$.none")
+	require.Nil(t, err)
+	require.Equal(t, `[]`, string(data))
+	_, err = c.JSONGet(k, "$.tags[?(@>1)]")
+	require.Equal(t, types.ErrJSONPath, err)
+
+	// NX、XX和添加字段
+	set, err = c.JSONSet(k, "$.name", "cache", types.SetModeNX)
+	require.Nil(t, err)
+	require.False(t, set)
+	set, err = c.JSONSet(k, "$.version", 2, types.SetModeXX)
+	require.Nil(t, err)
+	require.False(t, set)
+	set, err = c.JSONSet(k, "$.version", 2, types.SetModeNX)
+	require.Nil(t, err)
+	require.True(t, set)
+	set, err = c.JSONSet(k, "$.nested.n", map[string]any{"x": nil}, "")
+	require.Nil(t, err)
+	require.True(t, set)
+	set, err = c.JSONSet(k, "$.a.b", 1, "")
+	require.Nil(t, err)
+	require.False(t, set)
+	data, err = c.JSONGet(k)
+	require.Nil(t, err)
+	require.Equal(t, `{"name":"go","tags":["a","b"],"n":1,"nested":{"n":{"x":null},"ok":true},"version":2}`, string(data))
+
+	keys, err := c.JSONObjKeys(k, "$..nested")
+	require.Nil(t, err)
+	require.Equal(t, [][]string{{"n", "ok"}}, keys)
+	kinds, err := c.JSONType(k, "$.*")
+	require.Nil(t, err)
+	require.Equal(t, []string{"string", "array", "integer", "object", "integer"}, kinds)
+
+	_, err = c.JSONSet(k, "$", []byte(`{"a":`), "")
+	require.Equal(t, types.ErrJSONValue, err)
+	_, err = c.JSONGet("test_json_none")
+	require.Equal(t, types.ErrJSONKey, err)
+
+	// 覆盖其他类型的key
+	c.Set("test_json_string", "v")
+	_, err = c.JSONSet("test_json_string", "$", struct {
+		ID int `json:"id"`
+	}{ID: 7}, "")
+	require.Nil(t, err)
+	data, err = c.JSONGet("test_json_string")
+	require.Nil(t, err)
+	require.Equal(t, `{"id":7}`, string(data))
+	_, err = c.Get("test_json_string")
+	require.Equal(t, types.ErrKeyNotExist, err)
+	c.Del(k)
+	c.Del("test_json_string")
+}
+
+func TestJSONUpdate(t *testing.T) {
+	k := "test_json_update"
+	_, err := c.JSONSet(k, "$", []byte(`{"a":[1,2,3],"b":{"a":[]},"c":"x","i":9223372036854775807,"f":1.5}`), "")
+	require.Nil(t, err)
+
+	lengths, err := c.JSONArrAppend(k, "$..a", 4, "five")
+	require.Nil(t, err)
+	require.Len(t, lengths, 2)
+	require.Equal(t, 5, *lengths[0])
+	require.Equal(t, 2, *lengths[1])
+	lengths, err = c.JSONArrAppend(k, "$.c", 1)
+	require.Nil(t, err)
+	require.Nil(t, lengths[0])
+
+	popped, err := c.JSONArrPop(k, "$.a", -1)
+	require.Nil(t, err)
+	require.Equal(t, [][]byte{[]byte(`"five"`)}, popped)
+	popped, err = c.JSONArrPop(k, "$.a", 100)
+	require.Nil(t, err)
+	require.Equal(t, [][]byte{[]byte(`4`)}, popped)
+	popped, err = c.JSONArrPop(k, "$.c", 0)
+	require.Nil(t, err)
+	require.Nil(t, popped[0])
+
+	nums, err := c.JSONNumIncrBy(k, "$.a[0:2]", 2)
+	require.Nil(t, err)
+	require.Equal(t, []json.Number{"3", "4"}, nums)
+	nums, err = c.JSONNumIncrBy(k, "$.f", 1.5)
+	require.Nil(t, err)
+	require.Equal(t, []json.Number{"3.0"}, nums)
+	nums, err = c.JSONNumIncrBy(k, "$['c','f']", 0.25)
+	require.Nil(t, err)
+	require.Equal(t, []json.Number{"", "3.25"}, nums)
+	// 整数溢出时转为浮点数
+	nums, err = c.JSONNumIncrBy(k, "$.i", 1)
+	require.Nil(t, err)
+	require.Equal(t, []json.Number{"9.223372036854776e+18"}, nums)
+	kinds, err := c.JSONType(k, "$.i")
+	require.Nil(t, err)
+	require.Equal(t, []string{"number"}, kinds)
+
+	n, err := c.JSONDel(k, "$.a[0,0,2]")
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	n, err = c.JSONDel(k, "$..a")
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	data, err := c.JSONGet(k, "$.a", "$.b")
+	require.Nil(t, err)
+	require.Equal(t, `{"$.a":[],"$.b":[{}]}`, string(data))
+
+	n, err = c.JSONDel(k, "$")
+	require.Nil(t, err)
+	require.Equal(t, 1, n)
+	require.False(t, c.Exists(k))
+	_, err = c.JSONArrAppend(k, "$", 1)
+	require.Equal(t, types.ErrJSONKey, err)
+}
+
+func TestTimeSeries(t *testing.T) {
+	k := "test_ts"
+	require.Nil(t, c.TSCreateArgs(k, types.TSCreateArgs{Retention: 10 * time.Second}))
+	require.Equal(t, types.ErrTSExists, c.TSCreate(k))
+	require.True(t, c.Exists(k))
+
+	for i := int64(1); i <= 10; i++ {
+		_, err := c.TSAdd(k, i*1000, float64(i))
+		require.Nil(t, err)
+	}
+	// 乱序写入和重复时间戳
+	_, err := c.TSAdd(k, 500, 0.5)
+	require.Nil(t, err)
+	_, err = c.TSAdd(k, 1000, 100)
+	require.Equal(t, types.ErrTSDuplicate, err)
+	_, err = c.TSAddArgs(k, 1000, 2, types.TSAddArgs{OnDuplicate: types.TSDuplicateSum})
+	require.Nil(t, err)
+
+	samples, err := c.TSRange(k, 0, 2000, nil)
+	require.Nil(t, err)
+	require.Equal(t, []types.TSSample{{Timestamp: 500, Value: 0.5}, {Timestamp: 1000, Value: 3}, {Timestamp: 2000, Value: 2}}, samples)
+	samples, err = c.TSRevRange(k, 0, math.MaxInt64, &types.TSRangeArgs{Count: 2})
+	require.Nil(t, err)
+	require.Equal(t, []types.TSSample{{Timestamp: 10000, Value: 10}, {Timestamp: 9000, Value: 9}}, samples)
+
+	// 时间桶[0, 4000)中的样本为0.5、3、2、3
+	aggregations := map[types.TSAggregation][]float64{
+		types.TSAggAvg:   {2.125, 5.5, 9},
+		types.TSAggSum:   {8.5, 22, 27},
+		types.TSAggMin:   {0.5, 4, 8},
+		types.TSAggMax:   {3, 7, 10},
+		types.TSAggCount: {4, 4, 3},
+		types.TSAggFirst: {0.5, 4, 8},
+		types.TSAggLast:  {3, 7, 10},
+	}
+	for agg, want := range aggregations {
+		samples, err = c.TSRange(k, 0, math.MaxInt64, &types.TSRangeArgs{Aggregation: agg, Bucket: 4 * time.Second})
+		require.Nil(t, err)
+		require.Len(t, samples, 3)
+		for i, sample := range samples {
+			require.Equal(t, int64(i*4000), sample.Timestamp)
+			require.Equal(t, want[i], sample.Value, agg)
+		}
+	}
+	samples, err = c.TSRevRange(k, 0, math.MaxInt64, &types.TSRangeArgs{Aggregation: types.TSAggCount, Bucket: 4 * time.Second, Count: 1})
+	require.Nil(t, err)
+	require.Equal(t, []types.TSSample{{Timestamp: 8000, Value: 3}}, samples)
+	_, err = c.TSRange(k, 0, 1, &types.TSRangeArgs{Aggregation: "median", Bucket: time.Second})
+	require.Equal(t, types.ErrTSArgs, err)
+
+	// 超出保留时长的样本被忽略，且不能再写入
+	_, err = c.TSAdd(k, 15000, 15)
+	require.Nil(t, err)
+	samples, err = c.TSRange(k, 0, 6000, nil)
+	require.Nil(t, err)
+	require.Equal(t, []types.TSSample{{Timestamp: 5000, Value: 5}, {Timestamp: 6000, Value: 6}}, samples)
+	_, err = c.TSAdd(k, 4000, 4)
+	require.Equal(t, types.ErrTSRetention, err)
+	sample, err := c.TSGet(k)
+	require.Nil(t, err)
+	require.Equal(t, &types.TSSample{Timestamp: 15000, Value: 15}, sample)
+
+	_, err = c.TSRange("test_ts_none", 0, 1, nil)
+	require.Equal(t, types.ErrTSKey, err)
+	c.Del(k)
+}
+
+func TestTimeSeriesRule(t *testing.T) {
+	src, dst := "test_ts_src", "test_ts_dst"
+	require.Nil(t, c.TSCreate(src))
+	require.Nil(t, c.TSCreate(dst))
+	require.Equal(t, types.ErrTSKey, c.TSCreateRule(src, "test_ts_none", types.TSAggAvg, time.Second))
+	require.Nil(t, c.TSCreateRule(src, dst, types.TSAggSum, time.Second))
+	require.Equal(t, types.ErrTSRule, c.TSCreateRule(src, dst, types.TSAggSum, time.Second))
+	require.Equal(t, types.ErrTSRule, c.TSCreateRule(dst, src, types.TSAggSum, time.Second))
+
+	for _, ts := range []int64{1000, 1500, 2000, 2999, 3000} {
+		_, err := c.TSAdd(src, ts, 1)
+		require.Nil(t, err)
+	}
+	// 时间桶结束后才写入dst
+	samples, err := c.TSRange(dst, 0, math.MaxInt64, nil)
+	require.Nil(t, err)
+	require.Equal(t, []types.TSSample{{Timestamp: 1000, Value: 2}, {Timestamp: 2000, Value: 2}}, samples)
+	// 已结束的时间桶中有新样本时重新聚合
+	_, err = c.TSAdd(src, 1200, 5)
+	require.Nil(t, err)
+	samples, err = c.TSRange(dst, 0, math.MaxInt64, nil)
+	require.Nil(t, err)
+	require.Equal(t, []types.TSSample{{Timestamp: 1000, Value: 7}, {Timestamp: 2000, Value: 2}}, samples)
+
+	require.Nil(t, c.TSDeleteRule(src, dst))
+	require.Equal(t, types.ErrTSRule, c.TSDeleteRule(src, dst))
+	_, err = c.TSAdd(src, 5000, 1)
+	require.Nil(t, err)
+	samples, err = c.TSRange(dst, 0, math.MaxInt64, nil)
+	require.Nil(t, err)
+	require.Len(t, samples, 2)
+
+	// 删除dst后可以重新创建规则
+	require.Nil(t, c.TSCreateRule(src, dst, types.TSAggMax, time.Second))
+	c.Del(dst)
+	require.Nil(t, c.TSCreate(dst))
+	require.Nil(t, c.TSCreateRule(src, dst, types.TSAggMax, time.Second))
+	c.Del(src)
+	c.Del(dst)
+}
+
+func TestTimeSeriesMRange(t *testing.T) {
+	hosts := []struct {
+		key    string
+		labels map[string]string
+	}{
+		{"test_ts_cpu_a", map[string]string{"metric": "cpu", "host": "a"}},
+		{"test_ts_cpu_b", map[string]string{"metric": "cpu", "host": "b", "env": "dev"}},
+		{"test_ts_mem_a", map[string]string{"metric": "mem", "host": "a"}},
+	}
+	for i, h := range hosts {
+		_, err := c.TSAddArgs(h.key, 1000, float64(i), types.TSAddArgs{TSCreateArgs: types.TSCreateArgs{Labels: h.labels}})
+		require.Nil(t, err)
+	}
+	timestamps, errs := c.TSMAdd(
+		types.TSKeySample{Key: "test_ts_cpu_a", Timestamp: 2000, Value: 10},
+		types.TSKeySample{Key: "test_ts_none", Timestamp: 2000, Value: 10},
+	)
+	require.Equal(t, int64(2000), timestamps[0])
+	require.Equal(t, []error{nil, types.ErrTSKey}, errs)
+
+	keys := func(series []types.TSSeries) []string {
+		var keys []string
+		for _, s := range series {
+			keys = append(keys, s.Key)
+		}
+		return keys
+	}
+	series, err := c.TSMRange(0, math.MaxInt64, []string{"metric=cpu"}, nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"test_ts_cpu_a", "test_ts_cpu_b"}, keys(series))
+	require.Equal(t, []types.TSSample{{Timestamp: 1000, Value: 0}, {Timestamp: 2000, Value: 10}}, series[0].Samples)
+	require.Equal(t, "a", series[0].Labels["host"])
+	series, err = c.TSMRange(0, math.MaxInt64, []string{"host=(a,b)", "env="}, nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"test_ts_cpu_a", "test_ts_mem_a"}, keys(series))
+	series, err = c.TSMRange(0, math.MaxInt64, []string{"metric=cpu", "env!="}, nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"test_ts_cpu_b"}, keys(series))
+	series, err = c.TSMRevRange(0, math.MaxInt64, []string{"host=a", "metric!=mem"}, &types.TSRangeArgs{Count: 1})
+	require.Nil(t, err)
+	require.Equal(t, []string{"test_ts_cpu_a"}, keys(series))
+	require.Equal(t, []types.TSSample{{Timestamp: 2000, Value: 10}}, series[0].Samples)
+	_, err = c.TSMRange(0, math.MaxInt64, []string{"metric!=cpu"}, nil)
+	require.Equal(t, types.ErrTSFilter, err)
+	for _, h := range hosts {
+		c.Del(h.key)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	k := "test_rate_limit"
+	for i := 0; i < 3; i++ {
+		res, err := c.RateLimit(k, 3, 300*time.Millisecond)
+		require.Nil(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, int64(2-i), res.Remaining)
+	}
+	require.True(t, c.Exists(k))
+	res, err := c.RateLimit(k, 3, 300*time.Millisecond)
+	require.Nil(t, err)
+	require.False(t, res.Allowed)
+	require.Greater(t, res.RetryAfter, time.Duration(0))
+	require.LessOrEqual(t, res.RetryAfter, 600*time.Millisecond)
+	time.Sleep(res.RetryAfter + 10*time.Millisecond)
+	res, err = c.RateLimit(k, 3, 300*time.Millisecond)
+	require.Nil(t, err)
+	require.True(t, res.Allowed)
+	_, err = c.RateLimitArgs(k, types.RateLimitArgs{Limit: 3, Window: time.Second, N: 4})
+	require.Equal(t, types.ErrRateLimit, err)
+
+	// 滑动窗口日志
+	k = "test_rate_limit_log"
+	args := types.RateLimitArgs{Limit: 2, Window: 200 * time.Millisecond, Algorithm: types.RateLimitSlidingLog}
+	for i := 0; i < 2; i++ {
+		res, err = c.RateLimitArgs(k, args)
+		require.Nil(t, err)
+		require.True(t, res.Allowed)
+	}
+	res, err = c.RateLimitArgs(k, args)
+	require.Nil(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, int64(0), res.Remaining)
+	require.LessOrEqual(t, res.RetryAfter, 200*time.Millisecond)
+	time.Sleep(res.RetryAfter + 10*time.Millisecond)
+	res, err = c.RateLimitArgs(k, args)
+	require.Nil(t, err)
+	require.True(t, res.Allowed)
+	// 窗口结束后自动过期
+	time.Sleep(res.ResetAfter + 10*time.Millisecond)
+	require.False(t, c.Exists(k))
+
+	// 并发请求只允许limit个
+	k = "test_rate_limit_concurrent"
+	var wg sync.WaitGroup
+	var allowed int64
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := c.RateLimit(k, 50, time.Hour)
+			require.Nil(t, err)
+			if res.Allowed {
+				atomic.AddInt64(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(50), allowed)
+	c.Del(k)
+	c.Del("test_rate_limit")
+}
+
+func TestTokenBucket(t *testing.T) {
+	for _, algorithm := range []types.RateLimitAlgorithm{types.RateLimitTokenBucket, types.RateLimitGCRA} {
+		k := "test_token_bucket_" + string(algorithm)
+		b := c.TokenBucketArgs(k, types.TokenBucketArgs{Rate: 10, Burst: 5, Algorithm: algorithm})
+		res, err := b.Allow(5)
+		require.Nil(t, err)
+		require.True(t, res.Allowed, algorithm)
+		require.Equal(t, int64(0), res.Remaining)
+		require.True(t, c.Exists(k))
+		res, err = b.Allow(1)
+		require.Nil(t, err)
+		require.False(t, res.Allowed)
+		require.InDelta(t, 100*time.Millisecond, res.RetryAfter, float64(20*time.Millisecond))
+		time.Sleep(res.RetryAfter + 10*time.Millisecond)
+		res, err = b.Allow(1)
+		require.Nil(t, err)
+		require.True(t, res.Allowed, algorithm)
+		_, err = b.Allow(6)
+		require.Equal(t, types.ErrRateLimit, err)
+
+		// 同一个key共享令牌，恢复满桶后自动过期
+		res, err = c.TokenBucketArgs(k, types.TokenBucketArgs{Rate: 10, Burst: 5, Algorithm: algorithm}).Allow(1)
+		require.Nil(t, err)
+		require.False(t, res.Allowed)
+		require.InDelta(t, 500*time.Millisecond, res.ResetAfter, float64(50*time.Millisecond))
+		time.Sleep(res.ResetAfter + 10*time.Millisecond)
+		require.False(t, c.Exists(k))
+	}
+	_, err := c.TokenBucket("test_token_bucket", 0, 1).Allow(1)
+	require.Equal(t, types.ErrRateLimit, err)
+}
+
+func TestLock(t *testing.T) {
+	k := "test_lock"
+	lease, err := c.TryLock(k, time.Second)
+	require.Nil(t, err)
+	require.Len(t, lease.Token(), 32)
+	require.Equal(t, k, lease.Key())
+	_, err = c.TryLock(k, time.Second)
+	require.Equal(t, types.ErrLocked, err)
+	_, err = c.TryLock(k, 0)
+	require.Equal(t, types.ErrExpireArgs, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	_, err = c.Lock(ctx, k, time.Second)
+	cancel()
+	require.Equal(t, context.DeadlineExceeded, err)
+	_, err = c.LockTimeout(context.Background(), k, time.Second, 50*time.Millisecond)
+	require.Equal(t, types.ErrTimeout, err)
+
+	// 释放锁时唤醒等待的调用方
+	acquired := make(chan *Lease)
+	go func() {
+		l, err := c.Lock(context.Background(), k, time.Second)
+		require.Nil(t, err)
+		acquired <- l
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	require.Nil(t, lease.Unlock())
+	require.Equal(t, types.ErrLockNotHeld, lease.Unlock())
+	next := <-acquired
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+	require.NotEqual(t, lease.Token(), next.Token())
+	require.True(t, c.Exists(k))
+
+	// 删除key时唤醒等待的调用方
+	go func() {
+		l, err := c.Lock(context.Background(), k, time.Second)
+		require.Nil(t, err)
+		acquired <- l
+	}()
+	time.Sleep(20 * time.Millisecond)
+	c.Del(k)
+	lease = <-acquired
+	require.Equal(t, types.ErrLockNotHeld, next.Extend(time.Second))
+	require.Nil(t, lease.Unlock())
+
+	// 其他类型的k不能被锁定，原有的数据不变
+	hk := "test_lock_hash"
+	c.HSet(hk, "f", "v")
+	_, err = c.TryLock(hk, time.Second)
+	require.Equal(t, types.ErrLocked, err)
+	_, err = c.Lock(context.Background(), hk, time.Second)
+	require.Equal(t, types.ErrLocked, err)
+	v, err := c.HGet(hk, "f")
+	require.Nil(t, err)
+	require.Equal(t, "v", v)
+	c.Del(hk)
+}
+
+func TestLockExpiration(t *testing.T) {
+	k := "test_lock_expiration"
+	lease, err := c.TryLock(k, 100*time.Millisecond)
+	require.Nil(t, err)
+	require.Nil(t, lease.Extend(300*time.Millisecond))
+	time.Sleep(150 * time.Millisecond)
+	_, err = c.TryLock(k, time.Second)
+	require.Equal(t, types.ErrLocked, err)
+
+	// 锁过期后等待的调用方获取锁，原租约不能再释放或续期
+	start := time.Now()
+	next, err := c.Lock(context.Background(), k, time.Second)
+	require.Nil(t, err)
+	require.InDelta(t, 150*time.Millisecond, time.Since(start), float64(50*time.Millisecond))
+	require.Equal(t, types.ErrLockNotHeld, lease.Unlock())
+	require.Equal(t, types.ErrLockNotHeld, lease.Extend(time.Second))
+	require.Nil(t, next.Unlock())
+	require.False(t, c.Exists(k))
+
+	// 多个goroutine互斥
+	var wg sync.WaitGroup
+	var counter int
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l, err := c.Lock(context.Background(), k, time.Second)
+			require.Nil(t, err)
+			n := counter
+			time.Sleep(time.Millisecond)
+			counter = n + 1
+			require.Nil(t, l.Unlock())
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 20, counter)
+}
+
+func TestGetOrLoad(t *testing.T) {
+	k := "test_get_or_load"
+	var calls int64
+	loader := func(ctx context.Context) (any, error) {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return "loaded", nil
+	}
+	// 同时加载同一个key时只调用一次loader
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad(context.Background(), k, 200*time.Millisecond, loader)
+			require.Nil(t, err)
+			require.Equal(t, "loaded", v)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(1), calls)
+	v, err := c.GetOrLoad(context.Background(), k, 200*time.Millisecond, loader)
+	require.Nil(t, err)
+	require.Equal(t, "loaded", v)
+	require.Equal(t, int64(1), calls)
+	v, err = c.Get(k)
+	require.Nil(t, err)
+	require.Equal(t, "loaded", v)
+	time.Sleep(250 * time.Millisecond)
+	require.False(t, c.Exists(k))
+
+	// 等待的调用方可以单独取消
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		v, err := c.GetOrLoad(context.Background(), k, 100*time.Millisecond, loader)
+		require.Nil(t, err)
+		require.Equal(t, "loaded", v)
+		close(done)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	_, err = c.GetOrLoad(ctx, k, time.Second, loader)
+	require.Equal(t, context.DeadlineExceeded, err)
+	<-done
+
+	_, err = c.GetOrLoad(context.Background(), "test_get_or_load_none", 0, nil)
+	require.Equal(t, types.ErrNoLoader, err)
+
+	// 发起加载的调用方取消后，loader继续执行，等待的调用方仍然得到结果
+	k = "test_get_or_load_cancel"
+	leaderCtx, leaderCancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad(leaderCtx, k, 0, func(ctx context.Context) (any, error) {
+			time.Sleep(50 * time.Millisecond)
+			return "loaded", ctx.Err()
+		})
+		leaderErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	leaderCancel()
+	require.Equal(t, context.Canceled, <-leaderErr)
+	v, err = c.GetOrLoad(context.Background(), k, 0, loader)
+	require.Nil(t, err)
+	require.Equal(t, "loaded", v)
+	c.Del(k)
+
+	// loader panic时所有调用方返回错误
+	k = "test_get_or_load_panic"
+	release := make(chan struct{})
+	leaderErr = make(chan error)
+	go func() {
+		_, err := c.GetOrLoad(context.Background(), k, 0, func(ctx context.Context) (any, error) {
+			<-release
+			panic("boom")
+		})
+		leaderErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	_, err = c.GetOrLoad(context.Background(), k, 0, loader)
+	require.ErrorIs(t, err, types.ErrLoaderPanic)
+	require.ErrorIs(t, <-leaderErr, types.ErrLoaderPanic)
+	c.Del("test_get_or_load")
+}
+
+func TestGetOrLoadOptions(t *testing.T) {
+	errLoad := fmt.Errorf("load failed")
+	var calls int64
+	lc := NewCache(WithNegativeTTL(100*time.Millisecond), WithLoader(func(ctx context.Context, k string) (any, error) {
+		atomic.AddInt64(&calls, 1)
+		if k == "bad" {
+			return nil, errLoad
+		}
+		return "value of " + k, nil
+	}))
+	defer lc.destroy()
+
+	v, err := lc.GetOrLoad(context.Background(), "good", 0, nil)
+	require.Nil(t, err)
+	require.Equal(t, "value of good", v)
+	// 加载失败的错误在negativeTTL内被缓存
+	for i := 0; i < 3; i++ {
+		_, err = lc.GetOrLoad(context.Background(), "bad", 0, nil)
+		require.Equal(t, errLoad, err)
+	}
+	require.Equal(t, int64(2), calls)
+	require.False(t, lc.Exists("bad"))
+	time.Sleep(150 * time.Millisecond)
+	_, err = lc.GetOrLoad(context.Background(), "bad", 0, nil)
+	require.Equal(t, errLoad, err)
+	require.Equal(t, int64(3), calls)
+	// 传入的loader优先于全局的加载函数
+	v, err = lc.GetOrLoad(context.Background(), "custom", 0, func(ctx context.Context) (any, error) {
+		return 1, nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, v)
+}
+
 func TestExpiration(t *testing.T) {
 	k := "exp"
 	v := "hello"
@@ -268,6 +2617,104 @@ func BenchmarkGetString(b *testing.B) {
 	}
 }
 
+func BenchmarkLPushRPop(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	key := "benchmarkQueue"
+	for j := 0; j < b.N; j++ {
+		c.LPush(key, j)
+		if j%2 == 0 {
+			_, _ = c.RPop(key)
+		}
+	}
+}
+
+func TestHScan(t *testing.T) {
+	key := "test_hscan"
+	for i := 0; i < 100; i++ {
+		c.HSet(key, "f"+strconv.Itoa(i), i)
+	}
+	c.HSet(key, "other", "x")
+
+	seen := make(map[string]any)
+	var cursor uint64
+	for {
+		fields, next, err := c.HScan(key, cursor, "f*", 7)
+		require.Nil(t, err)
+		for field, v := range fields {
+			seen[field] = v
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	require.Len(t, seen, 100)
+	require.Equal(t, 42, seen["f42"])
+	require.NotContains(t, seen, "other")
+
+	_, _, err := c.HScan("test_hscan_not_exist", 0, "", 0)
+	require.Equal(t, types.ErrHashKey, err)
+}
+
+func TestSScanWhileModified(t *testing.T) {
+	key := "test_sscan"
+	for i := 0; i < 200; i++ {
+		require.Nil(t, c.SAdd(key, i))
+	}
+
+	seen := make(map[string]bool)
+	var cursor uint64
+	var round int
+	for {
+		members, next, err := c.SScan(key, cursor, "", 10)
+		require.Nil(t, err)
+		for _, m := range members {
+			seen[m.(string)] = true
+		}
+		// 遍历期间删除 100~199 中的元素并添加新元素
+		require.Nil(t, c.SRem(key, 100+round))
+		require.Nil(t, c.SAdd(key, 1000+round))
+		round++
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	// 遍历期间一直存在的元素都至少返回一次
+	for i := 0; i < 100; i++ {
+		require.True(t, seen[strconv.Itoa(i)], i)
+	}
+
+	members, cursor, err := c.SScan(key, 0, "1?", 1000)
+	require.Nil(t, err)
+	require.Equal(t, uint64(0), cursor)
+	require.ElementsMatch(t, []any{"10", "11", "12", "13", "14", "15", "16", "17", "18", "19"}, members)
+}
+
+func TestZScan(t *testing.T) {
+	key := "test_zscan"
+	for i := 0; i < 50; i++ {
+		require.Nil(t, c.ZAdd(key, "m"+strconv.Itoa(i), float64(i)))
+	}
+
+	seen := make(map[string]float64)
+	var cursor uint64
+	for {
+		elements, next, err := c.ZScan(key, cursor, "m[0-4]", 3)
+		require.Nil(t, err)
+		for e, score := range elements {
+			seen[e] = score
+			// 遍历期间修改score，不影响遍历
+			_, err = c.ZIncrBy(key, e, 100)
+			require.Nil(t, err)
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	require.Equal(t, map[string]float64{"m0": 0, "m1": 1, "m2": 2, "m3": 3, "m4": 4}, seen)
+	require.Equal(t, 50, c.ZCard(key))
+}
+
 func TestSetStringQPS(t *testing.T) {
 	start := time.Now()
 	loop := 1000000
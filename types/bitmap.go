@@ -0,0 +1,440 @@
+package types
+
+import (
+	"math"
+	"math/bits"
+	"strconv"
+)
+
+// maxBitOffset 位图中位的最大下标
+const maxBitOffset = maxStringLen*8 - 1
+
+// BitRange BitCount和BitPos的查找区间
+// Start、End 为闭区间的起止位置，支持负数下标；Unit 为区间的单位，为空时按字节
+type BitRange struct {
+	Start int64
+	End   int64
+	Unit  BitUnit
+}
+
+// BitFieldOp BitField的一个操作
+// Cmd 为GET、SET或INCRBY；Type 为字段类型，i表示有符号，u表示无符号，如i5、u8，最大为i64、u63
+// Offset 为字段起始的位；Value 为SET写入的值或INCRBY的增量
+// Overflow 为SET和INCRBY溢出时的处理方式，为空时为WRAP
+type BitFieldOp struct {
+	Cmd      BitFieldCmd
+	Type     string
+	Offset   int64
+	Value    int64
+	Overflow BitOverflow
+}
+
+// SetBit 设置k的值中offset位置的位，长度不足时自动扩展
+// return old int 为原来的位，exist bool 表示操作前k是否存在
+func (s *Strings) SetBit(k string, offset int64, value int) (old int, exist bool, err error) {
+	if offset < 0 || offset > maxBitOffset {
+		return 0, false, ErrOffset
+	}
+	if value != 0 && value != 1 {
+		return 0, false, ErrBitValue
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	exist = i != nil
+	if !exist {
+		i = newItem()
+	}
+	b, err := i.bitmap(int(offset/8) + 1)
+	if err != nil {
+		return 0, exist, err
+	}
+	s.items[k] = i
+	old = getBit(b, offset)
+	mask := byte(1) << (7 - offset%8)
+	if value == 1 {
+		b[offset/8] |= mask
+	} else {
+		b[offset/8] &^= mask
+	}
+	return old, exist, nil
+}
+
+// GetBit 获取k的值中offset位置的位，超出长度或k不存在时为0
+func (s *Strings) GetBit(k string, offset int64) (int, error) {
+	if offset < 0 || offset > maxBitOffset {
+		return 0, ErrOffset
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bytes(k)
+	if err != nil {
+		return 0, err
+	}
+	return getBit(b, offset), nil
+}
+
+// BitCount 统计k的值中为1的位的数量，r 为nil时统计全部
+func (s *Strings) BitCount(k string, r *BitRange) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bytes(k)
+	if err != nil {
+		return 0, err
+	}
+	start, end, ok, err := r.bits(len(b))
+	if err != nil || !ok {
+		return 0, err
+	}
+	var count int64
+	for p := start; p <= end; {
+		if p%8 == 0 && p+7 <= end {
+			count += int64(bits.OnesCount8(b[p/8]))
+			p += 8
+			continue
+		}
+		count += int64(getBit(b, p))
+		p++
+	}
+	return count, nil
+}
+
+// BitPos 查找k的值中第一个值为bit的位的位置，未找到时返回-1
+// r 为nil时查找全部，此时查找0且所有位都为1时，返回值的最后一位之后的位置
+func (s *Strings) BitPos(k string, bit int, r *BitRange) (int64, error) {
+	if bit != 0 && bit != 1 {
+		return 0, ErrBitValue
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.bytes(k)
+	if err != nil {
+		return 0, err
+	}
+	start, end, ok, err := r.bits(len(b))
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 && bit == 0 {
+		// 不存在的k视为所有位都为0
+		return 0, nil
+	}
+	if !ok {
+		return -1, nil
+	}
+	skip := byte(0)
+	if bit == 0 {
+		skip = 0xff
+	}
+	for p := start; p <= end; {
+		if p%8 == 0 && p+7 <= end && b[p/8] == skip {
+			p += 8
+			continue
+		}
+		if getBit(b, p) == bit {
+			return p, nil
+		}
+		p++
+	}
+	if bit == 0 && r == nil {
+		return int64(len(b)) * 8, nil
+	}
+	return -1, nil
+}
+
+// BitOp 对多个k的值进行位运算，结果存储到dst中，不存在的k视为空值
+// 长度不同时较短的值使用0字节补齐，结果为空时删除dst
+// return int 为dst中值的长度
+func (s *Strings) BitOp(op BitOp, dst string, keys ...string) (int, error) {
+	switch op {
+	case BitAnd, BitOr, BitXor:
+		if len(keys) == 0 {
+			return 0, ErrBitOp
+		}
+	case BitNot:
+		if len(keys) != 1 {
+			return 0, ErrBitOp
+		}
+	default:
+		return 0, ErrBitOp
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make([][]byte, len(keys))
+	var n int
+	for idx, k := range keys {
+		b, err := s.bytes(k)
+		if err != nil {
+			return 0, err
+		}
+		values[idx] = b
+		if len(b) > n {
+			n = len(b)
+		}
+	}
+	if n == 0 {
+		s.del(dst)
+		return 0, nil
+	}
+	result := make([]byte, n)
+	copy(result, values[0])
+	for _, b := range values[1:] {
+		for idx := range result {
+			var v byte
+			if idx < len(b) {
+				v = b[idx]
+			}
+			switch op {
+			case BitAnd:
+				result[idx] &= v
+			case BitOr:
+				result[idx] |= v
+			case BitXor:
+				result[idx] ^= v
+			}
+		}
+	}
+	if op == BitNot {
+		for idx := range result {
+			result[idx] = ^result[idx]
+		}
+	}
+	s.set(dst, result)
+	return n, nil
+}
+
+// BitField 对k的值中任意位置、任意宽度的整数字段依次执行多个操作
+// 返回每个操作的结果，GET为字段的值，SET为原来的值，INCRBY为新的值，
+// 溢出方式为FAIL且发生溢出时结果为nil；只有GET操作时不会创建k
+// return exist bool 表示操作前k是否存在
+func (s *Strings) BitField(k string, ops ...BitFieldOp) (result []any, exist bool, err error) {
+	fields := make([]bitField, len(ops))
+	var size int64
+	for idx, op := range ops {
+		if fields[idx], err = parseBitField(op); err != nil {
+			return nil, false, err
+		}
+		if op.Cmd != BitFieldGet {
+			size = max64(size, (op.Offset+int64(fields[idx].width)+7)/8)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	exist = i != nil
+	var b []byte
+	if size > 0 {
+		if !exist {
+			i = newItem()
+		}
+		if b, err = i.bitmap(int(size)); err != nil {
+			return nil, exist, err
+		}
+		s.items[k] = i
+	} else if b, err = s.bytes(k); err != nil {
+		return nil, exist, err
+	}
+	result = make([]any, len(ops))
+	for idx, op := range ops {
+		f := fields[idx]
+		old := f.get(b, op.Offset)
+		switch op.Cmd {
+		case BitFieldGet:
+			result[idx] = old
+		case BitFieldSet:
+			if v, ok := f.overflow(0, op.Value, op.Overflow); ok {
+				f.set(b, op.Offset, v)
+				result[idx] = old
+			}
+		case BitFieldIncrBy:
+			if v, ok := f.overflow(old, op.Value, op.Overflow); ok {
+				f.set(b, op.Offset, v)
+				result[idx] = v
+			}
+		}
+	}
+	return result, exist, nil
+}
+
+// bytes 获取k的值的内容，用于只读的位图操作，k不存在时返回nil
+func (s *Strings) bytes(k string) ([]byte, error) {
+	i := s.get(k)
+	if i == nil {
+		return nil, nil
+	}
+	switch o := i.object.(type) {
+	case []byte:
+		return o, nil
+	case string:
+		return []byte(o), nil
+	}
+	return nil, ErrNotString
+}
+
+// bitmap 获取可以原地修改的位图，长度不足n时使用0字节扩展
+// 值为string或可能被外部持有时先复制，之后按[]byte存储
+func (i *Item) bitmap(n int) ([]byte, error) {
+	var b []byte
+	switch o := i.object.(type) {
+	case nil:
+	case string:
+		b = []byte(o)
+	case []byte:
+		b = o
+		if i.shared {
+			b = append(make([]byte, 0, len(o)), o...)
+		}
+	default:
+		return nil, ErrNotString
+	}
+	if len(b) < n {
+		if cap(b) >= n {
+			// 切片末尾之外的内容可能不为0
+			tail := b[len(b):n]
+			for idx := range tail {
+				tail[idx] = 0
+			}
+			b = b[:n]
+		} else {
+			// 按倍数扩容，连续设置递增的位时避免频繁复制
+			grown := make([]byte, n, max64(int64(n), int64(cap(b))*2))
+			copy(grown, b)
+			b = grown
+		}
+	}
+	i.object = b
+	i.shared = false
+	return b, nil
+}
+
+// bits 将查找区间转换为长度为n字节的值中，位的闭区间
+// r 为nil时为全部的位，ok 为false时区间为空
+func (r *BitRange) bits(n int) (start, end int64, ok bool, err error) {
+	total := int64(n) * 8
+	if r == nil {
+		return 0, total - 1, total > 0, nil
+	}
+	switch r.Unit {
+	case "", BitUnitByte:
+		s, e, ok := rangeIndex(int(r.Start), int(r.End), n)
+		return int64(s) * 8, int64(e)*8 + 7, ok, nil
+	case BitUnitBit:
+		s, e, ok := rangeIndex(int(r.Start), int(r.End), int(total))
+		return int64(s), int64(e), ok, nil
+	}
+	return 0, 0, false, ErrBitUnit
+}
+
+// bitField BitField中整数字段的类型
+type bitField struct {
+	signed bool
+	width  int
+}
+
+// parseBitField 校验BitField的操作，并解析字段的类型
+func parseBitField(op BitFieldOp) (bitField, error) {
+	var f bitField
+	switch op.Cmd {
+	case BitFieldGet, BitFieldSet, BitFieldIncrBy:
+	default:
+		return f, ErrBitField
+	}
+	switch op.Overflow {
+	case "", BitOverflowWrap, BitOverflowSat, BitOverflowFail:
+	default:
+		return f, ErrBitField
+	}
+	if len(op.Type) < 2 || (op.Type[0] != 'i' && op.Type[0] != 'u') {
+		return f, ErrBitField
+	}
+	width, err := strconv.Atoi(op.Type[1:])
+	f.signed = op.Type[0] == 'i'
+	if err != nil || width < 1 || (f.signed && width > 64) || (!f.signed && width > 63) {
+		return f, ErrBitField
+	}
+	f.width = width
+	if op.Offset < 0 || op.Offset+int64(width)-1 > maxBitOffset {
+		return f, ErrOffset
+	}
+	return f, nil
+}
+
+// limits 获取字段能表示的最小值和最大值
+func (f bitField) limits() (min, max int64) {
+	if f.signed {
+		return -1 << (f.width - 1), 1<<(f.width-1) - 1
+	}
+	return 0, 1<<f.width - 1
+}
+
+// get 读取offset位置的字段，有符号的字段按符号位扩展
+func (f bitField) get(b []byte, offset int64) int64 {
+	var u uint64
+	for idx := 0; idx < f.width; idx++ {
+		u = u<<1 | uint64(getBit(b, offset+int64(idx)))
+	}
+	return f.truncate(u)
+}
+
+// set 将v的低width位写入offset位置，b的长度需足够
+func (f bitField) set(b []byte, offset int64, v int64) {
+	u := uint64(v)
+	for idx := 0; idx < f.width; idx++ {
+		p := offset + int64(idx)
+		mask := byte(1) << (7 - p%8)
+		if u>>(f.width-1-idx)&1 == 1 {
+			b[p/8] |= mask
+		} else {
+			b[p/8] &^= mask
+		}
+	}
+}
+
+// truncate 保留u的低width位，有符号的字段按符号位扩展
+func (f bitField) truncate(u uint64) int64 {
+	if f.width < 64 {
+		u &= 1<<f.width - 1
+	}
+	if f.signed && f.width < 64 && u>>(f.width-1) == 1 {
+		u |= math.MaxUint64 << f.width
+	}
+	return int64(u)
+}
+
+// overflow 计算v+incr，并按溢出方式处理超出字段范围的结果
+// return ok bool 为false时表示溢出且不写入
+func (f bitField) overflow(v, incr int64, mode BitOverflow) (int64, bool) {
+	min, max := f.limits()
+	over := incr > 0 && v > max-incr
+	// 无符号字段的min为0，incr为MinInt64时 min-incr 会溢出，此时必然下溢
+	under := incr < 0 && ((!f.signed && incr == math.MinInt64) || v < min-incr)
+	if !over && !under {
+		return v + incr, true
+	}
+	switch mode {
+	case BitOverflowSat:
+		if over {
+			return max, true
+		}
+		return min, true
+	case BitOverflowFail:
+		return 0, false
+	}
+	return f.truncate(uint64(v) + uint64(incr)), true
+}
+
+// getBit 获取b中第p位，超出长度时为0
+func getBit(b []byte, p int64) int {
+	if p/8 >= int64(len(b)) {
+		return 0
+	}
+	return int(b[p/8] >> (7 - p%8) & 1)
+}
+
+// max64 获取a和b中较大的值
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
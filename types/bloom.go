@@ -0,0 +1,348 @@
+package types
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// 布隆过滤器的默认参数与RedisBloom一致
+const (
+	bloomDefaultErrorRate = 0.01               // BFAdd自动创建时的错误率
+	bloomDefaultCapacity  = 100                // BFAdd自动创建时的容量
+	bloomDefaultExpansion = 2                  // 扩容时新一层容量的倍数
+	bloomTightening       = 0.5                // 扩容时新一层错误率的倍数，保证总错误率不超过设定值
+	bloomSeed             = 0xc6a4a7935bd1e995 // 计算第一个hash的种子
+)
+
+// NewBloomFilters 创建布隆过滤器类型实例
+func NewBloomFilters() *BloomFilters {
+	return &BloomFilters{
+		items: make(map[string]*BloomFilter),
+	}
+}
+
+// BloomFilters 布隆过滤器类型数据结构
+type BloomFilters struct {
+	mu    sync.Mutex
+	items map[string]*BloomFilter
+}
+
+// BFReserveArgs 创建布隆过滤器的参数
+// ErrorRate 为误判率，取值范围(0, 1)；Capacity 为预计添加的元素数量
+// Expansion 为元素数量超过容量时，新一层容量的倍数，为0时为2
+// NonScaling 为true时不扩容，元素数量达到容量后添加返回 ErrFilterFull
+type BFReserveArgs struct {
+	ErrorRate  float64
+	Capacity   int64
+	Expansion  int
+	NonScaling bool
+}
+
+// BFInfo 布隆过滤器的信息
+// Capacity 为所有层的总容量，Size 为占用的字节数，Filters 为层数，Items 为添加的元素数量
+type BFInfo struct {
+	Capacity  int64
+	Size      int
+	Filters   int
+	Items     int64
+	Expansion int
+}
+
+// Exist 判断k是否存在
+func (bs *BloomFilters) Exist(k string) bool {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	return bs.exist(k)
+}
+
+// exist 判断k是否存在
+func (bs *BloomFilters) exist(k string) bool {
+	f, exist := bs.items[k]
+	if !exist {
+		return false
+	}
+	if f.isExpired() {
+		bs.del(k)
+		return false
+	}
+	return true
+}
+
+// BFReserve 按照args创建一个空的布隆过滤器，k已存在时返回 ErrFilterExists
+func (bs *BloomFilters) BFReserve(k string, args BFReserveArgs) error {
+	if args.ErrorRate <= 0 || args.ErrorRate >= 1 || args.Capacity <= 0 || args.Expansion < 0 {
+		return ErrFilterArgs
+	}
+	if args.Expansion == 0 {
+		args.Expansion = bloomDefaultExpansion
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.exist(k) {
+		return ErrFilterExists
+	}
+	bs.items[k] = newBloomFilter(args)
+	return nil
+}
+
+// BFAdd 向布隆过滤器中添加一个元素，k不存在时按默认参数创建
+// return added bool 表示元素之前是否一定不存在，exist bool 表示添加前k是否存在
+func (bs *BloomFilters) BFAdd(k string, item any) (added, exist bool, err error) {
+	results, exist, err := bs.BFMAdd(k, item)
+	if err != nil {
+		return false, exist, err
+	}
+	return results[0], exist, nil
+}
+
+// BFMAdd 向布隆过滤器中添加多个元素，k不存在时按默认参数创建
+// 不扩容的过滤器已满时返回 ErrFilterFull，之前的元素已经添加
+func (bs *BloomFilters) BFMAdd(k string, items ...any) (results []bool, exist bool, err error) {
+	members, err := toMembers(items)
+	if err != nil {
+		return nil, false, err
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	f := bs.get(k)
+	if exist = f != nil; !exist {
+		f = newBloomFilter(BFReserveArgs{
+			ErrorRate: bloomDefaultErrorRate,
+			Capacity:  bloomDefaultCapacity,
+			Expansion: bloomDefaultExpansion,
+		})
+		bs.items[k] = f
+	}
+	results = make([]bool, len(members))
+	for i, m := range members {
+		if results[i], err = f.add(m); err != nil {
+			return nil, exist, err
+		}
+	}
+	return results, exist, nil
+}
+
+// BFExists 判断元素是否可能存在于布隆过滤器中，返回false时一定不存在
+func (bs *BloomFilters) BFExists(k string, item any) (bool, error) {
+	results, err := bs.BFMExists(k, item)
+	if err != nil {
+		return false, err
+	}
+	return results[0], nil
+}
+
+// BFMExists 判断多个元素是否可能存在于布隆过滤器中
+func (bs *BloomFilters) BFMExists(k string, items ...any) ([]bool, error) {
+	members, err := toMembers(items)
+	if err != nil {
+		return nil, err
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	results := make([]bool, len(members))
+	f := bs.get(k)
+	if f == nil {
+		return results, nil
+	}
+	for i, m := range members {
+		results[i] = f.exists(bloomHash(m))
+	}
+	return results, nil
+}
+
+// BFInfo 获取布隆过滤器的信息
+func (bs *BloomFilters) BFInfo(k string) (*BFInfo, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	f := bs.get(k)
+	if f == nil {
+		return nil, ErrFilterKey
+	}
+	info := &BFInfo{Filters: len(f.layers), Expansion: f.expansion}
+	for _, l := range f.layers {
+		info.Capacity += l.capacity
+		info.Size += len(l.bits) * 8
+		info.Items += l.items
+	}
+	return info, nil
+}
+
+// get 获取k对应的未过期布隆过滤器，不存在时返回nil
+func (bs *BloomFilters) get(k string) *BloomFilter {
+	if !bs.exist(k) {
+		return nil
+	}
+	return bs.items[k]
+}
+
+// Del 删除一个key
+func (bs *BloomFilters) Del(k string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.del(k)
+}
+
+func (bs *BloomFilters) del(k string) {
+	delete(bs.items, k)
+}
+
+// Expiration 设置超时时间
+func (bs *BloomFilters) Expiration(k string, d time.Duration) error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if !bs.exist(k) {
+		return ErrKeyNotExist
+	}
+	bs.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (bs *BloomFilters) ClearExpiration() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	for key, item := range bs.items {
+		if item.isExpired() {
+			delete(bs.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (bs *BloomFilters) RandomClearExpiration() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	var counter int
+	for key, item := range bs.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(bs.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (bs *BloomFilters) Flush() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.items = make(map[string]*BloomFilter)
+}
+
+// newBloomFilter 按照参数创建只有一层的布隆过滤器
+// 可扩容时第一层的错误率为 errorRate*(1-bloomTightening)，各层错误率之和不超过errorRate
+func newBloomFilter(args BFReserveArgs) *BloomFilter {
+	errorRate := args.ErrorRate
+	if !args.NonScaling {
+		errorRate *= 1 - bloomTightening
+	}
+	return &BloomFilter{
+		layers:     []*bloomLayer{newBloomLayer(args.Capacity, errorRate)},
+		errorRate:  errorRate,
+		expansion:  args.Expansion,
+		nonScaling: args.NonScaling,
+		expiration: DefaultExpiration,
+	}
+}
+
+// BloomFilter 可扩容的布隆过滤器
+// 最后一层的元素数量达到容量时，新增一层容量为expansion倍、错误率减半的过滤器
+// 查询时检查所有层，总错误率不超过创建时指定的错误率
+type BloomFilter struct {
+	layers     []*bloomLayer
+	errorRate  float64
+	expansion  int
+	nonScaling bool
+	expiration int64
+}
+
+// add 添加一个元素
+// return bool 表示元素之前是否一定不存在
+func (f *BloomFilter) add(m string) (bool, error) {
+	h1, h2 := bloomHash(m)
+	if f.exists(h1, h2) {
+		return false, nil
+	}
+	last := f.layers[len(f.layers)-1]
+	if last.items >= last.capacity {
+		if f.nonScaling {
+			return false, ErrFilterFull
+		}
+		f.errorRate *= bloomTightening
+		last = newBloomLayer(last.capacity*int64(f.expansion), f.errorRate)
+		f.layers = append(f.layers, last)
+	}
+	last.add(h1, h2)
+	return true, nil
+}
+
+// exists 判断元素是否可能存在于任意一层中
+func (f *BloomFilter) exists(h1, h2 uint64) bool {
+	for _, l := range f.layers {
+		if l.test(h1, h2) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExpired 判断一个元素是否过期
+func (f *BloomFilter) isExpired() bool {
+	if f.expiration != DefaultExpiration && time.Now().UnixNano() > f.expiration {
+		return true
+	}
+	return false
+}
+
+// newBloomLayer 按照容量和错误率创建一层布隆过滤器
+// 位数为 -n*ln(p)/ln(2)^2，hash函数数量为 -log2(p)
+func newBloomLayer(capacity int64, errorRate float64) *bloomLayer {
+	size := uint64(math.Ceil(-float64(capacity) * math.Log(errorRate) / (math.Ln2 * math.Ln2)))
+	words := (size + 63) / 64
+	return &bloomLayer{
+		bits:     make([]uint64, words),
+		size:     words * 64,
+		hashes:   int(math.Ceil(-math.Log2(errorRate))),
+		capacity: capacity,
+	}
+}
+
+// bloomLayer 布隆过滤器的一层
+// size 为位数，hashes 为每个元素设置的位数，items 为添加到该层的元素数量
+type bloomLayer struct {
+	bits     []uint64
+	size     uint64
+	hashes   int
+	capacity int64
+	items    int64
+}
+
+// add 设置元素对应的所有位
+func (l *bloomLayer) add(h1, h2 uint64) {
+	for i := 0; i < l.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % l.size
+		l.bits[bit/64] |= 1 << (bit % 64)
+	}
+	l.items++
+}
+
+// test 判断元素对应的所有位是否都已设置
+func (l *bloomLayer) test(h1, h2 uint64) bool {
+	for i := 0; i < l.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % l.size
+		if l.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash 计算元素的两个hash，第i个位置为 h1 + i*h2
+func bloomHash(m string) (h1, h2 uint64) {
+	h1 = murmurHash64A([]byte(m), bloomSeed)
+	h2 = murmurHash64A([]byte(m), h1)
+	return h1, h2
+}
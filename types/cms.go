@@ -0,0 +1,281 @@
+package types
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// NewCountMinSketches 创建Count-Min Sketch类型实例
+func NewCountMinSketches() *CountMinSketches {
+	return &CountMinSketches{
+		items: make(map[string]*CountMinSketch),
+	}
+}
+
+// CountMinSketches Count-Min Sketch类型数据结构
+type CountMinSketches struct {
+	mu    sync.Mutex
+	items map[string]*CountMinSketch
+}
+
+// CMSInfo Count-Min Sketch的信息，Count 为所有元素增加的总数
+type CMSInfo struct {
+	Width int
+	Depth int
+	Count int64
+}
+
+// Exist 判断k是否存在
+func (cs *CountMinSketches) Exist(k string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.exist(k)
+}
+
+// exist 判断k是否存在
+func (cs *CountMinSketches) exist(k string) bool {
+	s, exist := cs.items[k]
+	if !exist {
+		return false
+	}
+	if s.isExpired() {
+		cs.del(k)
+		return false
+	}
+	return true
+}
+
+// CMSInitByDim 按照宽度和深度创建Count-Min Sketch，k已存在时返回 ErrSketchExists
+func (cs *CountMinSketches) CMSInitByDim(k string, width, depth int) error {
+	if width <= 0 || depth <= 0 {
+		return ErrSketchArgs
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.exist(k) {
+		return ErrSketchExists
+	}
+	cs.items[k] = newCountMinSketch(width, depth)
+	return nil
+}
+
+// CMSInitByProb 按照误差和概率创建Count-Min Sketch，与RedisBloom一致
+// errorRate 为估算值超出的数量占总数的比例，probability 为超出该误差的概率
+func (cs *CountMinSketches) CMSInitByProb(k string, errorRate, probability float64) error {
+	if errorRate <= 0 || errorRate >= 1 || probability <= 0 || probability >= 1 {
+		return ErrSketchArgs
+	}
+	width := int(math.Ceil(2 / errorRate))
+	depth := int(math.Ceil(math.Log10(probability) / math.Log10(0.5)))
+	return cs.CMSInitByDim(k, width, depth)
+}
+
+// CMSIncrBy 将元素的计数增加increment，increment 不能为负数
+// return int64 为增加后元素计数的估算值
+func (cs *CountMinSketches) CMSIncrBy(k string, item any, increment int64) (int64, error) {
+	m, err := toMember(item)
+	if err != nil {
+		return 0, err
+	}
+	if increment < 0 {
+		return 0, ErrSketchArgs
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	s := cs.get(k)
+	if s == nil {
+		return 0, ErrSketchKey
+	}
+	return s.incrBy(m, increment), nil
+}
+
+// CMSQuery 获取元素计数的估算值，估算值不小于实际值
+func (cs *CountMinSketches) CMSQuery(k string, items ...any) ([]int64, error) {
+	members, err := toMembers(items)
+	if err != nil {
+		return nil, err
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	s := cs.get(k)
+	if s == nil {
+		return nil, ErrSketchKey
+	}
+	counts := make([]int64, len(members))
+	for i, m := range members {
+		counts[i] = s.query(m)
+	}
+	return counts, nil
+}
+
+// CMSMerge 将多个Count-Min Sketch按权重合并到dst中，dst原有的计数会被覆盖
+// dst和keys需要已经创建并且宽度和深度相同，weights 为空时权重都为1
+func (cs *CountMinSketches) CMSMerge(dst string, keys []string, weights []int64) error {
+	if len(keys) == 0 {
+		return ErrSketchArgs
+	}
+	if len(weights) > 0 && len(weights) != len(keys) {
+		return ErrWeights
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	d := cs.get(dst)
+	if d == nil {
+		return ErrSketchKey
+	}
+	counters := make([]int64, len(d.counters))
+	var count int64
+	for i, k := range keys {
+		s := cs.get(k)
+		if s == nil {
+			return ErrSketchKey
+		}
+		if s.width != d.width || s.depth != d.depth {
+			return ErrSketchDims
+		}
+		weight := int64(1)
+		if len(weights) > 0 {
+			weight = weights[i]
+		}
+		for j, c := range s.counters {
+			counters[j] += c * weight
+		}
+		count += s.count * weight
+	}
+	d.counters, d.count = counters, count
+	return nil
+}
+
+// CMSInfo 获取Count-Min Sketch的宽度、深度和总数
+func (cs *CountMinSketches) CMSInfo(k string) (*CMSInfo, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	s := cs.get(k)
+	if s == nil {
+		return nil, ErrSketchKey
+	}
+	return &CMSInfo{Width: s.width, Depth: s.depth, Count: s.count}, nil
+}
+
+// get 获取k对应的未过期Count-Min Sketch，不存在时返回nil
+func (cs *CountMinSketches) get(k string) *CountMinSketch {
+	if !cs.exist(k) {
+		return nil
+	}
+	return cs.items[k]
+}
+
+// Del 删除一个key
+func (cs *CountMinSketches) Del(k string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.del(k)
+}
+
+func (cs *CountMinSketches) del(k string) {
+	delete(cs.items, k)
+}
+
+// Expiration 设置超时时间
+func (cs *CountMinSketches) Expiration(k string, d time.Duration) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if !cs.exist(k) {
+		return ErrKeyNotExist
+	}
+	cs.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (cs *CountMinSketches) ClearExpiration() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for key, item := range cs.items {
+		if item.isExpired() {
+			delete(cs.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (cs *CountMinSketches) RandomClearExpiration() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var counter int
+	for key, item := range cs.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(cs.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (cs *CountMinSketches) Flush() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.items = make(map[string]*CountMinSketch)
+}
+
+// newCountMinSketch 创建一个Count-Min Sketch
+func newCountMinSketch(width, depth int) *CountMinSketch {
+	return &CountMinSketch{
+		width:      width,
+		depth:      depth,
+		counters:   make([]int64, width*depth),
+		expiration: DefaultExpiration,
+	}
+}
+
+// CountMinSketch 使用depth行、每行width个计数器估算元素的计数
+// 每行使用不同的hash选择计数器，估算值为各行计数器的最小值
+type CountMinSketch struct {
+	width      int
+	depth      int
+	counters   []int64
+	count      int64
+	expiration int64
+}
+
+// incrBy 增加元素的计数，并返回增加后的估算值
+func (s *CountMinSketch) incrBy(m string, increment int64) int64 {
+	min := int64(math.MaxInt64)
+	for i := 0; i < s.depth; i++ {
+		j := s.index(m, i)
+		s.counters[j] += increment
+		if s.counters[j] < min {
+			min = s.counters[j]
+		}
+	}
+	s.count += increment
+	return min
+}
+
+// query 获取元素计数的估算值
+func (s *CountMinSketch) query(m string) int64 {
+	min := int64(math.MaxInt64)
+	for i := 0; i < s.depth; i++ {
+		if c := s.counters[s.index(m, i)]; c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+// index 获取元素在第row行的计数器位置
+func (s *CountMinSketch) index(m string, row int) int {
+	return row*s.width + int(murmurHash64A([]byte(m), uint64(row))%uint64(s.width))
+}
+
+// isExpired 判断一个元素是否过期
+func (s *CountMinSketch) isExpired() bool {
+	if s.expiration != DefaultExpiration && time.Now().UnixNano() > s.expiration {
+		return true
+	}
+	return false
+}
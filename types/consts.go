@@ -3,18 +3,161 @@ package types
 import "time"
 
 const (
-	DefaultExpiration = -1
-	TypeString        = KeyType("string")
-	TypeList          = KeyType("list")
-	TypeHash          = KeyType("hash")
-	TypeSet           = KeyType("set")
-	TypeZSet          = KeyType("zSet")
-	DefaultScore      = float64(0)
-	ErrorRank         = -1
+	DefaultExpiration  = -1
+	TypeString         = KeyType("string")
+	TypeList           = KeyType("list")
+	TypeHash           = KeyType("hash")
+	TypeSet            = KeyType("set")
+	TypeZSet           = KeyType("zSet")
+	TypeHyperLogLog    = KeyType("hyperLogLog")
+	TypeStream         = KeyType("stream")
+	TypeBloom          = KeyType("bloom")
+	TypeCuckoo         = KeyType("cuckoo")
+	TypeCountMinSketch = KeyType("countMinSketch")
+	TypeTopK           = KeyType("topK")
+	TypeJSON           = KeyType("json")
+	TypeTimeSeries     = KeyType("timeSeries")
+	TypeRateLimiter    = KeyType("rateLimiter")
+	DefaultScore       = float64(0)
+	ErrorRank          = -1
 
 	DefaultCleanDuration = time.Second
 	DefaultCleanItems    = 100
+	DefaultScanCount     = 10 // 游标遍历时每次默认检查的元素数量
+
+	minListCap    = 8         // 列表缓冲区的最小容量，需为2的幂
+	maxStringLen  = 512 << 20 // 字符串的最大长度，与Redis一致为512MB
+	zSetBatchSort = 1024      // 有序集合批量添加的元素达到该数量时，添加后统一排序
+
+	// Hash中field过期时间相关操作的结果
+	FieldNotExist     = -2 // field不存在
+	FieldNoExpiration = -1 // field没有设置过期时间
+	FieldExpireSet    = 1  // 设置过期时间成功
+	FieldPersisted    = 1  // 移除过期时间成功
+	FieldExpired      = 2  // 过期时间已过，field已被删除
 )
 
 // KeyType 键类型
 type KeyType string
+
+// Aggregate 有序集合运算时score的聚合方式
+type Aggregate string
+
+const (
+	AggregateSum = Aggregate("SUM")
+	AggregateMin = Aggregate("MIN")
+	AggregateMax = Aggregate("MAX")
+)
+
+// ListDirection 列表的方向
+type ListDirection string
+
+const (
+	ListLeft  = ListDirection("LEFT")
+	ListRight = ListDirection("RIGHT")
+)
+
+// ListPosition 列表插入元素的位置
+type ListPosition string
+
+const (
+	ListBefore = ListPosition("BEFORE")
+	ListAfter  = ListPosition("AFTER")
+)
+
+// SetMode 写入字符串或JSON时的条件
+type SetMode string
+
+const (
+	SetModeNX = SetMode("NX") // k或JSON路径不存在时才写入
+	SetModeXX = SetMode("XX") // k或JSON路径存在时才写入
+)
+
+// BitUnit 位图区间的单位
+type BitUnit string
+
+const (
+	BitUnitByte = BitUnit("BYTE")
+	BitUnitBit  = BitUnit("BIT")
+)
+
+// BitOp 位图之间的位运算
+type BitOp string
+
+const (
+	BitAnd = BitOp("AND")
+	BitOr  = BitOp("OR")
+	BitXor = BitOp("XOR")
+	BitNot = BitOp("NOT")
+)
+
+// BitFieldCmd BitField的子命令
+type BitFieldCmd string
+
+const (
+	BitFieldGet    = BitFieldCmd("GET")
+	BitFieldSet    = BitFieldCmd("SET")
+	BitFieldIncrBy = BitFieldCmd("INCRBY")
+)
+
+// BitOverflow BitField写入时溢出的处理方式
+type BitOverflow string
+
+const (
+	BitOverflowWrap = BitOverflow("WRAP") // 回绕，按位截断
+	BitOverflowSat  = BitOverflow("SAT")  // 饱和，取最大或最小值
+	BitOverflowFail = BitOverflow("FAIL") // 不写入，结果为nil
+)
+
+// GeoUnit 地理位置距离的单位
+type GeoUnit string
+
+const (
+	GeoUnitM  = GeoUnit("m")
+	GeoUnitKM = GeoUnit("km")
+	GeoUnitMI = GeoUnit("mi")
+	GeoUnitFT = GeoUnit("ft")
+)
+
+// GeoSort 地理位置搜索结果按距离排序的方式
+type GeoSort string
+
+const (
+	GeoSortAsc  = GeoSort("ASC")  // 由近到远
+	GeoSortDesc = GeoSort("DESC") // 由远到近
+)
+
+// TSDuplicatePolicy 时间序列中时间戳已存在时的处理方式
+type TSDuplicatePolicy string
+
+const (
+	TSDuplicateBlock = TSDuplicatePolicy("BLOCK") // 返回 ErrTSDuplicate
+	TSDuplicateFirst = TSDuplicatePolicy("FIRST") // 保留原有的值
+	TSDuplicateLast  = TSDuplicatePolicy("LAST")  // 使用新的值
+	TSDuplicateMin   = TSDuplicatePolicy("MIN")   // 保留较小的值
+	TSDuplicateMax   = TSDuplicatePolicy("MAX")   // 保留较大的值
+	TSDuplicateSum   = TSDuplicatePolicy("SUM")   // 使用两者之和
+)
+
+// TSAggregation 时间序列按时间桶聚合的方式
+type TSAggregation string
+
+const (
+	TSAggAvg   = TSAggregation("avg")
+	TSAggSum   = TSAggregation("sum")
+	TSAggMin   = TSAggregation("min")
+	TSAggMax   = TSAggregation("max")
+	TSAggCount = TSAggregation("count")
+	TSAggFirst = TSAggregation("first")
+	TSAggLast  = TSAggregation("last")
+)
+
+// RateLimitAlgorithm 限流算法
+type RateLimitAlgorithm string
+
+const (
+	RateLimitSlidingCounter = RateLimitAlgorithm("SLIDING_COUNTER") // 滑动窗口计数，按上一个窗口的比例估算，占用固定空间
+	RateLimitSlidingLog     = RateLimitAlgorithm("SLIDING_LOG")     // 滑动窗口日志，记录窗口内的每次请求，结果精确
+	RateLimitTokenBucket    = RateLimitAlgorithm("TOKEN_BUCKET")    // 令牌桶
+	RateLimitGCRA           = RateLimitAlgorithm("GCRA")            // 通用信元速率算法，与令牌桶等价，只记录一个时间
+)
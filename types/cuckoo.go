@@ -0,0 +1,479 @@
+package types
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// 布谷鸟过滤器的默认参数与RedisBloom一致
+const (
+	cuckooDefaultCapacity      = 1024               // CFAdd自动创建时的容量
+	cuckooDefaultBucketSize    = 2                  // 每个桶存储的指纹数量
+	cuckooDefaultMaxIterations = 20                 // 插入时最多踢出指纹的次数
+	cuckooDefaultExpansion     = 1                  // 扩容时新一层容量的倍数
+	cuckooMaxBucketSize        = 255                // 每个桶最多存储的指纹数量
+	cuckooMaxIterations        = 65535              // 最多踢出指纹的次数
+	cuckooMaxExpansion         = 32768              // 扩容倍数的最大值
+	cuckooSeed                 = 0xc6a4a7935bd1e995 // 计算hash的种子
+	cuckooAltHash              = 0x5bd1e995         // 计算备用桶时与指纹相乘的常数
+)
+
+// NewCuckooFilters 创建布谷鸟过滤器类型实例
+func NewCuckooFilters() *CuckooFilters {
+	return &CuckooFilters{
+		items: make(map[string]*CuckooFilter),
+	}
+}
+
+// CuckooFilters 布谷鸟过滤器类型数据结构
+type CuckooFilters struct {
+	mu    sync.Mutex
+	items map[string]*CuckooFilter
+}
+
+// CFReserveArgs 创建布谷鸟过滤器的参数
+// Capacity 为预计添加的元素数量；BucketSize 为每个桶存储的指纹数量，为0时为2
+// MaxIterations 为插入时最多踢出指纹的次数，为0时为20
+// Expansion 为空间不足时新一层容量的倍数，会向上取整为2的幂，为0时为1
+type CFReserveArgs struct {
+	Capacity      int64
+	BucketSize    int
+	MaxIterations int
+	Expansion     int
+}
+
+// CFInfo 布谷鸟过滤器的信息
+// Size 为占用的字节数，Buckets 为所有层的桶数量，Filters 为层数
+// Items 为当前的元素数量，Deleted 为删除过的元素数量
+type CFInfo struct {
+	Size          int
+	Buckets       int64
+	Filters       int
+	Items         int64
+	Deleted       int64
+	BucketSize    int
+	Expansion     int
+	MaxIterations int
+}
+
+// Exist 判断k是否存在
+func (cs *CuckooFilters) Exist(k string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.exist(k)
+}
+
+// exist 判断k是否存在
+func (cs *CuckooFilters) exist(k string) bool {
+	f, exist := cs.items[k]
+	if !exist {
+		return false
+	}
+	if f.isExpired() {
+		cs.del(k)
+		return false
+	}
+	return true
+}
+
+// CFReserve 按照args创建一个空的布谷鸟过滤器，k已存在时返回 ErrFilterExists
+func (cs *CuckooFilters) CFReserve(k string, args CFReserveArgs) error {
+	if args.BucketSize == 0 {
+		args.BucketSize = cuckooDefaultBucketSize
+	}
+	if args.MaxIterations == 0 {
+		args.MaxIterations = cuckooDefaultMaxIterations
+	}
+	if args.Expansion == 0 {
+		args.Expansion = cuckooDefaultExpansion
+	}
+	if args.Capacity <= 0 || args.BucketSize < 0 || args.BucketSize > cuckooMaxBucketSize ||
+		args.MaxIterations < 0 || args.MaxIterations > cuckooMaxIterations ||
+		args.Expansion < 0 || args.Expansion > cuckooMaxExpansion {
+		return ErrFilterArgs
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.exist(k) {
+		return ErrFilterExists
+	}
+	cs.items[k] = newCuckooFilter(args)
+	return nil
+}
+
+// CFAdd 向布谷鸟过滤器中添加一个元素，元素可以重复添加，k不存在时按默认参数创建
+// return exist bool 表示添加前k是否存在
+func (cs *CuckooFilters) CFAdd(k string, item any) (exist bool, err error) {
+	_, exist, err = cs.add(k, item, false)
+	return exist, err
+}
+
+// CFAddNX 元素可能已存在时不添加，k不存在时按默认参数创建
+// return added bool 表示是否添加了元素，exist bool 表示添加前k是否存在
+func (cs *CuckooFilters) CFAddNX(k string, item any) (added, exist bool, err error) {
+	return cs.add(k, item, true)
+}
+
+// CFExists 判断元素是否可能存在于布谷鸟过滤器中，返回false时一定不存在
+func (cs *CuckooFilters) CFExists(k string, item any) (bool, error) {
+	results, err := cs.CFMExists(k, item)
+	if err != nil {
+		return false, err
+	}
+	return results[0], nil
+}
+
+// CFMExists 判断多个元素是否可能存在于布谷鸟过滤器中
+func (cs *CuckooFilters) CFMExists(k string, items ...any) ([]bool, error) {
+	members, err := toMembers(items)
+	if err != nil {
+		return nil, err
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	results := make([]bool, len(members))
+	f := cs.get(k)
+	if f == nil {
+		return results, nil
+	}
+	for i, m := range members {
+		results[i] = f.count(cuckooHash(m)) > 0
+	}
+	return results, nil
+}
+
+// CFCount 获取元素在布谷鸟过滤器中可能的数量，结果不小于实际数量
+func (cs *CuckooFilters) CFCount(k string, item any) (int, error) {
+	m, err := toMember(item)
+	if err != nil {
+		return 0, err
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	f := cs.get(k)
+	if f == nil {
+		return 0, nil
+	}
+	return f.count(cuckooHash(m)), nil
+}
+
+// CFDel 从布谷鸟过滤器中删除一个元素，只能删除已添加过的元素，否则可能误删其他元素
+// return bool 表示是否找到并删除了元素
+func (cs *CuckooFilters) CFDel(k string, item any) (bool, error) {
+	m, err := toMember(item)
+	if err != nil {
+		return false, err
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	f := cs.get(k)
+	if f == nil {
+		return false, ErrFilterKey
+	}
+	return f.delete(cuckooHash(m)), nil
+}
+
+// CFInfo 获取布谷鸟过滤器的信息
+func (cs *CuckooFilters) CFInfo(k string) (*CFInfo, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	f := cs.get(k)
+	if f == nil {
+		return nil, ErrFilterKey
+	}
+	info := &CFInfo{
+		Filters:       len(f.layers),
+		Items:         f.items,
+		Deleted:       f.deleted,
+		BucketSize:    f.bucketSize,
+		Expansion:     f.expansion,
+		MaxIterations: f.maxIterations,
+	}
+	for _, l := range f.layers {
+		info.Size += len(l.buckets)
+		info.Buckets += int64(l.numBuckets)
+	}
+	return info, nil
+}
+
+// add 添加一个元素，nx 为true时元素可能已存在则不添加
+func (cs *CuckooFilters) add(k string, item any, nx bool) (added, exist bool, err error) {
+	m, err := toMember(item)
+	if err != nil {
+		return false, false, err
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	f := cs.get(k)
+	if exist = f != nil; !exist {
+		f = newCuckooFilter(CFReserveArgs{
+			Capacity:      cuckooDefaultCapacity,
+			BucketSize:    cuckooDefaultBucketSize,
+			MaxIterations: cuckooDefaultMaxIterations,
+			Expansion:     cuckooDefaultExpansion,
+		})
+		cs.items[k] = f
+	}
+	h, fp := cuckooHash(m)
+	if nx && f.count(h, fp) > 0 {
+		return false, exist, nil
+	}
+	f.insert(h, fp)
+	return true, exist, nil
+}
+
+// get 获取k对应的未过期布谷鸟过滤器，不存在时返回nil
+func (cs *CuckooFilters) get(k string) *CuckooFilter {
+	if !cs.exist(k) {
+		return nil
+	}
+	return cs.items[k]
+}
+
+// Del 删除一个key
+func (cs *CuckooFilters) Del(k string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.del(k)
+}
+
+func (cs *CuckooFilters) del(k string) {
+	delete(cs.items, k)
+}
+
+// Expiration 设置超时时间
+func (cs *CuckooFilters) Expiration(k string, d time.Duration) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if !cs.exist(k) {
+		return ErrKeyNotExist
+	}
+	cs.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (cs *CuckooFilters) ClearExpiration() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for key, item := range cs.items {
+		if item.isExpired() {
+			delete(cs.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (cs *CuckooFilters) RandomClearExpiration() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var counter int
+	for key, item := range cs.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(cs.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (cs *CuckooFilters) Flush() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.items = make(map[string]*CuckooFilter)
+}
+
+// newCuckooFilter 按照参数创建只有一层的布谷鸟过滤器
+func newCuckooFilter(args CFReserveArgs) *CuckooFilter {
+	numBuckets := uint64(args.Capacity+int64(args.BucketSize)-1) / uint64(args.BucketSize)
+	return &CuckooFilter{
+		layers:        []*cuckooLayer{newCuckooLayer(nextPowerOfTwo(numBuckets), args.BucketSize)},
+		bucketSize:    args.BucketSize,
+		maxIterations: args.MaxIterations,
+		expansion:     int(nextPowerOfTwo(uint64(args.Expansion))),
+		expiration:    DefaultExpiration,
+	}
+}
+
+// CuckooFilter 可扩容的布谷鸟过滤器，支持删除元素
+// 元素存储为8位的指纹，可以放在两个桶中的任意一个，桶已满时踢出已有的指纹到其备用桶
+// 踢出次数达到maxIterations时，新增一层桶数量为expansion倍的过滤器
+type CuckooFilter struct {
+	layers        []*cuckooLayer
+	bucketSize    int
+	maxIterations int
+	expansion     int
+	items         int64
+	deleted       int64
+	expiration    int64
+}
+
+// insert 插入指纹，优先放入任意一层的空位，否则在最后一层踢出已有的指纹
+func (f *CuckooFilter) insert(h uint64, fp uint8) {
+	f.items++
+	for _, l := range f.layers {
+		if l.insertEmpty(h, fp) {
+			return
+		}
+	}
+	last := f.layers[len(f.layers)-1]
+	if last.kick(h, fp, f.maxIterations) {
+		return
+	}
+	last = newCuckooLayer(last.numBuckets*uint64(f.expansion), f.bucketSize)
+	f.layers = append(f.layers, last)
+	last.insertEmpty(h, fp)
+}
+
+// count 获取指纹在所有层中出现的次数
+func (f *CuckooFilter) count(h uint64, fp uint8) int {
+	var n int
+	for _, l := range f.layers {
+		n += l.count(h, fp)
+	}
+	return n
+}
+
+// delete 从最新的一层开始查找并删除一个指纹
+func (f *CuckooFilter) delete(h uint64, fp uint8) bool {
+	for i := len(f.layers) - 1; i >= 0; i-- {
+		if f.layers[i].delete(h, fp) {
+			f.items--
+			f.deleted++
+			return true
+		}
+	}
+	return false
+}
+
+// isExpired 判断一个元素是否过期
+func (f *CuckooFilter) isExpired() bool {
+	if f.expiration != DefaultExpiration && time.Now().UnixNano() > f.expiration {
+		return true
+	}
+	return false
+}
+
+// newCuckooLayer 创建一层布谷鸟过滤器，numBuckets 需为2的幂
+func newCuckooLayer(numBuckets uint64, bucketSize int) *cuckooLayer {
+	return &cuckooLayer{
+		buckets:    make([]uint8, numBuckets*uint64(bucketSize)),
+		numBuckets: numBuckets,
+		bucketSize: uint64(bucketSize),
+	}
+}
+
+// cuckooLayer 布谷鸟过滤器的一层，buckets 中每bucketSize个指纹为一个桶，0表示空位
+type cuckooLayer struct {
+	buckets    []uint8
+	numBuckets uint64
+	bucketSize uint64
+}
+
+// index 获取元素的两个桶
+func (l *cuckooLayer) index(h uint64, fp uint8) (uint64, uint64) {
+	i := h & (l.numBuckets - 1)
+	return i, l.alt(i, fp)
+}
+
+// alt 获取指纹的备用桶，对备用桶再次计算会得到原来的桶
+func (l *cuckooLayer) alt(i uint64, fp uint8) uint64 {
+	return (i ^ uint64(fp)*cuckooAltHash) & (l.numBuckets - 1)
+}
+
+// bucket 获取第i个桶
+func (l *cuckooLayer) bucket(i uint64) []uint8 {
+	return l.buckets[i*l.bucketSize : (i+1)*l.bucketSize]
+}
+
+// insertEmpty 将指纹放入两个桶中的空位
+func (l *cuckooLayer) insertEmpty(h uint64, fp uint8) bool {
+	i1, i2 := l.index(h, fp)
+	return l.insertBucket(i1, fp) || l.insertBucket(i2, fp)
+}
+
+// insertBucket 将指纹放入第i个桶的空位
+func (l *cuckooLayer) insertBucket(i uint64, fp uint8) bool {
+	bucket := l.bucket(i)
+	for j := range bucket {
+		if bucket[j] == 0 {
+			bucket[j] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// kick 依次踢出已有的指纹放入其备用桶，直到找到空位
+// 达到最大次数时撤销所有踢出，保证已有的指纹不会丢失
+func (l *cuckooLayer) kick(h uint64, fp uint8, maxIterations int) bool {
+	i, _ := l.index(h, fp)
+	path := make([]uint64, 0, maxIterations)
+	for n := 0; n < maxIterations; n++ {
+		pos := i*l.bucketSize + uint64(n)%l.bucketSize
+		fp, l.buckets[pos] = l.buckets[pos], fp
+		path = append(path, pos)
+		i = l.alt(i, fp)
+		if l.insertBucket(i, fp) {
+			return true
+		}
+	}
+	for n := len(path) - 1; n >= 0; n-- {
+		fp, l.buckets[path[n]] = l.buckets[path[n]], fp
+	}
+	return false
+}
+
+// count 获取指纹在两个桶中出现的次数
+func (l *cuckooLayer) count(h uint64, fp uint8) int {
+	i1, i2 := l.index(h, fp)
+	n := l.countBucket(i1, fp)
+	if i2 != i1 {
+		n += l.countBucket(i2, fp)
+	}
+	return n
+}
+
+// countBucket 获取指纹在第i个桶中出现的次数
+func (l *cuckooLayer) countBucket(i uint64, fp uint8) int {
+	var n int
+	for _, v := range l.bucket(i) {
+		if v == fp {
+			n++
+		}
+	}
+	return n
+}
+
+// delete 从两个桶中删除一个指纹
+func (l *cuckooLayer) delete(h uint64, fp uint8) bool {
+	i1, i2 := l.index(h, fp)
+	for _, i := range []uint64{i1, i2} {
+		bucket := l.bucket(i)
+		for j := range bucket {
+			if bucket[j] == fp {
+				bucket[j] = 0
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cuckooHash 计算元素的hash和1~255的指纹
+func cuckooHash(m string) (uint64, uint8) {
+	h := murmurHash64A([]byte(m), cuckooSeed)
+	return h, uint8((h>>32)%255 + 1)
+}
+
+// nextPowerOfTwo 获取不小于n的最小的2的幂
+func nextPowerOfTwo(n uint64) uint64 {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len64(n-1)
+}
@@ -0,0 +1,127 @@
+package types
+
+// dictShrinkCap 元素切片超过该容量后，才会在元素减少时释放内存
+const dictShrinkCap = 64
+
+// newDict 创建一个字典的实例
+func newDict[V any]() *dict[V] {
+	return &dict[V]{
+		index: make(map[string]int),
+	}
+}
+
+// dict 支持随机访问和游标遍历的字典
+// entries 存储所有元素，删除时使用最后一个元素填补空位
+// index 记录每个key在entries中的位置
+type dict[V any] struct {
+	entries []dictEntry[V]
+	index   map[string]int
+}
+
+// dictEntry 字典中的一个元素
+type dictEntry[V any] struct {
+	key   string
+	value V
+}
+
+// get 获取key对应的值
+func (d *dict[V]) get(key string) (V, bool) {
+	if i, exist := d.index[key]; exist {
+		return d.entries[i].value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// has 判断key是否存在
+func (d *dict[V]) has(key string) bool {
+	_, exist := d.index[key]
+	return exist
+}
+
+// set 设置key对应的值
+// return bool 表示是否为新增的key
+func (d *dict[V]) set(key string, v V) bool {
+	if i, exist := d.index[key]; exist {
+		d.entries[i].value = v
+		return false
+	}
+	d.index[key] = len(d.entries)
+	d.entries = append(d.entries, dictEntry[V]{key: key, value: v})
+	return true
+}
+
+// del 删除key，使用最后一个元素填补其位置
+// return bool 表示key是否存在
+func (d *dict[V]) del(key string) bool {
+	i, exist := d.index[key]
+	if !exist {
+		return false
+	}
+	last := len(d.entries) - 1
+	if i != last {
+		d.entries[i] = d.entries[last]
+		d.index[d.entries[i].key] = i
+	}
+	d.entries[last] = dictEntry[V]{}
+	d.entries = d.entries[:last]
+	delete(d.index, key)
+	// 元素数量远小于容量时，释放多余的内存，元素的顺序保持不变
+	if cap(d.entries) > dictShrinkCap && len(d.entries) < cap(d.entries)/4 {
+		entries := make([]dictEntry[V], len(d.entries), len(d.entries)*2)
+		copy(entries, d.entries)
+		d.entries = entries
+	}
+	return true
+}
+
+// len 获取元素数量
+func (d *dict[V]) len() int {
+	return len(d.entries)
+}
+
+// at 获取位置i上的元素，i需在[0, len)范围内
+func (d *dict[V]) at(i int) *dictEntry[V] {
+	return &d.entries[i]
+}
+
+// keys 获取所有的key，返回的切片为副本
+func (d *dict[V]) keys() []string {
+	keys := make([]string, len(d.entries))
+	for i := range d.entries {
+		keys[i] = d.entries[i].key
+	}
+	return keys
+}
+
+// scan 从游标位置开始，由后向前遍历最多count个位置上的元素
+// 游标为尚未遍历的位置数量，0表示从头开始；返回0表示遍历结束
+// 新增的元素总在尾部，删除时只会把已遍历的元素移动到未遍历的位置，
+// 因此遍历期间一直存在的元素至少会被返回一次
+func (d *dict[V]) scan(cursor uint64, count int, fn func(e *dictEntry[V])) uint64 {
+	pos := len(d.entries)
+	if cursor != 0 && cursor < uint64(pos) {
+		pos = int(cursor)
+	}
+	end := pos - count
+	if end < 0 {
+		end = 0
+	}
+	for i := pos - 1; i >= end; i-- {
+		fn(&d.entries[i])
+	}
+	return uint64(end)
+}
+
+// scanMatch 按游标遍历字典，只返回匹配pattern的元素，pattern为空时不过滤
+// count 小于等于0时使用 DefaultScanCount
+func (d *dict[V]) scanMatch(cursor uint64, pattern string, count int, fn func(e *dictEntry[V])) uint64 {
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+	return d.scan(cursor, count, func(e *dictEntry[V]) {
+		if pattern == "" || matchPattern(pattern, e.key) {
+			fn(e)
+		}
+	})
+}
@@ -3,11 +3,63 @@ package types
 import "errors"
 
 var (
-	ErrKeyNotExist = errors.New("key not exist")
-	ErrEmptyList   = errors.New("list is empty")
-	ErrStartStop   = errors.New("start or stop is invalid")
-	ErrHashKey     = errors.New("hash key is not exist")
-	ErrHashField   = errors.New("hash field is not exist")
-	ErrSetKey      = errors.New("set key is not exist")
-	ErrZSetKey     = errors.New("zset key is not exist")
+	ErrKeyNotExist  = errors.New("key not exist")
+	ErrEmptyList    = errors.New("list is empty")
+	ErrStartStop    = errors.New("start or stop is invalid")
+	ErrTimeout      = errors.New("wait timeout")
+	ErrIndex        = errors.New("index out of range")
+	ErrPosition     = errors.New("position must be BEFORE or AFTER")
+	ErrNotString    = errors.New("value is not a string or []byte")
+	ErrSetMode      = errors.New("set mode must be NX or XX")
+	ErrExpireArgs   = errors.New("expire arguments are invalid")
+	ErrOffset       = errors.New("offset is out of range")
+	ErrBitValue     = errors.New("bit is not an integer or out of range")
+	ErrBitUnit      = errors.New("bit unit must be BYTE or BIT")
+	ErrBitOp        = errors.New("bitop is invalid or NOT has more than one key")
+	ErrBitField     = errors.New("bitfield command, type or overflow is invalid")
+	ErrHashKey      = errors.New("hash key is not exist")
+	ErrHashField    = errors.New("hash field is not exist")
+	ErrNotInteger   = errors.New("value is not an integer or out of range")
+	ErrNotFloat     = errors.New("value is not a valid float")
+	ErrOverflow     = errors.New("increment or decrement would overflow")
+	ErrSetKey       = errors.New("set key is not exist")
+	ErrCount        = errors.New("count is out of range")
+	ErrMemberType   = errors.New("member must be string, []byte, number or bool")
+	ErrZSetKey      = errors.New("zset key is not exist")
+	ErrZStoreKeys   = errors.New("at least one key is required")
+	ErrWeights      = errors.New("weights count is not equal to keys count")
+	ErrAggregate    = errors.New("aggregate is invalid")
+	ErrCoordinate   = errors.New("longitude or latitude is out of range")
+	ErrGeoUnit      = errors.New("unit must be m, km, mi or ft")
+	ErrGeoMember    = errors.New("geo member is not exist")
+	ErrGeoArgs      = errors.New("geo search shape, sort or count is invalid")
+	ErrFilterKey    = errors.New("filter key is not exist")
+	ErrFilterExists = errors.New("filter key already exists")
+	ErrFilterArgs   = errors.New("filter error rate, capacity or expansion is invalid")
+	ErrFilterFull   = errors.New("non scaling filter is full")
+	ErrSketchKey    = errors.New("sketch key is not exist")
+	ErrSketchExists = errors.New("sketch key already exists")
+	ErrSketchArgs   = errors.New("sketch dimensions, probability or increment is invalid")
+	ErrSketchDims   = errors.New("sketch width or depth is not equal")
+	ErrStreamKey    = errors.New("stream key is not exist")
+	ErrStreamID     = errors.New("stream id is invalid or not greater than the last id")
+	ErrStreamArgs   = errors.New("stream keys count is not equal to ids count")
+	ErrValues       = errors.New("at least one field value is required")
+	ErrGroupExists  = errors.New("consumer group already exists")
+	ErrNoGroup      = errors.New("stream key or consumer group is not exist")
+	ErrJSONKey      = errors.New("json key is not exist or new key is not set at the root path")
+	ErrJSONPath     = errors.New("json path is invalid or does not exist")
+	ErrJSONValue    = errors.New("value is not valid json")
+	ErrTSKey        = errors.New("time series key is not exist")
+	ErrTSExists     = errors.New("time series key already exists")
+	ErrTSArgs       = errors.New("time series timestamp, retention, duplicate policy or aggregation is invalid")
+	ErrTSDuplicate  = errors.New("timestamp already exists and duplicate policy is BLOCK")
+	ErrTSRetention  = errors.New("timestamp is older than retention")
+	ErrTSRule       = errors.New("compaction rule is invalid or already exists")
+	ErrTSFilter     = errors.New("label filter is invalid or has no label=value matcher")
+	ErrRateLimit    = errors.New("rate limit, window, rate, burst, n or algorithm is invalid")
+	ErrLocked       = errors.New("lock is held by another owner")
+	ErrLockNotHeld  = errors.New("lock is not held by the lease")
+	ErrNoLoader     = errors.New("no loader is given or configured")
+	ErrLoaderPanic  = errors.New("loader panicked")
 )
@@ -0,0 +1,489 @@
+package types
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	geoStep         = 26           // geohash 每个维度的精度，经纬度共52位，可以精确存储为score
+	geoLatMin       = -85.05112878 // 墨卡托投影的纬度范围，与redis一致
+	geoLatMax       = 85.05112878
+	geoLonMin       = -180.0
+	geoLonMax       = 180.0
+	geoEarthRadius  = 6372797.560856 // 地球半径，单位为米，与redis一致
+	geoMaxCells     = 9              // 范围搜索时最多检查的geohash区域数量
+	geoHashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// GeoLocation 成员的地理位置
+type GeoLocation struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoSearchArgs GeoSearch的参数
+// Member 不为空时以该成员的位置为中心，否则以Longitude、Latitude为中心
+// Radius 大于0时按圆形区域搜索，否则按宽为Width、高为Height的矩形区域搜索，单位为Unit
+// Sort 为结果按距离排序的方式，Count 大于0时最多返回Count个结果，未指定Sort时取最近的结果
+// Any 为true时找到Count个结果即返回，不保证是最近的
+// WithDist、WithCoord 为true时结果包含距离和坐标
+type GeoSearchArgs struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+	Radius    float64
+	Width     float64
+	Height    float64
+	Unit      GeoUnit
+	Sort      GeoSort
+	Count     int
+	Any       bool
+	WithDist  bool
+	WithCoord bool
+}
+
+// GeoSearchResult GeoSearch的结果，Dist 为到中心的距离，单位与搜索时的单位相同
+type GeoSearchResult struct {
+	Member    string
+	Dist      float64
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoAdd 向有序集合中添加成员的地理位置，score为位置的geohash
+// return added int 为新添加的成员数量，exist bool 表示添加前key是否存在
+func (zs *ZSets) GeoAdd(key string, locations ...GeoLocation) (added int, exist bool, err error) {
+	for _, l := range locations {
+		if !geoValid(l.Longitude, l.Latitude) {
+			return 0, false, ErrCoordinate
+		}
+	}
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	exist = zs.exist(key)
+	if len(locations) == 0 {
+		return 0, exist, nil
+	}
+	z := newZSet()
+	if exist {
+		z = zs.items[key]
+	}
+	members := make([]string, len(locations))
+	scores := make([]float64, len(locations))
+	for i, l := range locations {
+		if !z.elements.has(l.Member) {
+			added++
+		}
+		members[i], scores[i] = l.Member, float64(geoEncode(l.Longitude, l.Latitude))
+	}
+	z.addMany(members, scores)
+	zs.items[key] = z
+	return added, exist, nil
+}
+
+// GeoPos 获取成员的地理位置，成员不存在时对应的位置为nil
+func (zs *ZSets) GeoPos(key string, members ...string) []*GeoLocation {
+	zs.mu.RLock()
+	defer zs.mu.RUnlock()
+	z := zs.lookup(key)
+	locations := make([]*GeoLocation, len(members))
+	for i, m := range members {
+		if z == nil {
+			continue
+		}
+		if score, exist := z.elements.get(m); exist {
+			lon, lat := geoDecode(uint64(score))
+			locations[i] = &GeoLocation{Member: m, Longitude: lon, Latitude: lat}
+		}
+	}
+	return locations
+}
+
+// GeoDist 获取两个成员之间的距离
+func (zs *ZSets) GeoDist(key, member1, member2 string, unit GeoUnit) (float64, error) {
+	meters, err := unit.meters()
+	if err != nil {
+		return 0, err
+	}
+	zs.mu.RLock()
+	defer zs.mu.RUnlock()
+	z := zs.lookup(key)
+	if z == nil {
+		return 0, ErrGeoMember
+	}
+	score1, exist1 := z.elements.get(member1)
+	score2, exist2 := z.elements.get(member2)
+	if !exist1 || !exist2 {
+		return 0, ErrGeoMember
+	}
+	lon1, lat1 := geoDecode(uint64(score1))
+	lon2, lat2 := geoDecode(uint64(score2))
+	return geoDistance(lon1, lat1, lon2, lat2) / meters, nil
+}
+
+// GeoHash 获取成员位置的11位geohash字符串，成员不存在时为空字符串
+func (zs *ZSets) GeoHash(key string, members ...string) []string {
+	zs.mu.RLock()
+	defer zs.mu.RUnlock()
+	z := zs.lookup(key)
+	hashes := make([]string, len(members))
+	for i, m := range members {
+		if z == nil {
+			continue
+		}
+		if score, exist := z.elements.get(m); exist {
+			hashes[i] = geoHashString(uint64(score))
+		}
+	}
+	return hashes
+}
+
+// GeoSearch 搜索圆形或矩形区域内的成员
+func (zs *ZSets) GeoSearch(key string, args *GeoSearchArgs) ([]GeoSearchResult, error) {
+	meters, err := args.validate()
+	if err != nil {
+		return nil, err
+	}
+	zs.mu.RLock()
+	defer zs.mu.RUnlock()
+	points, err := zs.geoSearch(key, args, meters)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]GeoSearchResult, len(points))
+	for i, p := range points {
+		results[i].Member = p.member
+		if args.WithDist {
+			results[i].Dist = p.dist / meters
+		}
+		if args.WithCoord {
+			results[i].Longitude, results[i].Latitude = p.lon, p.lat
+		}
+	}
+	return results, nil
+}
+
+// GeoSearchStore 搜索区域内的成员，并存储到有序集合dst中，结果为空时删除dst
+// storeDist 为true时score为到中心的距离，否则为位置的geohash
+// return int 为dst中成员的数量
+func (zs *ZSets) GeoSearchStore(dst, src string, args *GeoSearchArgs, storeDist bool) (int, error) {
+	meters, err := args.validate()
+	if err != nil {
+		return 0, err
+	}
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	points, err := zs.geoSearch(src, args, meters)
+	if err != nil {
+		return 0, err
+	}
+	elements := make(map[string]float64, len(points))
+	for _, p := range points {
+		if storeDist {
+			elements[p.member] = p.dist / meters
+		} else {
+			elements[p.member] = p.score
+		}
+	}
+	return zs.store(dst, elements), nil
+}
+
+// geoPoint 搜索到的成员，dist 单位为米
+type geoPoint struct {
+	member   string
+	score    float64
+	lon, lat float64
+	dist     float64
+}
+
+// geoSearch 搜索区域内的成员，并按照args排序和截取
+func (zs *ZSets) geoSearch(key string, args *GeoSearchArgs, meters float64) ([]geoPoint, error) {
+	z := zs.lookup(key)
+	shape := &geoShape{
+		lon:    args.Longitude,
+		lat:    args.Latitude,
+		radius: args.Radius * meters,
+		width:  args.Width * meters,
+		height: args.Height * meters,
+	}
+	if args.Member != "" {
+		if z == nil {
+			return nil, ErrGeoMember
+		}
+		score, exist := z.elements.get(args.Member)
+		if !exist {
+			return nil, ErrGeoMember
+		}
+		shape.lon, shape.lat = geoDecode(uint64(score))
+	}
+	points := make([]geoPoint, 0)
+	if z == nil {
+		return points, nil
+	}
+	for _, r := range shape.ranges() {
+		z.rangeByScore(float64(r[0]), float64(r[1]), func(e string, score float64) bool {
+			lon, lat := geoDecode(uint64(score))
+			if dist, ok := shape.contains(lon, lat); ok {
+				points = append(points, geoPoint{member: e, score: score, lon: lon, lat: lat, dist: dist})
+			}
+			return !args.Any || len(points) < args.Count
+		})
+		if args.Any && len(points) >= args.Count {
+			break
+		}
+	}
+	sortBy := args.Sort
+	if sortBy == "" && args.Count > 0 && !args.Any {
+		sortBy = GeoSortAsc
+	}
+	switch sortBy {
+	case GeoSortAsc:
+		sort.Slice(points, func(i, j int) bool { return points[i].dist < points[j].dist })
+	case GeoSortDesc:
+		sort.Slice(points, func(i, j int) bool { return points[i].dist > points[j].dist })
+	}
+	if args.Count > 0 && len(points) > args.Count {
+		points = points[:args.Count]
+	}
+	return points, nil
+}
+
+// validate 校验搜索参数，并返回单位对应的米数
+func (args *GeoSearchArgs) validate() (float64, error) {
+	meters, err := args.Unit.meters()
+	if err != nil {
+		return 0, err
+	}
+	if args.Radius <= 0 && (args.Width <= 0 || args.Height <= 0) {
+		return 0, ErrGeoArgs
+	}
+	if args.Sort != "" && args.Sort != GeoSortAsc && args.Sort != GeoSortDesc {
+		return 0, ErrGeoArgs
+	}
+	if args.Count < 0 || (args.Any && args.Count == 0) {
+		return 0, ErrGeoArgs
+	}
+	if args.Member == "" && !geoValid(args.Longitude, args.Latitude) {
+		return 0, ErrCoordinate
+	}
+	return meters, nil
+}
+
+// meters 获取单位对应的米数，未指定时为米
+func (u GeoUnit) meters() (float64, error) {
+	switch u {
+	case "", GeoUnitM:
+		return 1, nil
+	case GeoUnitKM:
+		return 1000, nil
+	case GeoUnitMI:
+		return 1609.34, nil
+	case GeoUnitFT:
+		return 0.3048, nil
+	}
+	return 0, ErrGeoUnit
+}
+
+// geoShape 搜索的圆形或矩形区域，中心为lon、lat，长度单位为米
+type geoShape struct {
+	lon, lat      float64
+	radius        float64
+	width, height float64
+}
+
+// contains 判断位置是否在区域内，并返回到中心的距离
+// 矩形区域与redis一致，在位置所在的纬度上计算经度方向的距离
+func (s *geoShape) contains(lon, lat float64) (float64, bool) {
+	if s.radius > 0 {
+		dist := geoDistance(s.lon, s.lat, lon, lat)
+		return dist, dist <= s.radius
+	}
+	if geoEarthRadius*math.Abs(geoRad(lat)-geoRad(s.lat)) > s.height/2 {
+		return 0, false
+	}
+	if geoDistance(s.lon, lat, lon, lat) > s.width/2 {
+		return 0, false
+	}
+	return geoDistance(s.lon, s.lat, lon, lat), true
+}
+
+// bounds 获取包含区域的纬度范围，以及中心两侧的经度差，经度差为180时包含所有经度
+func (s *geoShape) bounds() (minLat, maxLat, lonDelta float64) {
+	halfHeight, halfWidth := s.radius, s.radius
+	if s.radius <= 0 {
+		halfHeight, halfWidth = s.height/2, s.width/2
+	}
+	latDelta := geoDeg(halfHeight / geoEarthRadius)
+	minLat, maxLat = math.Max(s.lat-latDelta, geoLatMin), math.Min(s.lat+latDelta, geoLatMax)
+	if s.lat-latDelta <= -90 || s.lat+latDelta >= 90 {
+		return minLat, maxLat, 180
+	}
+	var x float64
+	if s.radius > 0 {
+		// 球冠的最大经度差
+		if halfWidth/geoEarthRadius >= math.Pi/2 {
+			return minLat, maxLat, 180
+		}
+		x = math.Sin(halfWidth/geoEarthRadius) / math.Cos(geoRad(s.lat))
+	} else {
+		// 距离两极最近的纬度上经度差最大
+		polar := math.Max(math.Abs(s.lat-latDelta), math.Abs(s.lat+latDelta))
+		x = math.Sin(halfWidth/(2*geoEarthRadius)) / math.Cos(geoRad(polar))
+	}
+	if x >= 1 {
+		return minLat, maxLat, 180
+	}
+	if s.radius > 0 {
+		return minLat, maxLat, geoDeg(math.Asin(x))
+	}
+	return minLat, maxLat, geoDeg(2 * math.Asin(x))
+}
+
+// ranges 获取覆盖区域的geohash区间，每个区间为score的闭区间
+// 选择覆盖区域的格子数量不超过 geoMaxCells 的最高精度，相邻的区间会合并
+func (s *geoShape) ranges() [][2]uint64 {
+	minLat, maxLat, lonDelta := s.bounds()
+	for step := geoStep; ; step-- {
+		cells := uint64(1) << step
+		latFrom := geoCell(minLat, geoLatMin, geoLatMax, cells)
+		latTo := geoCell(maxLat, geoLatMin, geoLatMax, cells)
+		lonRanges := geoLonCells(s.lon, lonDelta, cells)
+		var count uint64
+		for _, r := range lonRanges {
+			count += r[1] - r[0] + 1
+		}
+		count *= latTo - latFrom + 1
+		if count > geoMaxCells && step > 1 {
+			continue
+		}
+		shift := 2 * (geoStep - step)
+		ranges := make([][2]uint64, 0, count)
+		for i := latFrom; i <= latTo; i++ {
+			for _, r := range lonRanges {
+				for j := r[0]; j <= r[1]; j++ {
+					hash := geoInterleave(i, j)
+					ranges = append(ranges, [2]uint64{hash << shift, (hash+1)<<shift - 1})
+				}
+			}
+		}
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+		merged := ranges[:1]
+		for _, r := range ranges[1:] {
+			if last := &merged[len(merged)-1]; r[0] == last[1]+1 {
+				last[1] = r[1]
+			} else {
+				merged = append(merged, r)
+			}
+		}
+		return merged
+	}
+}
+
+// geoLonCells 获取经度范围覆盖的格子区间，跨越180度经线时分为两段
+func geoLonCells(lon, lonDelta float64, cells uint64) [][2]uint64 {
+	if lonDelta >= 180 {
+		return [][2]uint64{{0, cells - 1}}
+	}
+	size := (geoLonMax - geoLonMin) / float64(cells)
+	from := int64(math.Floor((lon - lonDelta - geoLonMin) / size))
+	to := int64(math.Floor((lon + lonDelta - geoLonMin) / size))
+	n := int64(cells)
+	switch {
+	case to-from+1 >= n:
+		return [][2]uint64{{0, cells - 1}}
+	case from < 0:
+		return [][2]uint64{{uint64(from + n), cells - 1}, {0, uint64(to)}}
+	case to >= n:
+		return [][2]uint64{{uint64(from), cells - 1}, {0, uint64(to - n)}}
+	}
+	return [][2]uint64{{uint64(from), uint64(to)}}
+}
+
+// geoCell 获取坐标v所在的格子序号
+func geoCell(v, min, max float64, cells uint64) uint64 {
+	i := (v - min) / (max - min) * float64(cells)
+	if i < 0 {
+		return 0
+	}
+	if i >= float64(cells) {
+		return cells - 1
+	}
+	return uint64(i)
+}
+
+// geoValid 判断经纬度是否在可以编码的范围内
+func geoValid(lon, lat float64) bool {
+	return lon >= geoLonMin && lon <= geoLonMax && lat >= geoLatMin && lat <= geoLatMax
+}
+
+// geoEncode 将经纬度编码为52位的geohash
+func geoEncode(lon, lat float64) uint64 {
+	return geoEncodeRange(lon, lat, geoLatMin, geoLatMax)
+}
+
+// geoEncodeRange 按纬度范围[latMin, latMax]将经纬度编码为52位的geohash
+// 纬度在偶数位，经度在奇数位
+func geoEncodeRange(lon, lat, latMin, latMax float64) uint64 {
+	cells := uint64(1) << geoStep
+	return geoInterleave(geoCell(lat, latMin, latMax, cells), geoCell(lon, geoLonMin, geoLonMax, cells))
+}
+
+// geoDecode 将geohash解码为所在格子中心的经纬度
+func geoDecode(hash uint64) (lon, lat float64) {
+	latCell, lonCell := geoDeinterleave(hash)
+	cells := float64(uint64(1) << geoStep)
+	lat = geoLatMin + (float64(latCell)+0.5)/cells*(geoLatMax-geoLatMin)
+	lon = geoLonMin + (float64(lonCell)+0.5)/cells*(geoLonMax-geoLonMin)
+	return math.Max(math.Min(lon, geoLonMax), geoLonMin), math.Max(math.Min(lat, geoLatMax), geoLatMin)
+}
+
+// geoHashString 获取geohash对应的标准geohash字符串
+// 与redis一致，按标准纬度范围[-90, 90]重新编码，52位只能表示10个字符，最后一位补0
+func geoHashString(hash uint64) string {
+	lon, lat := geoDecode(hash)
+	bits := geoEncodeRange(lon, lat, -90, 90)
+	buf := make([]byte, 11)
+	for i := 0; i < 10; i++ {
+		buf[i] = geoHashAlphabet[bits>>(2*geoStep-(i+1)*5)&0x1f]
+	}
+	buf[10] = geoHashAlphabet[0]
+	return string(buf)
+}
+
+// geoInterleave 交错合并x和y的低26位，x在偶数位，y在奇数位
+func geoInterleave(x, y uint64) uint64 {
+	var bits uint64
+	for i := 0; i < geoStep; i++ {
+		bits |= (x>>i&1)<<(2*i) | (y>>i&1)<<(2*i+1)
+	}
+	return bits
+}
+
+// geoDeinterleave 拆分交错合并的x和y
+func geoDeinterleave(bits uint64) (x, y uint64) {
+	for i := 0; i < geoStep; i++ {
+		x |= (bits >> (2 * i) & 1) << i
+		y |= (bits >> (2*i + 1) & 1) << i
+	}
+	return x, y
+}
+
+// geoDistance 使用haversine公式计算两个位置之间的距离，单位为米
+func geoDistance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lat2r := geoRad(lat1), geoRad(lat2)
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin(geoRad(lon2-lon1) / 2)
+	return 2 * geoEarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
+
+// geoRad 角度转换为弧度
+func geoRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// geoDeg 弧度转换为角度
+func geoDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
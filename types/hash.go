@@ -1,6 +1,7 @@
 package types
 
 import (
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -22,7 +23,7 @@ type Hashes struct {
 func (hs *Hashes) Exist(k string) bool {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	return hs.exist(k)
+	return hs.get(k) != nil
 }
 
 // exist 判断k是否存在
@@ -32,7 +33,7 @@ func (hs *Hashes) exist(k string) bool {
 		return false
 	}
 	if i.isExpired() {
-		hs.Del(k)
+		hs.del(k)
 		return false
 	}
 	return true
@@ -50,48 +51,246 @@ func (hs *Hashes) HSet(k, field string, v any) bool {
 
 // hSet -
 func (hs *Hashes) hSet(k, field string, v any) bool {
-	h, exist := hs.items[k]
-	if !exist {
-		h = newHash()
-	}
+	h, exist := hs.getOrCreate(k)
 	h.HSet(field, v)
-	hs.items[k] = h
 	return exist
 }
 
+// HMSet 批量缓存数据到Hash中
+// return added int 为新增field的数量，exist bool 表示存储前k是否存在
+func (hs *Hashes) HMSet(k string, values map[string]any) (added int, exist bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(values) == 0 {
+		return 0, hs.exist(k)
+	}
+	h, exist := hs.getOrCreate(k)
+	for field, v := range values {
+		if !h.Exist(field) {
+			added++
+		}
+		h.HSet(field, v)
+	}
+	return added, exist
+}
+
+// HSetNX field不存在时，缓存数据到Hash中
+// return set bool 表示是否写入，exist bool 表示存储前k是否存在
+func (hs *Hashes) HSetNX(k, field string, v any) (set, exist bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h, exist := hs.getOrCreate(k)
+	if h.Exist(field) {
+		return false, exist
+	}
+	h.HSet(field, v)
+	return true, exist
+}
+
 // HGet 从Hash中获取存储的元素
 func (hs *Hashes) HGet(k, field string) (any, error) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	h, exist := hs.items[k]
+	h := hs.get(k)
+	if h == nil {
+		return nil, ErrHashKey
+	}
+	return h.HGet(field)
+}
+
+// HMGet 从Hash中批量获取存储的元素，不存在的field对应的值为nil
+func (hs *Hashes) HMGet(k string, fields ...string) ([]any, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
+		return nil, ErrHashKey
+	}
+	vals := make([]any, len(fields))
+	for i, field := range fields {
+		vals[i], _ = h.HGet(field)
+	}
+	return vals, nil
+}
+
+// HGetAll 获取Hash中所有的field和内容，返回的map为副本
+func (hs *Hashes) HGetAll(k string) (map[string]any, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
+		return nil, ErrHashKey
+	}
+	return h.HGetAll(), nil
+}
+
+// HLen 获取Hash中field的数量
+func (hs *Hashes) HLen(k string) int {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
+		return 0
+	}
+	return h.HLen()
+}
+
+// HStrLen 获取Hash中field的值的长度，k或field不存在时返回0
+func (hs *Hashes) HStrLen(k, field string) (int, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
+		return 0, nil
+	}
+	return h.HStrLen(field)
+}
+
+// HIncrBy 对Hash中field的计数+incr，field不存在时从0开始
+// return exist bool 表示操作前k是否存在
+func (hs *Hashes) HIncrBy(k, field string, incr int64) (int64, bool, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	exist := h != nil
 	if !exist {
+		h = newHash()
+	}
+	n, err := h.HIncrBy(field, incr)
+	if err != nil {
+		return 0, exist, err
+	}
+	hs.items[k] = h
+	return n, exist, nil
+}
+
+// HIncrByFloat 对Hash中field的浮点数计数+incr，field不存在时从0开始
+// return exist bool 表示操作前k是否存在
+func (hs *Hashes) HIncrByFloat(k, field string, incr float64) (float64, bool, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	exist := h != nil
+	if !exist {
+		h = newHash()
+	}
+	f, err := h.HIncrByFloat(field, incr)
+	if err != nil {
+		return 0, exist, err
+	}
+	hs.items[k] = h
+	return f, exist, nil
+}
+
+// HRandField 从Hash中随机获取count个field
+// count 为正数时返回不重复的field，count 为负数时field可能重复
+func (hs *Hashes) HRandField(k string, count int) ([]string, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
 		return nil, ErrHashKey
-	} else if h.isExpired() {
-		hs.Del(k)
+	}
+	return h.HRandField(count), nil
+}
+
+// HDel 从Hash中删除多个field，Hash为空时删除k
+// return int 为删除的field数量
+func (hs *Hashes) HDel(k string, fields ...string) int {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
+		return 0
+	}
+	var removed int
+	for _, field := range fields {
+		if h.Exist(field) {
+			h.HDel(field)
+			removed++
+		}
+	}
+	if h.HLen() == 0 {
+		hs.del(k)
+	}
+	return removed
+}
+
+// HExpireAt 设置Hash中多个field的过期时间，at为UnixNano
+// 返回每个field的结果：FieldNotExist、FieldExpireSet 或 FieldExpired
+// 所有field都已删除时删除k
+func (hs *Hashes) HExpireAt(k string, at int64, fields ...string) ([]int, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
 		return nil, ErrHashKey
 	}
-	return h.HGet(field)
+	result := make([]int, len(fields))
+	for i, field := range fields {
+		result[i] = h.HExpireAt(field, at)
+	}
+	if h.HLen() == 0 {
+		hs.del(k)
+	}
+	return result, nil
 }
 
-// HDel 从Hash中删除元素field
-func (hs *Hashes) HDel(k, field string) {
+// HTTL 获取Hash中多个field的剩余生存时间，单位为毫秒
+// field不存在时为 FieldNotExist，没有过期时间时为 FieldNoExpiration
+func (hs *Hashes) HTTL(k string, fields ...string) ([]int64, error) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	h, exist := hs.items[k]
-	if exist {
-		h.HDel(field)
+	h := hs.get(k)
+	if h == nil {
+		return nil, ErrHashKey
+	}
+	result := make([]int64, len(fields))
+	for i, field := range fields {
+		result[i] = h.HTTL(field)
 	}
+	return result, nil
+}
+
+// HPersist 移除Hash中多个field的过期时间
+// 返回每个field的结果：FieldNotExist、FieldNoExpiration 或 FieldPersisted
+func (hs *Hashes) HPersist(k string, fields ...string) ([]int, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
+		return nil, ErrHashKey
+	}
+	result := make([]int, len(fields))
+	for i, field := range fields {
+		result[i] = h.HPersist(field)
+	}
+	return result, nil
+}
+
+// HScan 按游标遍历Hash中的field和内容
+// match 为field需匹配的glob模式，为空时不过滤；count 为本次检查的field数量
+// 返回本次遍历到的field和内容，以及下一次的游标，游标为0表示遍历结束
+func (hs *Hashes) HScan(k string, cursor uint64, match string, count int) (map[string]any, uint64, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if h == nil {
+		return nil, 0, ErrHashKey
+	}
+	result := make(map[string]any)
+	next := h.fields.scanMatch(cursor, match, count, func(e *dictEntry[any]) {
+		result[e.key] = e.value
+	})
+	return result, next, nil
 }
 
 // HKeys 获取Hash中的所有元素field
 func (hs *Hashes) HKeys(k string) ([]string, error) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	h, exist := hs.items[k]
-	if !exist {
-		return nil, ErrHashKey
-	} else if h.isExpired() {
-		hs.Del(k)
+	h := hs.get(k)
+	if h == nil {
 		return nil, ErrHashKey
 	}
 	return h.HKeys()
@@ -101,20 +300,46 @@ func (hs *Hashes) HKeys(k string) ([]string, error) {
 func (hs *Hashes) HVals(k string) ([]any, error) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	h, exist := hs.items[k]
-	if !exist {
-		return nil, ErrHashKey
-	} else if h.isExpired() {
-		hs.Del(k)
+	h := hs.get(k)
+	if h == nil {
 		return nil, ErrHashKey
 	}
 	return h.HVals()
 }
 
+// get 获取k对应的未过期Hash，不存在时返回nil
+// 同时清理已过期的field，所有field都过期时删除k
+func (hs *Hashes) get(k string) *Hash {
+	if !hs.exist(k) {
+		return nil
+	}
+	h := hs.items[k]
+	if h.clearExpiredFields() > 0 && h.HLen() == 0 {
+		hs.del(k)
+		return nil
+	}
+	return h
+}
+
+// getOrCreate 获取k对应的未过期Hash，不存在时创建
+// return exist bool 表示k原本是否存在
+func (hs *Hashes) getOrCreate(k string) (*Hash, bool) {
+	if h := hs.get(k); h != nil {
+		return h, true
+	}
+	h := newHash()
+	hs.items[k] = h
+	return h, false
+}
+
 // Del 删除一个key
 func (hs *Hashes) Del(k string) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
+	hs.del(k)
+}
+
+func (hs *Hashes) del(k string) {
 	delete(hs.items, k)
 }
 
@@ -136,6 +361,8 @@ func (hs *Hashes) ClearExpiration() {
 	for key, item := range hs.items {
 		if item.isExpired() {
 			delete(hs.items, key)
+		} else if item.clearExpiredFields() > 0 && item.HLen() == 0 {
+			delete(hs.items, key)
 		}
 	}
 }
@@ -151,6 +378,8 @@ func (hs *Hashes) RandomClearExpiration() {
 		}
 		if item.isExpired() {
 			delete(hs.items, key)
+		} else if item.clearExpiredFields() > 0 && item.HLen() == 0 {
+			delete(hs.items, key)
 		}
 		counter++
 	}
@@ -166,59 +395,210 @@ func (hs *Hashes) Flush() {
 // newHash 创建一个Hash的实例
 func newHash() *Hash {
 	return &Hash{
-		fields:     make(map[string]any),
+		fields:     newDict[any](),
 		expiration: DefaultExpiration,
 	}
 }
 
 // Hash 缓存集合
+// fields 存储所有field和内容，支持随机访问和游标遍历
+// fieldExpirations 为设置了过期时间的field，nextFieldExpiration 为其中最早的过期时间，0表示没有
 type Hash struct {
-	fields     map[string]any
-	expiration int64
+	fields              *dict[any]
+	fieldExpirations    map[string]int64
+	nextFieldExpiration int64
+	expiration          int64
 }
 
 // Exist Hash中是否存在field
 func (h *Hash) Exist(field string) bool {
-	if _, exist := h.fields[field]; exist {
-		return true
-	}
-	return false
+	return h.fields.has(field)
 }
 
-// HSet 添加Hash中的元素
+// HSet 添加Hash中的元素，覆盖已有的field时清除其过期时间
 func (h *Hash) HSet(field string, v any) {
-	h.fields[field] = v
+	h.fields.set(field, v)
+	delete(h.fieldExpirations, field)
 }
 
 // HGet 获取Hash中的元素
 func (h *Hash) HGet(field string) (any, error) {
-	if !h.Exist(field) {
+	v, exist := h.fields.get(field)
+	if !exist {
 		return nil, ErrHashField
 	}
-	return h.fields[field], nil
+	return v, nil
 }
 
 // HDel 删除Hash中的元素
 func (h *Hash) HDel(field string) {
-	if h.Exist(field) {
-		delete(h.fields, field)
+	if h.fields.del(field) {
+		delete(h.fieldExpirations, field)
 	}
 }
 
-// HKeys 获取Hash中所有field列表
-func (h *Hash) HKeys() ([]string, error) {
-	var fields []string
-	for field, _ := range h.fields {
-		fields = append(fields, field)
+// HExpireAt 设置field的过期时间，at为UnixNano
+// return int 为 FieldNotExist、FieldExpireSet 或 FieldExpired
+func (h *Hash) HExpireAt(field string, at int64) int {
+	if !h.Exist(field) {
+		return FieldNotExist
+	}
+	if at <= time.Now().UnixNano() {
+		h.HDel(field)
+		return FieldExpired
+	}
+	if h.fieldExpirations == nil {
+		h.fieldExpirations = make(map[string]int64)
+	}
+	h.fieldExpirations[field] = at
+	if h.nextFieldExpiration == 0 || at < h.nextFieldExpiration {
+		h.nextFieldExpiration = at
 	}
-	return fields, nil
+	return FieldExpireSet
+}
+
+// HTTL 获取field的剩余生存时间，单位为毫秒
+// field不存在时返回 FieldNotExist，没有过期时间时返回 FieldNoExpiration
+func (h *Hash) HTTL(field string) int64 {
+	if !h.Exist(field) {
+		return FieldNotExist
+	}
+	at, exist := h.fieldExpirations[field]
+	if !exist {
+		return FieldNoExpiration
+	}
+	return time.Duration(at - time.Now().UnixNano()).Milliseconds()
+}
+
+// HPersist 移除field的过期时间
+// return int 为 FieldNotExist、FieldNoExpiration 或 FieldPersisted
+func (h *Hash) HPersist(field string) int {
+	if !h.Exist(field) {
+		return FieldNotExist
+	}
+	if _, exist := h.fieldExpirations[field]; !exist {
+		return FieldNoExpiration
+	}
+	delete(h.fieldExpirations, field)
+	return FieldPersisted
+}
+
+// clearExpiredFields 删除已过期的field
+// 只在最早的过期时间已到达时遍历设置了过期时间的field
+// return int 为删除的field数量
+func (h *Hash) clearExpiredFields() int {
+	now := time.Now().UnixNano()
+	if h.nextFieldExpiration == 0 || now < h.nextFieldExpiration {
+		return 0
+	}
+	var cleared int
+	h.nextFieldExpiration = 0
+	for field, at := range h.fieldExpirations {
+		if at <= now {
+			h.fields.del(field)
+			delete(h.fieldExpirations, field)
+			cleared++
+		} else if h.nextFieldExpiration == 0 || at < h.nextFieldExpiration {
+			h.nextFieldExpiration = at
+		}
+	}
+	return cleared
+}
+
+// HGetAll 获取Hash中所有的field和内容
+func (h *Hash) HGetAll() map[string]any {
+	all := make(map[string]any, h.HLen())
+	for _, e := range h.fields.entries {
+		all[e.key] = e.value
+	}
+	return all
+}
+
+// HLen 获取Hash中field的数量
+func (h *Hash) HLen() int {
+	return h.fields.len()
+}
+
+// HStrLen 获取field的值的长度，field不存在时返回0
+func (h *Hash) HStrLen(field string) (int, error) {
+	v, exist := h.fields.get(field)
+	if !exist {
+		return 0, nil
+	}
+	switch cur := v.(type) {
+	case string:
+		return len(cur), nil
+	case []byte:
+		return len(cur), nil
+	}
+	return 0, ErrNotString
+}
+
+// HIncrBy 对field的计数+incr，结果按int64存储
+func (h *Hash) HIncrBy(field string, incr int64) (int64, error) {
+	v, _ := h.fields.get(field)
+	n, err := toInt64(v)
+	if err != nil {
+		return 0, err
+	}
+	if n, err = addInt64(n, incr); err != nil {
+		return 0, err
+	}
+	h.fields.set(field, n)
+	return n, nil
+}
+
+// HIncrByFloat 对field的计数+incr，结果按float64存储
+func (h *Hash) HIncrByFloat(field string, incr float64) (float64, error) {
+	v, _ := h.fields.get(field)
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, err
+	}
+	if f, err = addFloat64(f, incr); err != nil {
+		return 0, err
+	}
+	h.fields.set(field, f)
+	return f, nil
+}
+
+// HRandField 随机获取count个field
+func (h *Hash) HRandField(count int) []string {
+	n := h.HLen()
+	if count < 0 {
+		fields := make([]string, -count)
+		for i := range fields {
+			fields[i] = h.fields.at(rand.Intn(n)).key
+		}
+		return fields
+	}
+	if count >= n {
+		return h.fields.keys()
+	}
+	// Floyd 算法：不复制field列表，等概率选出count个不重复的位置
+	picked := make(map[int]struct{}, count)
+	fields := make([]string, 0, count)
+	for j := n - count; j < n; j++ {
+		i := rand.Intn(j + 1)
+		if _, exist := picked[i]; exist {
+			i = j
+		}
+		picked[i] = struct{}{}
+		fields = append(fields, h.fields.at(i).key)
+	}
+	return fields
+}
+
+// HKeys 获取Hash中所有field列表，返回的切片为副本
+func (h *Hash) HKeys() ([]string, error) {
+	return h.fields.keys(), nil
 }
 
-// HVals 获取Hash中所有的内容列表
+// HVals 获取Hash中所有的内容列表，返回的切片为副本
 func (h *Hash) HVals() ([]any, error) {
-	var vals []any
-	for _, val := range h.fields {
-		vals = append(vals, val)
+	vals := make([]any, h.HLen())
+	for i, e := range h.fields.entries {
+		vals[i] = e.value
 	}
 	return vals, nil
 }
@@ -0,0 +1,567 @@
+package types
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// HyperLogLog 的参数与Redis一致，标准误差约为0.81%
+const (
+	hllP              = 14                         // 使用hash的低14位选择寄存器
+	hllQ              = 64 - hllP                  // 用于计算连续0个数的位数
+	hllRegisters      = 1 << hllP                  // 寄存器数量
+	hllBits           = 6                          // 密集表示中每个寄存器的位数
+	hllRegisterMax    = 1<<hllBits - 1             // 寄存器的最大值
+	hllDenseSize      = hllRegisters * hllBits / 8 // 密集表示的字节数
+	hllSparseValMax   = 32                         // 稀疏表示中VAL能存储的最大值
+	hllSparseMaxBytes = 3000                       // 稀疏表示超过该长度后转换为密集表示
+	hllAlphaInf       = 0.721347520444481703680    // 估算公式中的常数
+	hllSeed           = uint64(0xadc83b19)         // 与Redis一致的hash种子
+)
+
+// NewHyperLogLogs 创建HyperLogLog类型实例
+func NewHyperLogLogs() *HyperLogLogs {
+	return &HyperLogLogs{
+		items: make(map[string]*HyperLogLog),
+	}
+}
+
+// HyperLogLogs HyperLogLog类型数据结构
+type HyperLogLogs struct {
+	mu    sync.Mutex
+	items map[string]*HyperLogLog
+}
+
+// Exist 判断k是否存在
+func (hs *HyperLogLogs) Exist(k string) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.exist(k)
+}
+
+// exist 判断k是否存在
+func (hs *HyperLogLogs) exist(k string) bool {
+	h, exist := hs.items[k]
+	if !exist {
+		return false
+	}
+	if h.isExpired() {
+		hs.del(k)
+		return false
+	}
+	return true
+}
+
+// PFAdd 向HyperLogLog中添加元素，k不存在时创建
+// 元素支持string、[]byte、数字和bool，统一按字符串计算hash
+// return changed bool 表示估算的基数是否可能发生变化，exist bool 表示添加前k是否存在
+func (hs *HyperLogLogs) PFAdd(k string, elements ...any) (changed, exist bool, err error) {
+	members, err := toMembers(elements)
+	if err != nil {
+		return false, false, err
+	}
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	h := hs.get(k)
+	if exist = h != nil; !exist {
+		h = newHyperLogLog()
+		hs.items[k] = h
+		changed = true
+	}
+	for _, m := range members {
+		if h.add(m) {
+			changed = true
+		}
+	}
+	return changed, exist, nil
+}
+
+// PFCount 估算一个或多个HyperLogLog并集的基数，不存在的k视为空
+func (hs *HyperLogLogs) PFCount(keys ...string) int64 {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(keys) == 1 {
+		h := hs.get(keys[0])
+		if h == nil {
+			return 0
+		}
+		return h.count()
+	}
+	var regs [hllRegisters]uint8
+	for _, k := range keys {
+		if h := hs.get(k); h != nil {
+			h.merge(&regs)
+		}
+	}
+	return countRegisters(&regs)
+}
+
+// PFMerge 将多个HyperLogLog合并到dst中，dst原有的元素会保留，dst不存在时创建
+// return exist bool 表示合并前dst是否存在
+func (hs *HyperLogLogs) PFMerge(dst string, keys ...string) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	var regs [hllRegisters]uint8
+	d := hs.get(dst)
+	exist := d != nil
+	if exist {
+		d.merge(&regs)
+	}
+	for _, k := range keys {
+		if h := hs.get(k); h != nil {
+			h.merge(&regs)
+		}
+	}
+	merged := newHyperLogLogWithRegisters(&regs)
+	if exist {
+		merged.expiration = d.expiration
+	}
+	hs.items[dst] = merged
+	return exist
+}
+
+// get 获取k对应的未过期HyperLogLog，不存在时返回nil
+func (hs *HyperLogLogs) get(k string) *HyperLogLog {
+	if !hs.exist(k) {
+		return nil
+	}
+	return hs.items[k]
+}
+
+// Del 删除一个key
+func (hs *HyperLogLogs) Del(k string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.del(k)
+}
+
+func (hs *HyperLogLogs) del(k string) {
+	delete(hs.items, k)
+}
+
+// Expiration 设置超时时间
+func (hs *HyperLogLogs) Expiration(k string, d time.Duration) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if !hs.exist(k) {
+		return ErrKeyNotExist
+	}
+	hs.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (hs *HyperLogLogs) ClearExpiration() {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	for key, item := range hs.items {
+		if item.isExpired() {
+			delete(hs.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (hs *HyperLogLogs) RandomClearExpiration() {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	var counter int
+	for key, item := range hs.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(hs.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (hs *HyperLogLogs) Flush() {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.items = make(map[string]*HyperLogLog)
+}
+
+// newHyperLogLog 创建一个空的HyperLogLog，使用稀疏表示
+func newHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{
+		sparse:     encodeSparse([]hllRun{{n: hllRegisters}}),
+		card:       0,
+		expiration: DefaultExpiration,
+	}
+}
+
+// newHyperLogLogWithRegisters 使用寄存器的值创建HyperLogLog
+// 稀疏表示能容纳时使用稀疏表示，否则使用密集表示
+func newHyperLogLogWithRegisters(regs *[hllRegisters]uint8) *HyperLogLog {
+	h := &HyperLogLog{
+		card:       -1,
+		expiration: DefaultExpiration,
+	}
+	runs := make([]hllRun, 0)
+	for idx := 0; idx < hllRegisters; {
+		val := regs[idx]
+		if val > hllSparseValMax {
+			runs = nil
+			break
+		}
+		n := 1
+		for idx+n < hllRegisters && regs[idx+n] == val {
+			n++
+		}
+		runs = append(runs, hllRun{val: val, n: n})
+		idx += n
+	}
+	if runs != nil {
+		if h.sparse = encodeSparse(runs); len(h.sparse) <= hllSparseMaxBytes {
+			return h
+		}
+	}
+	h.sparse = nil
+	h.dense = make([]byte, hllDenseSize)
+	for idx, val := range regs {
+		denseSet(h.dense, idx, val)
+	}
+	return h
+}
+
+// HyperLogLog 基数估算
+// sparse 为Redis的稀疏表示，由ZERO、XZERO、VAL三种操作码组成，dense 不为nil时使用密集表示
+// dense 为Redis的密集表示，每个寄存器占6位
+// card 为缓存的基数，-1表示需要重新计算
+type HyperLogLog struct {
+	sparse     []byte
+	dense      []byte
+	card       int64
+	expiration int64
+}
+
+// hllRun 稀疏表示中连续n个值为val的寄存器
+type hllRun struct {
+	val uint8
+	n   int
+}
+
+// add 添加一个元素
+// return bool 表示是否有寄存器被更新
+func (h *HyperLogLog) add(member string) bool {
+	hash := murmurHash64A([]byte(member), hllSeed)
+	index := int(hash & (hllRegisters - 1))
+	// 加入哨兵位，保证连续0的个数不超过hllQ
+	hash = hash>>hllP | 1<<hllQ
+	count := uint8(bits.TrailingZeros64(hash) + 1)
+	if !h.set(index, count) {
+		return false
+	}
+	h.card = -1
+	return true
+}
+
+// set 寄存器的值小于count时更新为count
+// return bool 表示寄存器是否被更新
+func (h *HyperLogLog) set(index int, count uint8) bool {
+	if h.dense == nil {
+		if count <= hllSparseValMax {
+			return h.sparseSet(index, count)
+		}
+		h.toDense()
+	}
+	if denseGet(h.dense, index) >= count {
+		return false
+	}
+	denseSet(h.dense, index, count)
+	return true
+}
+
+// sparseSet 在稀疏表示中更新寄存器，只替换寄存器所在的操作码，超出长度限制时转换为密集表示
+func (h *HyperLogLog) sparseSet(index int, count uint8) bool {
+	pos := 0
+	for i := 0; i < len(h.sparse); {
+		run, size := sparseOp(h.sparse[i:])
+		if index >= pos+run.n {
+			pos += run.n
+			i += size
+			continue
+		}
+		if run.val >= count {
+			return false
+		}
+		// 将所在的区间拆分为 前段、index、后段 三部分
+		split := make([]hllRun, 0, 3)
+		if before := index - pos; before > 0 {
+			split = append(split, hllRun{val: run.val, n: before})
+		}
+		split = append(split, hllRun{val: count, n: 1})
+		if after := pos + run.n - index - 1; after > 0 {
+			split = append(split, hllRun{val: run.val, n: after})
+		}
+		repl := encodeSparse(split)
+		if len(repl) == size {
+			copy(h.sparse[i:], repl)
+			return true
+		}
+		sparse := make([]byte, 0, len(h.sparse)-size+len(repl))
+		sparse = append(sparse, h.sparse[:i]...)
+		sparse = append(sparse, repl...)
+		h.sparse = append(sparse, h.sparse[i+size:]...)
+		if len(h.sparse) > hllSparseMaxBytes {
+			h.toDense()
+		}
+		return true
+	}
+	return false
+}
+
+// toDense 将稀疏表示转换为密集表示
+func (h *HyperLogLog) toDense() {
+	dense := make([]byte, hllDenseSize)
+	index := 0
+	for _, run := range decodeSparse(h.sparse) {
+		if run.val > 0 {
+			for i := 0; i < run.n; i++ {
+				denseSet(dense, index+i, run.val)
+			}
+		}
+		index += run.n
+	}
+	h.dense = dense
+	h.sparse = nil
+}
+
+// merge 将寄存器的值合并到regs中，每个寄存器取最大值
+func (h *HyperLogLog) merge(regs *[hllRegisters]uint8) {
+	if h.dense != nil {
+		for idx := range regs {
+			if val := denseGet(h.dense, idx); val > regs[idx] {
+				regs[idx] = val
+			}
+		}
+		return
+	}
+	index := 0
+	for _, run := range decodeSparse(h.sparse) {
+		for i := 0; i < run.n && run.val > 0; i++ {
+			if run.val > regs[index+i] {
+				regs[index+i] = run.val
+			}
+		}
+		index += run.n
+	}
+}
+
+// count 估算基数，结果会被缓存直到有寄存器被更新
+func (h *HyperLogLog) count() int64 {
+	if h.card >= 0 {
+		return h.card
+	}
+	var hist [hllQ + 2]int
+	if h.dense != nil {
+		for idx := 0; idx < hllRegisters; idx++ {
+			hist[denseGet(h.dense, idx)]++
+		}
+	} else {
+		for _, run := range decodeSparse(h.sparse) {
+			hist[run.val] += run.n
+		}
+	}
+	h.card = estimate(&hist)
+	return h.card
+}
+
+// isExpired 判断一个元素是否过期
+func (h *HyperLogLog) isExpired() bool {
+	if h.expiration != DefaultExpiration && time.Now().UnixNano() > h.expiration {
+		return true
+	}
+	return false
+}
+
+// countRegisters 根据寄存器的值估算基数
+func countRegisters(regs *[hllRegisters]uint8) int64 {
+	var hist [hllQ + 2]int
+	for _, val := range regs {
+		hist[val]++
+	}
+	return estimate(&hist)
+}
+
+// estimate 根据寄存器值的分布估算基数，使用与Redis相同的Ertl改进算法
+func estimate(hist *[hllQ + 2]int) int64 {
+	m := float64(hllRegisters)
+	z := m * hllTau((m-float64(hist[hllQ+1]))/m)
+	for j := hllQ; j >= 1; j-- {
+		z += float64(hist[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(hist[0])/m)
+	return int64(math.Round(hllAlphaInf * m * m / z))
+}
+
+// hllSigma Ertl算法中的sigma函数
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if prev == z {
+			return z
+		}
+	}
+}
+
+// hllTau Ertl算法中的tau函数
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= math.Pow(1-x, 2) * y
+		if prev == z {
+			return z / 3
+		}
+	}
+}
+
+// denseGet 读取密集表示中第index个寄存器
+func denseGet(dense []byte, index int) uint8 {
+	byteIndex := index * hllBits / 8
+	fb := uint(index * hllBits & 7)
+	b0 := dense[byteIndex]
+	var b1 byte
+	if byteIndex+1 < len(dense) {
+		b1 = dense[byteIndex+1]
+	}
+	return (b0>>fb | b1<<(8-fb)) & hllRegisterMax
+}
+
+// denseSet 写入密集表示中第index个寄存器
+func denseSet(dense []byte, index int, val uint8) {
+	byteIndex := index * hllBits / 8
+	fb := uint(index * hllBits & 7)
+	dense[byteIndex] &^= hllRegisterMax << fb
+	dense[byteIndex] |= val << fb
+	if byteIndex+1 < len(dense) {
+		dense[byteIndex+1] &^= hllRegisterMax >> (8 - fb)
+		dense[byteIndex+1] |= val >> (8 - fb)
+	}
+}
+
+// decodeSparse 解析稀疏表示
+func decodeSparse(sparse []byte) []hllRun {
+	runs := make([]hllRun, 0, len(sparse))
+	for i := 0; i < len(sparse); {
+		run, size := sparseOp(sparse[i:])
+		runs = append(runs, run)
+		i += size
+	}
+	return runs
+}
+
+// sparseOp 解析稀疏表示中的一个操作码
+// ZERO 00xxxxxx 表示1~64个0，XZERO 01xxxxxx yyyyyyyy 表示1~16384个0，
+// VAL 1vvvvvxx 表示1~4个值为1~32的寄存器
+// return int 为操作码的字节数
+func sparseOp(b []byte) (hllRun, int) {
+	op := b[0]
+	switch op & 0xc0 {
+	case 0x00:
+		return hllRun{n: int(op&0x3f) + 1}, 1
+	case 0x40:
+		return hllRun{n: (int(op&0x3f)<<8 | int(b[1])) + 1}, 2
+	}
+	return hllRun{val: (op>>2)&0x1f + 1, n: int(op&0x03) + 1}, 1
+}
+
+// encodeSparse 将连续的寄存器编码为稀疏表示，相邻且值相同的区间会被合并
+func encodeSparse(runs []hllRun) []byte {
+	sparse := make([]byte, 0, len(runs)*2)
+	for i := 0; i < len(runs); i++ {
+		val, n := runs[i].val, runs[i].n
+		for i+1 < len(runs) && runs[i+1].val == val {
+			i++
+			n += runs[i].n
+		}
+		for n > 0 {
+			switch {
+			case val > 0:
+				l := minInt(n, 4)
+				sparse = append(sparse, 0x80|(val-1)<<2|byte(l-1))
+				n -= l
+			case n > 64:
+				l := minInt(n, hllRegisters)
+				sparse = append(sparse, 0x40|byte((l-1)>>8), byte(l-1))
+				n -= l
+			default:
+				sparse = append(sparse, byte(n-1))
+				n = 0
+			}
+		}
+	}
+	return sparse
+}
+
+// murmurHash64A 与Redis一致的MurmurHash64A
+func murmurHash64A(key []byte, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+	h := seed ^ uint64(len(key))*m
+	n := len(key) / 8
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint64(key[i*8:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+	}
+	tail := key[n*8:]
+	switch len(tail) {
+	case 7:
+		h ^= uint64(tail[6]) << 48
+		fallthrough
+	case 6:
+		h ^= uint64(tail[5]) << 40
+		fallthrough
+	case 5:
+		h ^= uint64(tail[4]) << 32
+		fallthrough
+	case 4:
+		h ^= uint64(tail[3]) << 24
+		fallthrough
+	case 3:
+		h ^= uint64(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint64(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint64(tail[0])
+		h *= m
+	}
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+// minInt 获取a和b中较小的值
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,930 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewJSONs 创建JSON类型实例
+func NewJSONs() *JSONs {
+	return &JSONs{
+		items: make(map[string]*JSONDocument),
+	}
+}
+
+// JSONs JSON类型数据结构
+type JSONs struct {
+	mu    sync.Mutex
+	items map[string]*JSONDocument
+}
+
+// Exist 判断k是否存在
+func (js *JSONs) Exist(k string) bool {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	return js.exist(k)
+}
+
+// exist 判断k是否存在
+func (js *JSONs) exist(k string) bool {
+	d, exist := js.items[k]
+	if !exist {
+		return false
+	}
+	if d.isExpired() {
+		js.del(k)
+		return false
+	}
+	return true
+}
+
+// JSONSet 设置path匹配的值，value 为[]byte或json.RawMessage时按JSON文本解析，其他类型按json.Marshal转换
+// k不存在时只能在根路径"$"创建，path 的最后一级为对象中不存在的字段时添加该字段
+// mode 为NX时只在path不存在时设置，为XX时只在path存在时设置，为空时不限制
+// return set bool 表示是否设置成功，exist bool 表示设置前k是否存在
+func (js *JSONs) JSONSet(k, path string, value any, mode SetMode) (set, exist bool, err error) {
+	if mode != "" && mode != SetModeNX && mode != SetModeXX {
+		return false, false, ErrSetMode
+	}
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return false, false, err
+	}
+	v, err := toJSONValue(value)
+	if err != nil {
+		return false, false, err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if exist = d != nil; !exist {
+		if len(p.segments) > 0 {
+			return false, false, ErrJSONKey
+		}
+		if mode == SetModeXX {
+			return false, false, nil
+		}
+		js.items[k] = &JSONDocument{root: v, expiration: DefaultExpiration}
+		return true, false, nil
+	}
+	if matches := p.eval(d.root); len(matches) > 0 {
+		if mode == SetModeNX {
+			return false, true, nil
+		}
+		for i, m := range matches {
+			if i > 0 {
+				v = cloneJSON(v)
+			}
+			d.replace(m, v)
+		}
+		return true, true, nil
+	}
+	if mode == SetModeXX {
+		return false, true, nil
+	}
+	// 在父路径匹配的对象中添加字段
+	last := p.segments[len(p.segments)-1]
+	if last.recursive || last.wildcard || len(last.keys) != 1 {
+		return false, true, nil
+	}
+	parent := &jsonPath{segments: p.segments[:len(p.segments)-1]}
+	for _, m := range parent.eval(d.root) {
+		if obj, ok := m.value.(*jsonObject); ok {
+			if set {
+				v = cloneJSON(v)
+			}
+			obj.set(last.keys[0], v)
+			set = true
+		}
+	}
+	return set, true, nil
+}
+
+// JSONGet 获取path匹配的值，返回JSON文本
+// 没有path时返回整个文档；path 以"$"开头时返回所有匹配值组成的数组，否则返回第一个匹配的值
+// 有多个path时返回以path为key的对象
+func (js *JSONs) JSONGet(k string, paths ...string) ([]byte, error) {
+	parsed := make([]*jsonPath, len(paths))
+	for i, path := range paths {
+		p, err := parseJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = p
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if d == nil {
+		return nil, ErrJSONKey
+	}
+	var buf bytes.Buffer
+	if len(parsed) == 0 {
+		writeJSON(&buf, d.root)
+		return buf.Bytes(), nil
+	}
+	if len(parsed) == 1 {
+		if err := parsed[0].write(&buf, d.root); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	buf.WriteByte('{')
+	for i, p := range parsed {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSON(&buf, paths[i])
+		buf.WriteByte(':')
+		if err := p.write(&buf, d.root); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// JSONDel 删除path匹配的值，path 为根路径时删除k
+// return n int 为删除的数量，deleted bool 表示k是否被删除
+func (js *JSONs) JSONDel(k, path string) (n int, deleted bool, err error) {
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return 0, false, err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if d == nil {
+		return 0, false, nil
+	}
+	if len(p.segments) == 0 {
+		js.del(k)
+		return 1, true, nil
+	}
+	matches := p.eval(d.root)
+	// 同一个数组中的元素从后向前删除，保证下标不变
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].index > matches[j].index
+	})
+	removed := make(map[jsonMatch]bool, len(matches))
+	for _, m := range matches {
+		switch parent := m.parent.(type) {
+		case *jsonObject:
+			if parent.del(m.key) {
+				n++
+			}
+		case *jsonArray:
+			// 同一个元素可能被多次匹配，只删除一次
+			key := jsonMatch{parent: parent, index: m.index}
+			if !removed[key] && m.index < len(parent.items) {
+				removed[key] = true
+				parent.items = append(parent.items[:m.index], parent.items[m.index+1:]...)
+				n++
+			}
+		}
+	}
+	return n, false, nil
+}
+
+// JSONArrAppend 向path匹配的数组末尾添加元素
+// return []*int 为添加后数组的长度，匹配的值不是数组时为nil
+func (js *JSONs) JSONArrAppend(k, path string, values ...any) ([]*int, error) {
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]any, len(values))
+	for i, value := range values {
+		if items[i], err = toJSONValue(value); err != nil {
+			return nil, err
+		}
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if d == nil {
+		return nil, ErrJSONKey
+	}
+	matches := p.eval(d.root)
+	lengths := make([]*int, len(matches))
+	for i, m := range matches {
+		arr, ok := m.value.(*jsonArray)
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			arr.items = append(arr.items, cloneJSON(item))
+		}
+		n := len(arr.items)
+		lengths[i] = &n
+	}
+	return lengths, nil
+}
+
+// JSONArrPop 从path匹配的数组中弹出下标为index的元素，index 为负数时从末尾计算，超出范围时取最近的元素
+// return [][]byte 为弹出元素的JSON文本，匹配的值不是数组或数组为空时为nil
+func (js *JSONs) JSONArrPop(k, path string, index int) ([][]byte, error) {
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if d == nil {
+		return nil, ErrJSONKey
+	}
+	matches := p.eval(d.root)
+	popped := make([][]byte, len(matches))
+	for i, m := range matches {
+		arr, ok := m.value.(*jsonArray)
+		if !ok || len(arr.items) == 0 {
+			continue
+		}
+		idx := index
+		if idx < 0 {
+			idx += len(arr.items)
+		}
+		idx = int(math.Max(0, math.Min(float64(idx), float64(len(arr.items)-1))))
+		var buf bytes.Buffer
+		writeJSON(&buf, arr.items[idx])
+		popped[i] = buf.Bytes()
+		arr.items = append(arr.items[:idx], arr.items[idx+1:]...)
+	}
+	return popped, nil
+}
+
+// JSONNumIncrBy 将path匹配的数字增加n，整数加整数的结果仍为整数
+// return []json.Number 为增加后的值，匹配的值不是数字时为空字符串
+func (js *JSONs) JSONNumIncrBy(k, path string, n float64) ([]json.Number, error) {
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if d == nil {
+		return nil, ErrJSONKey
+	}
+	matches := p.eval(d.root)
+	results := make([]json.Number, len(matches))
+	values := make([]json.Number, len(matches))
+	// 先计算所有结果，有溢出时不修改任何值
+	for i, m := range matches {
+		if num, ok := m.value.(json.Number); ok {
+			if values[i], err = addJSONNumber(num, n); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, m := range matches {
+		if values[i] != "" {
+			d.replace(m, values[i])
+			results[i] = values[i]
+		}
+	}
+	return results, nil
+}
+
+// JSONType 获取path匹配的值的类型
+// 类型为object、array、string、integer、number、boolean或null
+func (js *JSONs) JSONType(k, path string) ([]string, error) {
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if d == nil {
+		return nil, ErrJSONKey
+	}
+	matches := p.eval(d.root)
+	types := make([]string, len(matches))
+	for i, m := range matches {
+		types[i] = jsonType(m.value)
+	}
+	return types, nil
+}
+
+// JSONObjKeys 获取path匹配的对象的所有字段，按添加的顺序排列，匹配的值不是对象时为nil
+func (js *JSONs) JSONObjKeys(k, path string) ([][]string, error) {
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	d := js.get(k)
+	if d == nil {
+		return nil, ErrJSONKey
+	}
+	matches := p.eval(d.root)
+	keys := make([][]string, len(matches))
+	for i, m := range matches {
+		if obj, ok := m.value.(*jsonObject); ok {
+			keys[i] = append([]string{}, obj.keys...)
+		}
+	}
+	return keys, nil
+}
+
+// get 获取k对应的未过期文档，不存在时返回nil
+func (js *JSONs) get(k string) *JSONDocument {
+	if !js.exist(k) {
+		return nil
+	}
+	return js.items[k]
+}
+
+// Del 删除一个key
+func (js *JSONs) Del(k string) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.del(k)
+}
+
+func (js *JSONs) del(k string) {
+	delete(js.items, k)
+}
+
+// Expiration 设置超时时间
+func (js *JSONs) Expiration(k string, d time.Duration) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if !js.exist(k) {
+		return ErrKeyNotExist
+	}
+	js.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (js *JSONs) ClearExpiration() {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	for key, item := range js.items {
+		if item.isExpired() {
+			delete(js.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (js *JSONs) RandomClearExpiration() {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	var counter int
+	for key, item := range js.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(js.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (js *JSONs) Flush() {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.items = make(map[string]*JSONDocument)
+}
+
+// JSONDocument 解析后的JSON文档
+// 对象为 *jsonObject，数组为 *jsonArray，数字为 json.Number，其他为string、bool和nil
+type JSONDocument struct {
+	root       any
+	expiration int64
+}
+
+// replace 将匹配的值替换为v
+func (d *JSONDocument) replace(m jsonMatch, v any) {
+	switch parent := m.parent.(type) {
+	case nil:
+		d.root = v
+	case *jsonObject:
+		parent.values[m.key] = v
+	case *jsonArray:
+		parent.items[m.index] = v
+	}
+}
+
+// isExpired 判断一个元素是否过期
+func (d *JSONDocument) isExpired() bool {
+	if d.expiration != DefaultExpiration && time.Now().UnixNano() > d.expiration {
+		return true
+	}
+	return false
+}
+
+// jsonObject 保留字段顺序的JSON对象
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// set 设置字段的值，新字段添加到末尾
+func (o *jsonObject) set(key string, v any) {
+	if _, exist := o.values[key]; !exist {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+// del 删除字段
+func (o *jsonObject) del(key string) bool {
+	if _, exist := o.values[key]; !exist {
+		return false
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// jsonArray JSON数组，使用指针以便原地修改
+type jsonArray struct {
+	items []any
+}
+
+// toJSONValue 将value转换为JSON树
+func toJSONValue(value any) (any, error) {
+	switch v := value.(type) {
+	case json.RawMessage:
+		return parseJSON(v)
+	case []byte:
+		return parseJSON(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, ErrJSONValue
+	}
+	return parseJSON(data)
+}
+
+// parseJSON 将JSON文本解析为JSON树
+func parseJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeJSON(dec)
+	if err != nil {
+		return nil, ErrJSONValue
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, ErrJSONValue
+	}
+	return v, nil
+}
+
+// decodeJSON 从dec中读取一个值
+func decodeJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := &jsonObject{values: make(map[string]any)}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key.(string), v)
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := &jsonArray{items: make([]any, 0)}
+		for dec.More() {
+			v, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr.items = append(arr.items, v)
+		}
+		_, err = dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// writeJSON 将JSON树序列化为JSON文本
+func writeJSON(buf *bytes.Buffer, v any) {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case json.Number:
+		buf.WriteString(string(t))
+	case string:
+		data, _ := json.Marshal(t)
+		buf.Write(data)
+	case *jsonObject:
+		buf.WriteByte('{')
+		for i, key := range t.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, key)
+			buf.WriteByte(':')
+			writeJSON(buf, t.values[key])
+		}
+		buf.WriteByte('}')
+	case *jsonArray:
+		buf.WriteByte('[')
+		for i, item := range t.items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, item)
+		}
+		buf.WriteByte(']')
+	}
+}
+
+// cloneJSON 深拷贝JSON树
+func cloneJSON(v any) any {
+	switch t := v.(type) {
+	case *jsonObject:
+		obj := &jsonObject{keys: append([]string{}, t.keys...), values: make(map[string]any, len(t.values))}
+		for key, value := range t.values {
+			obj.values[key] = cloneJSON(value)
+		}
+		return obj
+	case *jsonArray:
+		arr := &jsonArray{items: make([]any, len(t.items))}
+		for i, item := range t.items {
+			arr.items[i] = cloneJSON(item)
+		}
+		return arr
+	}
+	return v
+}
+
+// jsonType 获取值的类型
+func jsonType(v any) string {
+	switch t := v.(type) {
+	case *jsonObject:
+		return "object"
+	case *jsonArray:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := t.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return "null"
+}
+
+// addJSONNumber 计算num+n，都为整数且不溢出时结果为整数，否则为浮点数
+func addJSONNumber(num json.Number, n float64) (json.Number, error) {
+	if i, err := num.Int64(); err == nil && n == math.Trunc(n) && math.Abs(n) < math.MaxInt64 {
+		if sum, err := addInt64(i, int64(n)); err == nil {
+			return json.Number(strconv.FormatInt(sum, 10)), nil
+		}
+	}
+	f, err := num.Float64()
+	if err != nil {
+		return "", ErrNotFloat
+	}
+	sum, err := addFloat64(f, n)
+	if err != nil {
+		return "", err
+	}
+	s := strconv.FormatFloat(sum, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return json.Number(s), nil
+}
+
+// jsonPath 解析后的JSONPath
+// legacy 为true时为不以"$"开头的旧版路径，只返回第一个匹配的值
+type jsonPath struct {
+	legacy   bool
+	segments []jsonSegment
+}
+
+// jsonSegment JSONPath中的一级
+// recursive 为true时匹配所有后代，wildcard 匹配所有子元素，keys 为对象字段，indexes 为数组下标
+type jsonSegment struct {
+	recursive bool
+	wildcard  bool
+	keys      []string
+	indexes   []int
+	slice     *jsonSlice
+}
+
+// jsonSlice 数组切片[start:end:step]
+type jsonSlice struct {
+	start, end, step int
+	hasStart, hasEnd bool
+}
+
+// jsonMatch 匹配到的值，parent 为所在的对象或数组，根节点的parent为nil
+type jsonMatch struct {
+	value  any
+	parent any
+	key    string
+	index  int
+}
+
+// parseJSONPath 解析JSONPath，支持$、.name、['name']、[index]、[*]、..name和[start:end:step]
+// 不以"$"开头时为旧版路径，""和"."表示根节点
+func parseJSONPath(path string) (*jsonPath, error) {
+	p := &jsonPath{}
+	switch {
+	case path == "" || path == ".":
+		return &jsonPath{legacy: true}, nil
+	case path[0] == '$':
+		path = path[1:]
+	default:
+		p.legacy = true
+		if path[0] != '.' && path[0] != '[' {
+			path = "." + path
+		}
+	}
+	for i := 0; i < len(path); {
+		var seg jsonSegment
+		var err error
+		switch {
+		case strings.HasPrefix(path[i:], ".."):
+			// ..name、..*和..[...]匹配所有后代
+			seg.recursive = true
+			if i += 2; i < len(path) && path[i] == '[' {
+				i, err = parseJSONBracket(path, i, &seg)
+			} else {
+				i, err = parseJSONName(path, i, &seg)
+			}
+		case path[i] == '.':
+			i, err = parseJSONName(path, i+1, &seg)
+		case path[i] == '[':
+			i, err = parseJSONBracket(path, i, &seg)
+		default:
+			err = ErrJSONPath
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.segments = append(p.segments, seg)
+	}
+	return p, nil
+}
+
+// parseJSONName 解析.name或.*，返回下一级的位置
+func parseJSONName(path string, i int, seg *jsonSegment) (int, error) {
+	end := i
+	for end < len(path) && path[end] != '.' && path[end] != '[' {
+		end++
+	}
+	name := path[i:end]
+	switch name {
+	case "":
+		return 0, ErrJSONPath
+	case "*":
+		seg.wildcard = true
+	default:
+		seg.keys = []string{name}
+	}
+	return end, nil
+}
+
+// parseJSONBracket 解析[...]，返回下一级的位置
+func parseJSONBracket(path string, i int, seg *jsonSegment) (int, error) {
+	if i >= len(path) || path[i] != '[' {
+		return 0, ErrJSONPath
+	}
+	i++
+	// 带引号的字段名
+	if i < len(path) && (path[i] == '\'' || path[i] == '"') {
+		for {
+			key, end, err := parseJSONQuoted(path, i)
+			if err != nil {
+				return 0, err
+			}
+			seg.keys = append(seg.keys, key)
+			i = skipSpaces(path, end)
+			if i < len(path) && path[i] == ']' {
+				return i + 1, nil
+			}
+			if i >= len(path) || path[i] != ',' {
+				return 0, ErrJSONPath
+			}
+			i = skipSpaces(path, i+1)
+		}
+	}
+	end := strings.IndexByte(path[i:], ']')
+	if end < 0 {
+		return 0, ErrJSONPath
+	}
+	content := strings.TrimSpace(path[i : i+end])
+	next := i + end + 1
+	switch {
+	case content == "*":
+		seg.wildcard = true
+	case strings.Contains(content, ":"):
+		parts := strings.Split(content, ":")
+		if len(parts) > 3 {
+			return 0, ErrJSONPath
+		}
+		s := &jsonSlice{step: 1}
+		var err error
+		if v := strings.TrimSpace(parts[0]); v != "" {
+			if s.start, err = strconv.Atoi(v); err != nil {
+				return 0, ErrJSONPath
+			}
+			s.hasStart = true
+		}
+		if v := strings.TrimSpace(parts[1]); v != "" {
+			if s.end, err = strconv.Atoi(v); err != nil {
+				return 0, ErrJSONPath
+			}
+			s.hasEnd = true
+		}
+		if len(parts) == 3 {
+			if v := strings.TrimSpace(parts[2]); v != "" {
+				if s.step, err = strconv.Atoi(v); err != nil || s.step <= 0 {
+					return 0, ErrJSONPath
+				}
+			}
+		}
+		seg.slice = s
+	default:
+		for _, part := range strings.Split(content, ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return 0, ErrJSONPath
+			}
+			seg.indexes = append(seg.indexes, index)
+		}
+	}
+	return next, nil
+}
+
+// parseJSONQuoted 解析单引号或双引号包围的字段名，支持反斜杠转义
+func parseJSONQuoted(path string, i int) (string, int, error) {
+	quote := path[i]
+	var sb strings.Builder
+	for j := i + 1; j < len(path); j++ {
+		switch path[j] {
+		case '\\':
+			if j+1 >= len(path) {
+				return "", 0, ErrJSONPath
+			}
+			j++
+			sb.WriteByte(path[j])
+		case quote:
+			return sb.String(), j + 1, nil
+		default:
+			sb.WriteByte(path[j])
+		}
+	}
+	return "", 0, ErrJSONPath
+}
+
+// skipSpaces 跳过空格
+func skipSpaces(path string, i int) int {
+	for i < len(path) && path[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// eval 获取root中所有匹配的值
+func (p *jsonPath) eval(root any) []jsonMatch {
+	matches := []jsonMatch{{value: root}}
+	for _, seg := range p.segments {
+		var next []jsonMatch
+		for _, m := range matches {
+			if !seg.recursive {
+				next = seg.apply(m, next)
+				continue
+			}
+			for _, d := range jsonDescendants(m, nil) {
+				next = seg.apply(d, next)
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// write 将匹配的值写入buf，旧版路径只写入第一个匹配的值，没有匹配时返回 ErrJSONPath
+func (p *jsonPath) write(buf *bytes.Buffer, root any) error {
+	matches := p.eval(root)
+	if p.legacy {
+		if len(matches) == 0 {
+			return ErrJSONPath
+		}
+		writeJSON(buf, matches[0].value)
+		return nil
+	}
+	buf.WriteByte('[')
+	for i, m := range matches {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSON(buf, m.value)
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+// apply 获取m中与该级匹配的子元素，追加到out中
+func (seg *jsonSegment) apply(m jsonMatch, out []jsonMatch) []jsonMatch {
+	switch v := m.value.(type) {
+	case *jsonObject:
+		if seg.wildcard {
+			return jsonChildren(m, out)
+		}
+		for _, key := range seg.keys {
+			if value, exist := v.values[key]; exist {
+				out = append(out, jsonMatch{value: value, parent: v, key: key})
+			}
+		}
+	case *jsonArray:
+		n := len(v.items)
+		if seg.wildcard {
+			return jsonChildren(m, out)
+		}
+		for _, index := range seg.indexes {
+			if index < 0 {
+				index += n
+			}
+			if index >= 0 && index < n {
+				out = append(out, jsonMatch{value: v.items[index], parent: v, index: index})
+			}
+		}
+		if s := seg.slice; s != nil {
+			start, end := 0, n
+			if s.hasStart {
+				start = s.start
+			}
+			if s.hasEnd {
+				end = s.end
+			}
+			if start < 0 {
+				start += n
+			}
+			if end < 0 {
+				end += n
+			}
+			start = int(math.Max(0, float64(start)))
+			end = int(math.Min(float64(n), float64(end)))
+			for i := start; i < end; i += s.step {
+				out = append(out, jsonMatch{value: v.items[i], parent: v, index: i})
+			}
+		}
+	}
+	return out
+}
+
+// jsonChildren 获取m的所有子元素，追加到out中
+func jsonChildren(m jsonMatch, out []jsonMatch) []jsonMatch {
+	switch v := m.value.(type) {
+	case *jsonObject:
+		for _, key := range v.keys {
+			out = append(out, jsonMatch{value: v.values[key], parent: v, key: key})
+		}
+	case *jsonArray:
+		for i, item := range v.items {
+			out = append(out, jsonMatch{value: item, parent: v, index: i})
+		}
+	}
+	return out
+}
+
+// jsonDescendants 按先序获取m本身及其所有后代，追加到out中
+func jsonDescendants(m jsonMatch, out []jsonMatch) []jsonMatch {
+	out = append(out, m)
+	for _, child := range jsonChildren(m, nil) {
+		out = jsonDescendants(child, out)
+	}
+	return out
+}
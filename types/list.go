@@ -1,6 +1,9 @@
 package types
 
 import (
+	"bytes"
+	"context"
+	"reflect"
 	"sync"
 	"time"
 )
@@ -8,14 +11,17 @@ import (
 // NewLists 创建List类型实例
 func NewLists() *Lists {
 	return &Lists{
-		items: make(map[string]*List),
+		items:   make(map[string]*List),
+		waiters: make(map[string][]*listWaiter),
 	}
 }
 
 // Lists 类型数据结构
+// waiters 为每个key上阻塞等待元素的调用方，按等待的先后顺序排列
 type Lists struct {
-	mu    sync.Mutex
-	items map[string]*List
+	mu      sync.Mutex
+	items   map[string]*List
+	waiters map[string][]*listWaiter
 }
 
 // Exist 判断一个key是否存在
@@ -38,96 +44,380 @@ func (ls *Lists) exist(k string) bool {
 	return true
 }
 
-// LPush 从队列k的头部，添加一个元素v
-func (ls *Lists) LPush(k string, v any) bool {
+// LPush 从队列k的头部，依次添加元素vs
+// return n int 为添加后队列的长度，exist bool 表示添加前k是否存在
+func (ls *Lists) LPush(k string, vs ...any) (n int, exist bool) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
+	return ls.push(k, ListLeft, vs...)
+}
 
-	l, exist := ls.items[k]
-	if !exist {
-		l = newList()
-	} else if l.isExpired() {
-		ls.del(k)
-		return false
+// LPushX 队列k存在时，从头部依次添加元素vs
+// return int 为添加后队列的长度，k不存在时返回0
+func (ls *Lists) LPushX(k string, vs ...any) int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if !ls.exist(k) {
+		return 0
 	}
-	l.LPush(v)
-	ls.items[k] = l
-	return exist
+	n, _ := ls.push(k, ListLeft, vs...)
+	return n
 }
 
 // LPop 从队列k的头部，弹出一个元素
 func (ls *Lists) LPop(k string) (any, error) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
-	l, exist := ls.items[k]
-	if !exist {
-		return nil, ErrKeyNotExist
-	} else if l.isExpired() {
-		ls.del(k)
-		return nil, ErrKeyNotExist
-	}
-	return l.LPop()
+	return ls.pop(k, ListLeft)
 }
 
-// RPush 从队列k的尾部，添加一个元素
-func (ls *Lists) RPush(k string, v any) bool {
+// LPopCount 从队列k的头部，弹出最多count个元素
+func (ls *Lists) LPopCount(k string, count int) ([]any, error) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
-	l, exist := ls.items[k]
-	if !exist {
-		l = newList()
-	} else if l.isExpired() {
-		ls.del(k)
-		return false
+	return ls.popCount(k, ListLeft, count)
+}
+
+// RPush 从队列k的尾部，依次添加元素vs
+// return n int 为添加后队列的长度，exist bool 表示添加前k是否存在
+func (ls *Lists) RPush(k string, vs ...any) (n int, exist bool) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.push(k, ListRight, vs...)
+}
+
+// RPushX 队列k存在时，从尾部依次添加元素vs
+// return int 为添加后队列的长度，k不存在时返回0
+func (ls *Lists) RPushX(k string, vs ...any) int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if !ls.exist(k) {
+		return 0
 	}
-	l.RPush(v)
-	ls.items[k] = l
-	return exist
+	n, _ := ls.push(k, ListRight, vs...)
+	return n
 }
 
 // RPop 从队列k的尾部，弹出一个元素
 func (ls *Lists) RPop(k string) (any, error) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
-	l, exist := ls.items[k]
-	if !exist {
-		return nil, ErrKeyNotExist
-	} else if l.isExpired() {
-		ls.del(k)
-		return nil, ErrKeyNotExist
+	return ls.pop(k, ListRight)
+}
+
+// RPopCount 从队列k的尾部，弹出最多count个元素
+func (ls *Lists) RPopCount(k string, count int) ([]any, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.popCount(k, ListRight, count)
+}
+
+// BLPop 从多个队列的头部弹出一个元素，所有队列都为空时阻塞等待
+// timeout 为0时一直等待，直到ctx结束
+// return key string 为弹出元素的队列
+func (ls *Lists) BLPop(ctx context.Context, timeout time.Duration, keys ...string) (string, any, error) {
+	w := newListWaiter(keys, ListLeft)
+	if err := ls.block(ctx, timeout, w); err != nil {
+		return "", nil, err
 	}
-	return l.RPop()
+	return w.key, w.value, nil
+}
+
+// BRPop 从多个队列的尾部弹出一个元素，所有队列都为空时阻塞等待
+// timeout 为0时一直等待，直到ctx结束
+// return key string 为弹出元素的队列
+func (ls *Lists) BRPop(ctx context.Context, timeout time.Duration, keys ...string) (string, any, error) {
+	w := newListWaiter(keys, ListRight)
+	if err := ls.block(ctx, timeout, w); err != nil {
+		return "", nil, err
+	}
+	return w.key, w.value, nil
+}
+
+// BLMove 从队列src的from端弹出一个元素，添加到队列dst的to端，src为空时阻塞等待
+// timeout 为0时一直等待，直到ctx结束
+func (ls *Lists) BLMove(ctx context.Context, src, dst string, from, to ListDirection, timeout time.Duration) (any, error) {
+	w := newListWaiter([]string{src}, from)
+	w.move, w.dst, w.to = true, dst, to
+	if err := ls.block(ctx, timeout, w); err != nil {
+		return nil, err
+	}
+	return w.value, nil
 }
 
 // LLen 获取队列k的长度
 func (ls *Lists) LLen(k string) int {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
-	l, exist := ls.items[k]
-	if !exist {
-		return 0
-	} else if l.isExpired() {
-		ls.del(k)
+	l := ls.get(k)
+	if l == nil {
 		return 0
 	}
 	return l.LLen()
 }
 
-// LRange 获取队列元素列表
+// LRange 获取队列[start, stop]区间内的元素，返回的切片为副本
+// 区间超出范围时按Redis的规则截断，区间为空时返回空切片
 func (ls *Lists) LRange(k string, start, stop int) ([]any, error) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
-	if start > stop {
-		return nil, ErrStartStop
+	l := ls.get(k)
+	if l == nil {
+		return nil, ErrKeyNotExist
+	}
+	return l.LRange(start, stop)
+}
+
+// LIndex 获取队列中下标为index的元素，index为负数时从尾部开始计算
+func (ls *Lists) LIndex(k string, index int) (any, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l := ls.get(k)
+	if l == nil {
+		return nil, ErrKeyNotExist
+	}
+	return l.LIndex(index)
+}
+
+// LSet 设置队列中下标为index的元素
+func (ls *Lists) LSet(k string, index int, v any) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l := ls.get(k)
+	if l == nil {
+		return ErrKeyNotExist
+	}
+	return l.LSet(index, v)
+}
+
+// LInsert 在队列中第一个等于pivot的元素之前或之后插入元素v
+// return int 为插入后队列的长度，k不存在时返回0，pivot不存在时返回-1
+func (ls *Lists) LInsert(k string, where ListPosition, pivot, v any) (int, error) {
+	if where != ListBefore && where != ListAfter {
+		return 0, ErrPosition
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l := ls.get(k)
+	if l == nil {
+		return 0, nil
+	}
+	return l.LInsert(where, pivot, v), nil
+}
+
+// LRem 从队列中删除count个等于v的元素
+// count 大于0时从头部开始删除，小于0时从尾部开始删除，等于0时删除全部
+// return int 为删除的元素数量
+func (ls *Lists) LRem(k string, count int, v any) int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l := ls.get(k)
+	if l == nil {
+		return 0
+	}
+	removed := l.LRem(count, v)
+	ls.delIfEmpty(k, l)
+	return removed
+}
+
+// LTrim 只保留队列中[start, stop]区间内的元素，区间为空时删除k
+func (ls *Lists) LTrim(k string, start, stop int) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l := ls.get(k)
+	if l == nil {
+		return
+	}
+	l.LTrim(start, stop)
+	ls.delIfEmpty(k, l)
+}
+
+// LPos 获取队列中等于v的元素的下标，不存在时返回-1
+func (ls *Lists) LPos(k string, v any, args LPosArgs) (int, error) {
+	positions, err := ls.LPosCount(k, v, 1, args)
+	if err != nil || len(positions) == 0 {
+		return -1, err
+	}
+	return positions[0], nil
+}
+
+// LPosCount 获取队列中最多count个等于v的元素的下标，count为0时返回全部
+func (ls *Lists) LPosCount(k string, v any, count int, args LPosArgs) ([]int, error) {
+	if args.Rank == 0 {
+		args.Rank = 1
+	}
+	if count < 0 || args.MaxLen < 0 {
+		return nil, ErrCount
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	l := ls.get(k)
+	if l == nil {
+		return nil, nil
+	}
+	return l.LPos(v, count, args), nil
+}
+
+// LMove 从队列src的from端弹出一个元素，添加到队列dst的to端
+func (ls *Lists) LMove(src, dst string, from, to ListDirection) (any, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	v, err := ls.pop(src, from)
+	if err != nil {
+		return nil, err
+	}
+	ls.push(dst, to, v)
+	return v, nil
+}
+
+// get 获取k对应的未过期列表，不存在时返回nil
+func (ls *Lists) get(k string) *List {
+	if !ls.exist(k) {
+		return nil
+	}
+	return ls.items[k]
+}
+
+// push 向队列k的where端依次添加元素，全部添加后再唤醒等待该队列的调用方
+// return n int 为添加后队列的长度，exist bool 表示添加前k是否存在
+func (ls *Lists) push(k string, where ListDirection, vs ...any) (n int, exist bool) {
+	l := ls.get(k)
+	exist = l != nil
+	if len(vs) == 0 {
+		if exist {
+			n = l.LLen()
+		}
+		return n, exist
 	}
-	l, exist := ls.items[k]
 	if !exist {
+		l = newList()
+		ls.items[k] = l
+	}
+	for _, v := range vs {
+		if where == ListLeft {
+			l.LPush(v)
+		} else {
+			l.RPush(v)
+		}
+	}
+	n = l.LLen()
+	ls.serve(k)
+	return n, exist
+}
+
+// pop 从队列k的where端弹出一个元素，队列为空时删除k
+func (ls *Lists) pop(k string, where ListDirection) (any, error) {
+	l := ls.get(k)
+	if l == nil {
 		return nil, ErrKeyNotExist
-	} else if l.isExpired() {
+	}
+	var v any
+	var err error
+	if where == ListLeft {
+		v, err = l.LPop()
+	} else {
+		v, err = l.RPop()
+	}
+	ls.delIfEmpty(k, l)
+	return v, err
+}
+
+// delIfEmpty 队列为空时删除k
+func (ls *Lists) delIfEmpty(k string, l *List) {
+	if l.LLen() == 0 {
 		ls.del(k)
+	}
+}
+
+// popCount 从队列k的where端弹出最多count个元素，队列为空时删除k
+func (ls *Lists) popCount(k string, where ListDirection, count int) ([]any, error) {
+	if count < 0 {
+		return nil, ErrCount
+	}
+	l := ls.get(k)
+	if l == nil {
 		return nil, ErrKeyNotExist
 	}
-	return l.LRange(start, stop)
+	if count > l.LLen() {
+		count = l.LLen()
+	}
+	vs := make([]any, count)
+	for i := range vs {
+		if where == ListLeft {
+			vs[i], _ = l.LPop()
+		} else {
+			vs[i], _ = l.RPop()
+		}
+	}
+	ls.delIfEmpty(k, l)
+	return vs, nil
+}
+
+// serve 按等待的先后顺序，将队列k中的元素交给等待的调用方
+func (ls *Lists) serve(k string) {
+	for len(ls.waiters[k]) > 0 && ls.get(k) != nil {
+		w := ls.waiters[k][0]
+		ls.removeWaiter(w)
+		w.serve(ls, k)
+	}
+}
+
+// block 尝试立即弹出元素，所有队列都为空时加入等待队列
+func (ls *Lists) block(ctx context.Context, timeout time.Duration, w *listWaiter) error {
+	ls.mu.Lock()
+	for _, k := range w.keys {
+		if ls.get(k) != nil {
+			w.serve(ls, k)
+			ls.mu.Unlock()
+			return nil
+		}
+	}
+	for _, k := range w.keys {
+		ls.waiters[k] = append(ls.waiters[k], w)
+	}
+	ls.mu.Unlock()
+
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+	var err error
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeoutC:
+		err = ErrTimeout
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	// 超时与元素到达同时发生时，元素已经弹出，不能丢弃
+	if w.served {
+		return nil
+	}
+	ls.removeWaiter(w)
+	return err
+}
+
+// removeWaiter 将w从所有key的等待队列中移除
+func (ls *Lists) removeWaiter(w *listWaiter) {
+	for _, k := range w.keys {
+		queue := ls.waiters[k]
+		for i := 0; i < len(queue); i++ {
+			if queue[i] == w {
+				queue = append(queue[:i], queue[i+1:]...)
+				i--
+			}
+		}
+		if len(queue) == 0 {
+			delete(ls.waiters, k)
+		} else {
+			ls.waiters[k] = queue
+		}
+	}
 }
 
 // Del 删除一个key
@@ -186,64 +476,288 @@ func (ls *Lists) Flush() {
 	ls.items = make(map[string]*List)
 }
 
+// newListWaiter 创建一个等待队列元素的调用方
+func newListWaiter(keys []string, from ListDirection) *listWaiter {
+	return &listWaiter{
+		keys: keys,
+		from: from,
+		done: make(chan struct{}),
+	}
+}
+
+// listWaiter 阻塞等待队列元素的调用方
+// keys 等待的队列，from 弹出元素的一端
+// move 为true时，将弹出的元素添加到队列dst的to端
+// key、value 为获取到元素的队列和元素，served 为true后有效
+type listWaiter struct {
+	keys   []string
+	from   ListDirection
+	move   bool
+	dst    string
+	to     ListDirection
+	key    string
+	value  any
+	served bool
+	done   chan struct{}
+}
+
+// serve 从队列k中弹出元素交给w，调用方需持有Lists的锁
+func (w *listWaiter) serve(ls *Lists, k string) {
+	w.key = k
+	w.value, _ = ls.pop(k, w.from)
+	if w.move {
+		ls.push(w.dst, w.to, w.value)
+	}
+	w.served = true
+	close(w.done)
+}
+
 // newList 创建一个列表的实例
 func newList() *List {
 	return &List{
-		items:      make([]any, 0, 0),
+		items:      make([]any, minListCap),
 		expiration: DefaultExpiration,
 	}
 }
 
-// List 列表集合
+// List 列表集合，使用环形缓冲区实现的双端队列
+// 两端的添加、弹出均为O(1)，按下标访问为O(1)
+// items 的长度始终为2的幂，head 为第一个元素的位置，size 为元素数量
 type List struct {
 	items      []any
+	head       int
+	size       int
 	expiration int64
 }
 
 // LPush 从队列的头部，添加一个元素v
 func (l *List) LPush(v any) {
-	l.items = append([]any{v}, l.items...)
+	l.grow()
+	l.head = (l.head - 1) & (len(l.items) - 1)
+	l.items[l.head] = v
+	l.size++
 }
 
 // LPop 从队列的头部，弹出一个元素
 func (l *List) LPop() (any, error) {
-	var v any
-	v, l.items = l.items[0], l.items[1:]
+	if l.size == 0 {
+		return nil, ErrEmptyList
+	}
+	v := l.items[l.head]
+	l.items[l.head] = nil
+	l.head = (l.head + 1) & (len(l.items) - 1)
+	l.size--
+	l.shrink()
 	return v, nil
 }
 
 // RPush 从队列的尾部，添加一个元素
 func (l *List) RPush(v any) {
-	l.items = append(l.items, v)
+	l.grow()
+	l.items[(l.head+l.size)&(len(l.items)-1)] = v
+	l.size++
 }
 
 // RPop 从队列的尾部，弹出一个元素
 func (l *List) RPop() (any, error) {
-	var v any
-	v, l.items = l.items[len(l.items)-1], l.items[:len(l.items)-1]
+	if l.size == 0 {
+		return nil, ErrEmptyList
+	}
+	i := (l.head + l.size - 1) & (len(l.items) - 1)
+	v := l.items[i]
+	l.items[i] = nil
+	l.size--
+	l.shrink()
 	return v, nil
 }
 
 // LLen 获取队列的长度
 func (l *List) LLen() int {
-	return len(l.items)
+	return l.size
 }
 
-// LRange 获取队列元素列表
+// LRange 获取队列[start, stop]区间内的元素，返回的切片为副本
 func (l *List) LRange(start, stop int) ([]any, error) {
-	if start < 0 {
-		start = len(l.items) + start
+	start, stop, ok := rangeIndex(start, stop, l.size)
+	if !ok {
+		return []any{}, nil
 	}
-	if stop < 0 {
-		stop = len(l.items) + stop
+	return l.slice(start, stop+1), nil
+}
+
+// LIndex 获取下标为index的元素
+func (l *List) LIndex(index int) (any, error) {
+	i, ok := l.index(index)
+	if !ok {
+		return nil, ErrIndex
 	}
-	if start > stop || start >= len(l.items) {
-		return nil, ErrStartStop
+	return l.at(i), nil
+}
+
+// LSet 设置下标为index的元素
+func (l *List) LSet(index int, v any) error {
+	i, ok := l.index(index)
+	if !ok {
+		return ErrIndex
 	}
-	if stop >= len(l.items) {
-		stop = len(l.items) - 1
+	l.items[(l.head+i)&(len(l.items)-1)] = v
+	return nil
+}
+
+// LInsert 在第一个等于pivot的元素之前或之后插入元素v
+// return int 为插入后的长度，pivot不存在时返回-1
+func (l *List) LInsert(where ListPosition, pivot, v any) int {
+	for i := 0; i < l.size; i++ {
+		if !valueEqual(l.at(i), pivot) {
+			continue
+		}
+		if where == ListAfter {
+			i++
+		}
+		items := l.slice(0, l.size)
+		items = append(items[:i], append([]any{v}, items[i:]...)...)
+		l.reset(items)
+		return l.size
+	}
+	return -1
+}
+
+// LRem 删除count个等于v的元素
+// return int 为删除的元素数量
+func (l *List) LRem(count int, v any) int {
+	items := l.slice(0, l.size)
+	remove := make([]bool, len(items))
+	var removed int
+	if count >= 0 {
+		for i := 0; i < len(items) && (count == 0 || removed < count); i++ {
+			if valueEqual(items[i], v) {
+				remove[i] = true
+				removed++
+			}
+		}
+	} else {
+		for i := len(items) - 1; i >= 0 && removed < -count; i-- {
+			if valueEqual(items[i], v) {
+				remove[i] = true
+				removed++
+			}
+		}
+	}
+	if removed == 0 {
+		return 0
+	}
+	kept := items[:0]
+	for i, item := range items {
+		if !remove[i] {
+			kept = append(kept, item)
+		}
+	}
+	l.reset(kept)
+	return removed
+}
+
+// LTrim 只保留[start, stop]区间内的元素
+func (l *List) LTrim(start, stop int) {
+	start, stop, ok := rangeIndex(start, stop, l.size)
+	if !ok {
+		l.reset(nil)
+		return
+	}
+	for ; start > 0; start-- {
+		_, _ = l.LPop()
+		stop--
+	}
+	for l.size > stop+1 {
+		_, _ = l.RPop()
+	}
+}
+
+// LPos 获取最多count个等于v的元素的下标，count为0时返回全部
+// args.Rank 为负数时从尾部开始查找，跳过前|Rank|-1个匹配的元素
+// args.MaxLen 大于0时最多比较MaxLen个元素
+func (l *List) LPos(v any, count int, args LPosArgs) []int {
+	skip := args.Rank - 1
+	step, i := 1, 0
+	if args.Rank < 0 {
+		skip = -args.Rank - 1
+		step, i = -1, l.size-1
+	}
+	var positions []int
+	for compared := 0; i >= 0 && i < l.size; i, compared = i+step, compared+1 {
+		if args.MaxLen > 0 && compared >= args.MaxLen {
+			break
+		}
+		if !valueEqual(l.at(i), v) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		positions = append(positions, i)
+		if count > 0 && len(positions) >= count {
+			break
+		}
+	}
+	return positions
+}
+
+// index 将index转换为从头部开始的下标
+func (l *List) index(index int) (int, bool) {
+	if index < 0 {
+		index = l.size + index
+	}
+	return index, index >= 0 && index < l.size
+}
+
+// at 获取下标为i的元素，i需在[0, size)范围内
+func (l *List) at(i int) any {
+	return l.items[(l.head+i)&(len(l.items)-1)]
+}
+
+// slice 复制下标在[start, end)范围内的元素
+func (l *List) slice(start, end int) []any {
+	items := make([]any, end-start)
+	for i := range items {
+		items[i] = l.at(start + i)
+	}
+	return items
+}
+
+// grow 缓冲区已满时，扩容为原来的两倍
+func (l *List) grow() {
+	if l.size == len(l.items) {
+		l.resize(len(l.items) * 2)
 	}
-	return l.items[start : stop+1], nil
+}
+
+// shrink 元素数量不足容量的1/4时，缩容为原来的一半，释放内存
+func (l *List) shrink() {
+	if len(l.items) > minListCap && l.size < len(l.items)/4 {
+		l.resize(len(l.items) / 2)
+	}
+}
+
+// reset 使用items重建缓冲区
+func (l *List) reset(items []any) {
+	n := minListCap
+	for n < len(items) {
+		n *= 2
+	}
+	l.items = make([]any, n)
+	copy(l.items, items)
+	l.head = 0
+	l.size = len(items)
+}
+
+// resize 将元素复制到容量为n的新缓冲区中，n需为2的幂
+func (l *List) resize(n int) {
+	items := make([]any, n)
+	for i := 0; i < l.size; i++ {
+		items[i] = l.at(i)
+	}
+	l.items = items
+	l.head = 0
 }
 
 // isExpired 判断一个元素是否过期
@@ -253,3 +767,32 @@ func (l *List) isExpired() bool {
 	}
 	return false
 }
+
+// LPosArgs LPos的查找参数
+// Rank 从第几个匹配的元素开始返回，为负数时从尾部开始查找，为0时视为1
+// MaxLen 最多比较的元素数量，为0时不限制
+type LPosArgs struct {
+	Rank   int
+	MaxLen int
+}
+
+// valueEqual 判断两个列表元素是否相等
+// []byte 按内容比较，结构体、数组以及切片、map等不可比较的类型按深度比较
+// 结构体和数组的接口字段可能保存不可比较的值，使用==比较会panic
+func valueEqual(a, b any) bool {
+	if ab, ok := a.([]byte); ok {
+		bb, ok := b.([]byte)
+		return ok && bytes.Equal(ab, bb)
+	}
+	if a == nil || b == nil {
+		return a == b
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) {
+		return false
+	}
+	if k := t.Kind(); t.Comparable() && k != reflect.Struct && k != reflect.Array {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
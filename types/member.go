@@ -0,0 +1,58 @@
+package types
+
+import "strconv"
+
+// toMember 将集合、有序集合的元素转换为统一的字符串编码
+// 与Redis一致，整数、浮点数按十进制字符串存储，[]byte 按字符串存储
+// 切片、map 等其他类型返回 ErrMemberType
+func toMember(m any) (string, error) {
+	switch v := m.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	}
+	return "", ErrMemberType
+}
+
+// toMembers 批量转换元素，任意一个元素不合法时返回错误
+func toMembers(ms []any) ([]string, error) {
+	members := make([]string, len(ms))
+	for i, m := range ms {
+		member, err := toMember(m)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = member
+	}
+	return members, nil
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"math"
+	"strconv"
+)
+
+// toInt64 将存储的值转换为int64，用于计数
+// nil 视为0，字符串和[]byte按十进制整数解析，浮点数需为整数值
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(n), nil
+	case int8:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case uint:
+		return uintToInt64(uint64(n))
+	case uint8:
+		return int64(n), nil
+	case uint16:
+		return int64(n), nil
+	case uint32:
+		return int64(n), nil
+	case uint64:
+		return uintToInt64(n)
+	case float32:
+		return floatToInt64(float64(n))
+	case float64:
+		return floatToInt64(n)
+	case string:
+		return parseInt64(n)
+	case []byte:
+		return parseInt64(string(n))
+	}
+	return 0, ErrNotInteger
+}
+
+// toFloat64 将存储的值转换为float64，用于浮点数计数
+// nil 视为0，字符串和[]byte按十进制浮点数解析
+func toFloat64(v any) (float64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case float32:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string:
+		return parseFloat64(n)
+	case []byte:
+		return parseFloat64(string(n))
+	}
+	i, err := toInt64(v)
+	if err != nil {
+		return 0, ErrNotFloat
+	}
+	return float64(i), nil
+}
+
+// addInt64 计算a+b，结果溢出时返回 ErrOverflow
+func addInt64(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+// addFloat64 计算a+b，结果为NaN或Inf时返回 ErrNotFloat
+func addFloat64(a, b float64) (float64, error) {
+	sum := a + b
+	if math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return 0, ErrNotFloat
+	}
+	return sum, nil
+}
+
+func uintToInt64(n uint64) (int64, error) {
+	if n > math.MaxInt64 {
+		return 0, ErrNotInteger
+	}
+	return int64(n), nil
+}
+
+func floatToInt64(f float64) (int64, error) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, ErrNotInteger
+	}
+	return int64(f), nil
+}
+
+func parseInt64(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+	return n, nil
+}
+
+func parseFloat64(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, ErrNotFloat
+	}
+	return f, nil
+}
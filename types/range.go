@@ -0,0 +1,22 @@
+package types
+
+// rangeIndex 按Redis的规则，将[start, stop]转换为长度为n的序列中的下标区间
+// 负数表示从尾部开始计算，超出范围的部分会被截断，ok 为false时区间为空
+func rangeIndex(start, stop, n int) (int, int, bool) {
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop || start >= n {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
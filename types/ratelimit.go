@@ -0,0 +1,342 @@
+package types
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// NewRateLimiters 创建限流器类型实例
+func NewRateLimiters() *RateLimiters {
+	return &RateLimiters{
+		items: make(map[string]*RateLimiter),
+	}
+}
+
+// RateLimiters 限流器类型数据结构
+type RateLimiters struct {
+	mu    sync.Mutex
+	items map[string]*RateLimiter
+}
+
+// RateLimitArgs 滑动窗口限流的参数
+// Limit 为Window内允许的数量；N 为本次请求的数量，为0时为1
+// Algorithm 为 RateLimitSlidingCounter 或 RateLimitSlidingLog，为空时为 RateLimitSlidingCounter
+type RateLimitArgs struct {
+	Limit     int64
+	Window    time.Duration
+	N         int64
+	Algorithm RateLimitAlgorithm
+}
+
+// TokenBucketArgs 令牌桶限流的参数
+// Rate 为每秒生成的令牌数量，Burst 为桶的容量，即允许的最大突发数量
+// Algorithm 为 RateLimitTokenBucket 或 RateLimitGCRA，为空时为 RateLimitTokenBucket
+type TokenBucketArgs struct {
+	Rate      float64
+	Burst     int64
+	Algorithm RateLimitAlgorithm
+}
+
+// RateLimitResult 限流的结果
+// Remaining 为之后还允许的数量；RetryAfter 为被拒绝时需要等待的时间，允许时为0
+// ResetAfter 为恢复到没有请求的状态需要的时间
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int64
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// Exist 判断k是否存在
+func (rs *RateLimiters) Exist(k string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.exist(k)
+}
+
+// exist 判断k是否存在
+func (rs *RateLimiters) exist(k string) bool {
+	l, exist := rs.items[k]
+	if !exist {
+		return false
+	}
+	if l.isExpired() {
+		rs.del(k)
+		return false
+	}
+	return true
+}
+
+// SlidingWindow 按滑动窗口判断是否允许args.N个请求，允许时计入窗口
+// k不存在或算法不同时重新创建，k在窗口结束后自动过期
+// return exist bool 表示请求前k是否存在
+func (rs *RateLimiters) SlidingWindow(k string, args RateLimitArgs) (*RateLimitResult, bool, error) {
+	if args.N == 0 {
+		args.N = 1
+	}
+	if args.Algorithm == "" {
+		args.Algorithm = RateLimitSlidingCounter
+	}
+	if args.Limit <= 0 || args.Window <= 0 || args.N < 0 || args.N > args.Limit ||
+		(args.Algorithm != RateLimitSlidingCounter && args.Algorithm != RateLimitSlidingLog) {
+		return nil, false, ErrRateLimit
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	l, exist := rs.limiter(k, args.Algorithm)
+	now := time.Now().UnixNano()
+	if args.Algorithm == RateLimitSlidingLog {
+		return l.slidingLog(now, args), exist, nil
+	}
+	return l.slidingCounter(now, args), exist, nil
+}
+
+// TokenBucket 按令牌桶判断是否允许n个请求，允许时消耗n个令牌
+// k不存在或算法不同时按满桶创建，k在令牌恢复满桶后自动过期
+// return exist bool 表示请求前k是否存在
+func (rs *RateLimiters) TokenBucket(k string, args TokenBucketArgs, n int64) (*RateLimitResult, bool, error) {
+	if args.Algorithm == "" {
+		args.Algorithm = RateLimitTokenBucket
+	}
+	if args.Rate <= 0 || args.Burst <= 0 || n < 0 || n > args.Burst ||
+		(args.Algorithm != RateLimitTokenBucket && args.Algorithm != RateLimitGCRA) {
+		return nil, false, ErrRateLimit
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	l, exist := rs.limiter(k, args.Algorithm)
+	now := time.Now().UnixNano()
+	if args.Algorithm == RateLimitGCRA {
+		return l.gcra(now, args, n), exist, nil
+	}
+	return l.tokenBucket(now, args, n), exist, nil
+}
+
+// limiter 获取k对应的限流器，不存在或算法不同时重新创建
+func (rs *RateLimiters) limiter(k string, algorithm RateLimitAlgorithm) (*RateLimiter, bool) {
+	l := rs.get(k)
+	exist := l != nil
+	if !exist || l.algorithm != algorithm {
+		l = &RateLimiter{algorithm: algorithm, expiration: DefaultExpiration}
+		rs.items[k] = l
+	}
+	return l, exist
+}
+
+// get 获取k对应的未过期限流器，不存在时返回nil
+func (rs *RateLimiters) get(k string) *RateLimiter {
+	if !rs.exist(k) {
+		return nil
+	}
+	return rs.items[k]
+}
+
+// Del 删除一个key
+func (rs *RateLimiters) Del(k string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.del(k)
+}
+
+func (rs *RateLimiters) del(k string) {
+	delete(rs.items, k)
+}
+
+// Expiration 设置超时时间
+func (rs *RateLimiters) Expiration(k string, d time.Duration) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !rs.exist(k) {
+		return ErrKeyNotExist
+	}
+	rs.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (rs *RateLimiters) ClearExpiration() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for key, item := range rs.items {
+		if item.isExpired() {
+			delete(rs.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (rs *RateLimiters) RandomClearExpiration() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	var counter int
+	for key, item := range rs.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(rs.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (rs *RateLimiters) Flush() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.items = make(map[string]*RateLimiter)
+}
+
+// RateLimiter 限流器的状态，时间均为纳秒
+// 滑动窗口计数：start 为当前固定窗口的起始时间，prev、curr 为上一个和当前窗口的数量
+// 滑动窗口日志：log 为窗口内的请求
+// 令牌桶：tokens 为last时刻的令牌数量；GCRA：tat 为理论上下一个请求到达的时间
+type RateLimiter struct {
+	algorithm  RateLimitAlgorithm
+	start      int64
+	prev, curr int64
+	log        []rateLimitEntry
+	tokens     float64
+	last       int64
+	tat        int64
+	expiration int64
+}
+
+// rateLimitEntry 滑动窗口日志中的一次请求
+type rateLimitEntry struct {
+	at int64
+	n  int64
+}
+
+// slidingCounter 滑动窗口计数，按上一个窗口在滑动窗口中所占的比例估算数量
+func (l *RateLimiter) slidingCounter(now int64, args RateLimitArgs) *RateLimitResult {
+	window := int64(args.Window)
+	start := now - now%window
+	if start != l.start {
+		if start-l.start == window {
+			l.prev = l.curr
+		} else {
+			l.prev = 0
+		}
+		l.start, l.curr = start, 0
+	}
+	weight := float64(window-(now-start)) / float64(window)
+	count := float64(l.prev)*weight + float64(l.curr)
+	res := &RateLimitResult{}
+	if count+float64(args.N) <= float64(args.Limit) {
+		l.curr += args.N
+		count += float64(args.N)
+		res.Allowed = true
+	} else if l.curr+args.N <= args.Limit {
+		// 等待上一个窗口的比例降低
+		w := float64(args.Limit-l.curr-args.N) / float64(l.prev)
+		res.RetryAfter = time.Duration(start + window - int64(w*float64(window)) - now)
+	} else {
+		// 等待下一个窗口，当前窗口成为上一个窗口
+		w := float64(args.Limit-args.N) / float64(l.curr)
+		res.RetryAfter = time.Duration(start + 2*window - int64(w*float64(window)) - now)
+	}
+	res.Remaining = int64(math.Max(0, float64(args.Limit)-math.Ceil(count)))
+	if l.curr > 0 {
+		res.ResetAfter = time.Duration(start + 2*window - now)
+	} else if l.prev > 0 {
+		res.ResetAfter = time.Duration(start + window - now)
+	}
+	l.expiration = now + int64(res.ResetAfter)
+	return res
+}
+
+// slidingLog 滑动窗口日志，记录窗口内的每次请求
+func (l *RateLimiter) slidingLog(now int64, args RateLimitArgs) *RateLimitResult {
+	window := int64(args.Window)
+	i := 0
+	for i < len(l.log) && l.log[i].at <= now-window {
+		i++
+	}
+	l.log = l.log[i:]
+	var count int64
+	for _, e := range l.log {
+		count += e.n
+	}
+	res := &RateLimitResult{}
+	if count+args.N <= args.Limit {
+		l.log = append(l.log, rateLimitEntry{at: now, n: args.N})
+		count += args.N
+		res.Allowed = true
+	} else {
+		// 等待最早的请求移出窗口，直到剩余数量足够
+		need := count + args.N - args.Limit
+		for _, e := range l.log {
+			if need -= e.n; need <= 0 {
+				res.RetryAfter = time.Duration(e.at + window - now)
+				break
+			}
+		}
+	}
+	res.Remaining = args.Limit - count
+	if len(l.log) > 0 {
+		res.ResetAfter = time.Duration(l.log[len(l.log)-1].at + window - now)
+	}
+	l.expiration = now + int64(res.ResetAfter)
+	return res
+}
+
+// tokenBucket 令牌桶，令牌按rate持续生成，最多为burst个
+func (l *RateLimiter) tokenBucket(now int64, args TokenBucketArgs, n int64) *RateLimitResult {
+	if l.last == 0 {
+		l.tokens = float64(args.Burst)
+	} else {
+		elapsed := float64(now-l.last) / float64(time.Second)
+		l.tokens = math.Min(float64(args.Burst), l.tokens+elapsed*args.Rate)
+	}
+	l.last = now
+	res := &RateLimitResult{}
+	if l.tokens >= float64(n) {
+		l.tokens -= float64(n)
+		res.Allowed = true
+	} else {
+		res.RetryAfter = rateDuration(float64(n)-l.tokens, args.Rate)
+	}
+	res.Remaining = int64(l.tokens)
+	res.ResetAfter = rateDuration(float64(args.Burst)-l.tokens, args.Rate)
+	l.expiration = now + int64(res.ResetAfter)
+	return res
+}
+
+// gcra 通用信元速率算法，只记录理论到达时间，与令牌桶的结果等价
+// 每个请求使tat增加一个间隔，tat超出当前时间的部分不能超过burst个间隔
+func (l *RateLimiter) gcra(now int64, args TokenBucketArgs, n int64) *RateLimitResult {
+	interval := float64(time.Second) / args.Rate
+	tolerance := int64(interval * float64(args.Burst))
+	tat := l.tat
+	if tat < now {
+		tat = now
+	}
+	newTat := tat + int64(interval*float64(n))
+	res := &RateLimitResult{}
+	if allowAt := newTat - tolerance; allowAt > now {
+		res.RetryAfter = time.Duration(allowAt - now)
+	} else {
+		tat = newTat
+		res.Allowed = true
+	}
+	l.tat = tat
+	res.Remaining = int64(float64(now-tat+tolerance) / interval)
+	res.ResetAfter = time.Duration(tat - now)
+	l.expiration = tat
+	return res
+}
+
+// isExpired 判断一个元素是否过期
+func (l *RateLimiter) isExpired() bool {
+	if l.expiration != DefaultExpiration && time.Now().UnixNano() > l.expiration {
+		return true
+	}
+	return false
+}
+
+// rateDuration 按每秒rate个的速度生成n个需要的时间
+func rateDuration(n, rate float64) time.Duration {
+	return time.Duration(math.Ceil(n / rate * float64(time.Second)))
+}
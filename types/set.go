@@ -1,7 +1,7 @@
 package types
 
 import (
-	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -40,27 +40,39 @@ func (ss *Sets) exist(k string) bool {
 }
 
 // SAdd 向集合中添加一个元素
-func (ss *Sets) SAdd(k string, m any) bool {
+// return exist bool 表示添加前k是否存在
+func (ss *Sets) SAdd(k string, m any) (bool, error) {
+	member, err := toMember(m)
+	if err != nil {
+		return false, err
+	}
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
-	s, exist := ss.items[k]
+	s := ss.get(k)
+	exist := s != nil
 	if !exist {
 		s = newSet()
 	}
-	s.SAdd(m)
+	s.SAdd(member)
 	ss.items[k] = s
-	return exist
+	return exist, nil
 }
 
 // SRem 从集合中，删除一个元素
-func (ss *Sets) SRem(k, m string) {
+func (ss *Sets) SRem(k string, m any) error {
+	member, err := toMember(m)
+	if err != nil {
+		return err
+	}
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 	s, exist := ss.items[k]
 	if exist {
-		s.SRem(m)
+		s.SRem(member)
+		ss.delIfEmpty(k, s)
 	}
+	return nil
 }
 
 // SMembers 获取集合中所有的元素列表
@@ -79,6 +91,10 @@ func (ss *Sets) SMembers(k string) ([]any, error) {
 
 // SIsMember 判断m是否为集合中的元素
 func (ss *Sets) SIsMember(k string, m any) (bool, error) {
+	member, err := toMember(m)
+	if err != nil {
+		return false, err
+	}
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 	s, exist := ss.items[k]
@@ -88,7 +104,7 @@ func (ss *Sets) SIsMember(k string, m any) (bool, error) {
 		ss.del(k)
 		return false, ErrSetKey
 	}
-	return s.SIsMember(m)
+	return s.SIsMember(member)
 }
 
 // SCard 统计集合中元素数量
@@ -99,51 +115,283 @@ func (ss *Sets) SCard(k string) int {
 	if !exist {
 		return 0
 	} else if s.isExpired() {
-		fmt.Println("111")
 		ss.del(k)
 		return 0
 	}
 	return s.SCard()
 }
 
-// SUnion 获取集合s1和s2的并集
-func (ss *Sets) SUnion(k1, k2 string) *Set {
+// SMIsMember 批量判断元素是否为集合中的元素
+func (ss *Sets) SMIsMember(k string, ms ...any) ([]bool, error) {
+	members, err := toMembers(ms)
+	if err != nil {
+		return nil, err
+	}
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
-	s1, exist1 := ss.items[k1]
-	if exist1 && s1.isExpired() {
-		exist1 = false
+	result := make([]bool, len(members))
+	s := ss.get(k)
+	if s == nil {
+		return result, nil
 	}
-	s2, exist2 := ss.items[k2]
-	if exist2 && s2.isExpired() {
-		exist2 = false
+	for i, m := range members {
+		result[i], _ = s.SIsMember(m)
 	}
-	if !exist1 && !exist2 {
-		return nil
-	} else if exist1 && !exist2 {
-		return s1
-	} else if !exist1 && exist2 {
-		return s2
+	return result, nil
+}
+
+// SPop 从集合中随机弹出count个元素，集合为空时删除k
+func (ss *Sets) SPop(k string, count int) ([]any, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	if s == nil {
+		return nil, ErrSetKey
+	}
+	if count < 0 {
+		return nil, ErrCount
+	}
+	popped := s.SPop(count)
+	ss.delIfEmpty(k, s)
+	return popped, nil
+}
+
+// SRandMember 从集合中随机获取count个元素
+// count 为负数时允许返回重复的元素
+func (ss *Sets) SRandMember(k string, count int) ([]any, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	if s == nil {
+		return nil, ErrSetKey
+	}
+	return s.SRandMember(count), nil
+}
+
+// SScan 按游标遍历集合中的元素
+// match 为元素需匹配的glob模式，为空时不过滤；count 为本次检查的元素数量
+// 返回本次遍历到的元素，以及下一次的游标，游标为0表示遍历结束
+func (ss *Sets) SScan(k string, cursor uint64, match string, count int) ([]any, uint64, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	if s == nil {
+		return nil, 0, ErrSetKey
+	}
+	members := make([]any, 0)
+	next := s.members.scanMatch(cursor, match, count, func(e *dictEntry[struct{}]) {
+		members = append(members, e.key)
+	})
+	return members, next, nil
+}
+
+// SMove 将元素m从集合src移动到集合dst
+// moved 表示是否移动成功，exist 表示移动前dst是否存在
+func (ss *Sets) SMove(src, dst string, m any) (moved, exist bool, err error) {
+	member, err := toMember(m)
+	if err != nil {
+		return false, false, err
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(src)
+	if s == nil || !s.remove(member) {
+		return false, ss.exist(dst), nil
+	}
+	ss.delIfEmpty(src, s)
+	d := ss.get(dst)
+	if exist = d != nil; !exist {
+		d = newSet()
+		ss.items[dst] = d
 	}
-	return s1.SUnion(s2)
+	d.SAdd(member)
+	return true, exist, nil
 }
 
-// SInter 获取集合s1和s2的交集
-func (ss *Sets) SInter(k1, k2 string) *Set {
+// SUnion 获取多个集合的并集
+func (ss *Sets) SUnion(keys ...string) []any {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
-	s1, exist1 := ss.items[k1]
-	if exist1 && s1.isExpired() {
-		exist1 = false
+	members, _ := ss.sUnion(keys).SMembers()
+	return members
+}
+
+// SUnionStore 计算多个集合的并集，并存储到dst中
+// return int 为dst中元素的数量
+func (ss *Sets) SUnionStore(dst string, keys ...string) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.store(dst, ss.sUnion(keys))
+}
+
+// SInter 获取多个集合的交集
+func (ss *Sets) SInter(keys ...string) []any {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	members, _ := ss.sInter(keys).SMembers()
+	return members
+}
+
+// SInterStore 计算多个集合的交集，并存储到dst中
+// return int 为dst中元素的数量
+func (ss *Sets) SInterStore(dst string, keys ...string) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.store(dst, ss.sInter(keys))
+}
+
+// SInterCard 统计多个集合交集的元素数量
+// limit 大于0时，数量达到limit后停止计算
+func (ss *Sets) SInterCard(limit int, keys ...string) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	sets, ok := ss.gets(keys)
+	if !ok {
+		return 0
+	}
+	var count int
+	for _, e := range sets[0].members.entries {
+		if isMemberOfAll(e.key, sets[1:]) {
+			count++
+			if limit > 0 && count >= limit {
+				break
+			}
+		}
+	}
+	return count
+}
+
+// SDiff 获取第一个集合与其他集合的差集
+func (ss *Sets) SDiff(keys ...string) []any {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	members, _ := ss.sDiff(keys).SMembers()
+	return members
+}
+
+// SDiffStore 计算第一个集合与其他集合的差集，并存储到dst中
+// return int 为dst中元素的数量
+func (ss *Sets) SDiffStore(dst string, keys ...string) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.store(dst, ss.sDiff(keys))
+}
+
+// sUnion -
+func (ss *Sets) sUnion(keys []string) *Set {
+	union := newSet()
+	for _, k := range keys {
+		if s := ss.get(k); s != nil {
+			for _, e := range s.members.entries {
+				union.SAdd(e.key)
+			}
+		}
+	}
+	return union
+}
+
+// sInter -
+func (ss *Sets) sInter(keys []string) *Set {
+	inter := newSet()
+	sets, ok := ss.gets(keys)
+	if !ok {
+		return inter
+	}
+	for _, e := range sets[0].members.entries {
+		if isMemberOfAll(e.key, sets[1:]) {
+			inter.SAdd(e.key)
+		}
+	}
+	return inter
+}
+
+// sDiff -
+func (ss *Sets) sDiff(keys []string) *Set {
+	diff := newSet()
+	if len(keys) == 0 {
+		return diff
+	}
+	first := ss.get(keys[0])
+	if first == nil {
+		return diff
 	}
-	s2, exist2 := ss.items[k2]
-	if exist2 && s2.isExpired() {
-		exist2 = false
+	others := make([]*Set, 0, len(keys)-1)
+	for _, k := range keys[1:] {
+		if s := ss.get(k); s != nil {
+			others = append(others, s)
+		}
 	}
-	if !exist1 || !exist2 {
+	for _, e := range first.members.entries {
+		if !isMemberOfAny(e.key, others) {
+			diff.SAdd(e.key)
+		}
+	}
+	return diff
+}
+
+// get 获取k对应的未过期集合，不存在时返回nil
+func (ss *Sets) get(k string) *Set {
+	if !ss.exist(k) {
 		return nil
 	}
-	return s1.SInter(s2)
+	return ss.items[k]
+}
+
+// gets 获取多个key对应的集合，元素最少的集合排在第一个
+// 任意一个key不存在时，ok为false
+func (ss *Sets) gets(keys []string) (sets []*Set, ok bool) {
+	if len(keys) == 0 {
+		return nil, false
+	}
+	sets = make([]*Set, 0, len(keys))
+	for _, k := range keys {
+		s := ss.get(k)
+		if s == nil {
+			return nil, false
+		}
+		sets = append(sets, s)
+		if last := len(sets) - 1; s.SCard() < sets[0].SCard() {
+			sets[0], sets[last] = sets[last], sets[0]
+		}
+	}
+	return sets, true
+}
+
+// delIfEmpty 集合为空时删除k
+func (ss *Sets) delIfEmpty(k string, s *Set) {
+	if s.SCard() == 0 {
+		ss.del(k)
+	}
+}
+
+// store 将运算结果存储到dst中，结果为空时删除dst
+func (ss *Sets) store(dst string, s *Set) int {
+	n := s.SCard()
+	if n == 0 {
+		ss.del(dst)
+		return 0
+	}
+	ss.items[dst] = s
+	return n
+}
+
+// scores 获取多个集合中的元素，score均为1，不存在的key被忽略，供有序集合运算使用
+func (ss *Sets) scores(keys []string) map[string]map[string]float64 {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	result := make(map[string]map[string]float64, len(keys))
+	for _, k := range keys {
+		s, exist := ss.items[k]
+		if !exist || s.isExpired() {
+			continue
+		}
+		scores := make(map[string]float64, s.SCard())
+		for _, e := range s.members.entries {
+			scores[e.key] = 1
+		}
+		result[k] = scores
+	}
+	return result
 }
 
 // Del 删除一个key
@@ -205,68 +453,95 @@ func (ss *Sets) Flush() {
 // newSet 创建一个集合的实例
 func newSet() *Set {
 	return &Set{
-		sets:       make(map[any]struct{}),
+		members:    newDict[struct{}](),
 		expiration: DefaultExpiration,
 	}
 }
 
 // Set 缓存集合
+// members 存储所有元素，支持随机访问和游标遍历
 type Set struct {
-	sets       map[any]struct{}
+	members    *dict[struct{}]
 	expiration int64
 }
 
 // SAdd 向集合中添加一个元素
-func (s *Set) SAdd(m any) {
-	s.sets[m] = struct{}{}
+func (s *Set) SAdd(m string) {
+	s.members.set(m, struct{}{})
 }
 
 // SRem 从集合中，删除一个元素
 func (s *Set) SRem(m string) {
-	delete(s.sets, m)
+	s.remove(m)
 }
 
-// SMembers 获取集合中所有的元素列表
+// SMembers 获取集合中所有的元素列表，返回的切片为副本
 func (s *Set) SMembers() ([]any, error) {
-	members := make([]any, 0, len(s.sets))
-	for m, _ := range s.sets {
-		members = append(members, m)
+	members := make([]any, s.SCard())
+	for i, e := range s.members.entries {
+		members[i] = e.key
 	}
 	return members, nil
 }
 
 // SIsMember 判断m是否为集合中的元素
-func (s *Set) SIsMember(m any) (bool, error) {
-	_, isMember := s.sets[m]
-	return isMember, nil
+func (s *Set) SIsMember(m string) (bool, error) {
+	return s.members.has(m), nil
 }
 
 // SCard 统计集合中元素数量
 func (s *Set) SCard() int {
-	return len(s.sets)
+	return s.members.len()
 }
 
-// SUnion 获取集合s1和s2的并集
-func (s *Set) SUnion(other *Set) *Set {
-	union := newSet()
-	for member, _ := range s.sets {
-		union.SAdd(member)
+// SPop 随机弹出count个元素
+func (s *Set) SPop(count int) []any {
+	if n := s.SCard(); count > n {
+		count = n
 	}
-	for member, _ := range other.sets {
-		union.SAdd(member)
+	popped := make([]any, 0, count)
+	for ; count > 0; count-- {
+		m := s.members.at(rand.Intn(s.SCard())).key
+		s.remove(m)
+		popped = append(popped, m)
 	}
-	return union
+	return popped
 }
 
-// SInter 获取集合s1和s2的交集
-func (s *Set) SInter(other *Set) *Set {
-	inter := newSet()
-	for member, _ := range other.sets {
-		if _, exist := s.sets[member]; exist {
-			inter.SAdd(member)
+// SRandMember 随机获取元素
+// count 为正数时返回不重复的元素，数量最多为集合元素数量
+// count 为负数时返回|count|个元素，元素可能重复
+func (s *Set) SRandMember(count int) []any {
+	n := s.SCard()
+	if count < 0 {
+		members := make([]any, 0, -count)
+		for ; count < 0; count++ {
+			members = append(members, s.members.at(rand.Intn(n)).key)
 		}
+		return members
 	}
-	return inter
+	if count >= n {
+		members, _ := s.SMembers()
+		return members
+	}
+	// Floyd 算法：不复制元素列表，等概率选出count个不重复的位置
+	picked := make(map[int]struct{}, count)
+	members := make([]any, 0, count)
+	for j := n - count; j < n; j++ {
+		i := rand.Intn(j + 1)
+		if _, exist := picked[i]; exist {
+			i = j
+		}
+		picked[i] = struct{}{}
+		members = append(members, s.members.at(i).key)
+	}
+	return members
+}
+
+// remove 删除一个元素
+// return bool 表示元素是否存在
+func (s *Set) remove(m string) bool {
+	return s.members.del(m)
 }
 
 // isExpired 判断一个元素是否过期
@@ -276,3 +551,23 @@ func (s *Set) isExpired() bool {
 	}
 	return false
 }
+
+// isMemberOfAny 判断m是否为任意一个集合中的元素
+func isMemberOfAny(m string, sets []*Set) bool {
+	for _, s := range sets {
+		if s.members.has(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMemberOfAll 判断m是否为所有集合中的元素
+func isMemberOfAll(m string, sets []*Set) bool {
+	for _, s := range sets {
+		if !s.members.has(m) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,1104 @@
+package types
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	streamChunkSize       = 128 // 每个分块最多存储的消息数量
+	defaultAutoClaimCount = 100 // XAutoClaim 默认最多转移的消息数量
+)
+
+// NewStreams 创建Streams类型实例
+func NewStreams() *Streams {
+	return &Streams{
+		items:   make(map[string]*Stream),
+		waiters: make(map[string][]chan struct{}),
+	}
+}
+
+// Streams Streams类型数据结构
+// waiters 为阻塞读取时，等待各个key新消息的通知
+type Streams struct {
+	mu      sync.Mutex
+	items   map[string]*Stream
+	waiters map[string][]chan struct{}
+}
+
+// StreamEntry 流中的一条消息，消息已被删除时 Values 为nil
+type StreamEntry struct {
+	ID     string
+	Values map[string]any
+}
+
+// XStream 从一个流中读取到的消息
+type XStream struct {
+	Key     string
+	Entries []StreamEntry
+}
+
+// XAddArgs XAdd的参数
+// ID 为"*"或空时自动生成，"ms-*"时自动生成序号；Values 为消息内容，至少包含一个field
+// NoMkStream 为true时k不存在不会创建
+// MaxLen 大于0时，添加后只保留最新的MaxLen条消息；MinID 不为空时删除ID小于MinID的消息
+// Approx 为true时只删除完整的分块，保留的消息可能略多，但效率更高
+type XAddArgs struct {
+	ID         string
+	Values     map[string]any
+	NoMkStream bool
+	MaxLen     int
+	MinID      string
+	Approx     bool
+}
+
+// XReadArgs XRead的参数
+// Keys 为要读取的流，IDs 为对应的流中已读取的最后一条消息的ID，"$" 表示只读取新的消息
+// Count 为每个流最多返回的消息数量，为0时不限制
+// Block 为true时没有新消息则阻塞等待，Timeout 为等待时间，为0时一直等待
+type XReadArgs struct {
+	Keys    []string
+	IDs     []string
+	Count   int
+	Block   bool
+	Timeout time.Duration
+}
+
+// XReadGroupArgs XReadGroup的参数
+// IDs 为">"时读取从未分配给消费者组的新消息，为其他ID时读取该消费者待确认的消息
+// NoAck 为true时读取的消息不需要确认，不加入待确认列表
+type XReadGroupArgs struct {
+	Group    string
+	Consumer string
+	Keys     []string
+	IDs      []string
+	Count    int
+	NoAck    bool
+	Block    bool
+	Timeout  time.Duration
+}
+
+// XPendingSummary 消费者组中待确认消息的概况
+// Lower、Higher 为待确认消息的最小和最大ID，Consumers 为每个消费者待确认的消息数量
+type XPendingSummary struct {
+	Count     int
+	Lower     string
+	Higher    string
+	Consumers map[string]int
+}
+
+// XPendingArgs XPendingExt的参数
+// Start、End 为ID的范围，支持"-"和"+"；Count 为最多返回的数量，为0时不限制
+// Consumer 不为空时只返回该消费者的消息，Idle 为消息最少的空闲时间
+type XPendingArgs struct {
+	Group    string
+	Start    string
+	End      string
+	Count    int
+	Consumer string
+	Idle     time.Duration
+}
+
+// XPendingEntry 一条待确认的消息
+// Idle 为距离上次分配的时间，RetryCount 为分配的次数
+type XPendingEntry struct {
+	ID         string
+	Consumer   string
+	Idle       time.Duration
+	RetryCount int
+}
+
+// XClaimArgs XClaim的参数
+// 空闲时间不小于MinIdle的待确认消息，会被转移给Consumer
+type XClaimArgs struct {
+	Group    string
+	Consumer string
+	MinIdle  time.Duration
+	IDs      []string
+}
+
+// XAutoClaimArgs XAutoClaim的参数
+// Start 为开始扫描的ID，首次为"0-0"；Count 为最多转移的消息数量，为0时为100
+type XAutoClaimArgs struct {
+	Group    string
+	Consumer string
+	MinIdle  time.Duration
+	Start    string
+	Count    int
+}
+
+// Exist 判断k是否存在
+func (ss *Streams) Exist(k string) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.exist(k)
+}
+
+// exist 判断k是否存在
+func (ss *Streams) exist(k string) bool {
+	s, exist := ss.items[k]
+	if !exist {
+		return false
+	}
+	if s.isExpired() {
+		ss.del(k)
+		return false
+	}
+	return true
+}
+
+// XAdd 向流中添加一条消息，并按照args删除旧的消息
+// return id string 为消息的ID，exist bool 表示添加前k是否存在
+func (ss *Streams) XAdd(k string, args *XAddArgs) (id string, exist bool, err error) {
+	if len(args.Values) == 0 {
+		return "", false, ErrValues
+	}
+	minID := minStreamID
+	if args.MinID != "" {
+		if minID, err = parseStreamID(args.MinID, 0); err != nil {
+			return "", false, err
+		}
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	if exist = s != nil; !exist {
+		if args.NoMkStream {
+			return "", false, ErrStreamKey
+		}
+		s = newStream()
+	}
+	next, err := s.nextID(args.ID)
+	if err != nil {
+		return "", exist, err
+	}
+	values := make(map[string]any, len(args.Values))
+	for field, v := range args.Values {
+		values[field] = v
+	}
+	s.append(next, values)
+	maxLen := math.MaxInt
+	if args.MaxLen > 0 {
+		maxLen = args.MaxLen
+	}
+	s.trim(maxLen, minID, args.Approx)
+	ss.items[k] = s
+	ss.notify(k)
+	return next.String(), exist, nil
+}
+
+// XLen 获取流中消息的数量
+func (ss *Streams) XLen(k string) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	if s == nil {
+		return 0
+	}
+	return s.length
+}
+
+// XRange 按ID从小到大获取[start, end]范围内的消息
+// start、end 支持"-"、"+"，只有毫秒时间戳的ID，以及"("前缀表示不包含该ID
+// count 为最多返回的数量，为0时不限制
+func (ss *Streams) XRange(k, start, end string, count int) ([]StreamEntry, error) {
+	from, to, ok, err := parseStreamRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	if s == nil {
+		return nil, ErrStreamKey
+	}
+	entries := make([]StreamEntry, 0)
+	if ok {
+		s.rangeAsc(from, to, func(e *streamEntry) bool {
+			entries = append(entries, e.entry())
+			return count <= 0 || len(entries) < count
+		})
+	}
+	return entries, nil
+}
+
+// XRevRange 按ID从大到小获取[start, end]范围内的消息，参数与XRange相同，顺序为end、start
+func (ss *Streams) XRevRange(k, end, start string, count int) ([]StreamEntry, error) {
+	from, to, ok, err := parseStreamRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	if s == nil {
+		return nil, ErrStreamKey
+	}
+	entries := make([]StreamEntry, 0)
+	if ok {
+		s.rangeDesc(from, to, func(e *streamEntry) bool {
+			entries = append(entries, e.entry())
+			return count <= 0 || len(entries) < count
+		})
+	}
+	return entries, nil
+}
+
+// XRead 从多个流中读取ID大于指定ID的消息
+// 没有新消息且args.Block为true时阻塞等待，超时返回 ErrTimeout
+func (ss *Streams) XRead(ctx context.Context, args *XReadArgs) ([]XStream, error) {
+	if len(args.Keys) == 0 || len(args.Keys) != len(args.IDs) {
+		return nil, ErrStreamArgs
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ids := make([]streamID, len(args.IDs))
+	for i, id := range args.IDs {
+		if id == "$" {
+			if s := ss.get(args.Keys[i]); s != nil {
+				ids[i] = s.lastID
+			}
+			continue
+		}
+		var err error
+		if ids[i], err = parseStreamID(id, 0); err != nil {
+			return nil, err
+		}
+	}
+	timeoutC, stop := blockTimeout(args.Block, args.Timeout)
+	defer stop()
+	for {
+		var result []XStream
+		for i, k := range args.Keys {
+			s := ss.get(k)
+			if s == nil {
+				continue
+			}
+			if entries := s.read(ids[i], args.Count); len(entries) > 0 {
+				result = append(result, XStream{Key: k, Entries: entries})
+			}
+		}
+		if len(result) > 0 || !args.Block {
+			return result, nil
+		}
+		if err := ss.wait(ctx, args.Keys, timeoutC); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// XGroupCreate 为流创建消费者组，id 为组内已分配的最后一条消息的ID，"$" 表示只消费新的消息
+// mkStream 为true时k不存在则创建空的流
+// return exist bool 表示创建前k是否存在
+func (ss *Streams) XGroupCreate(k, group, id string, mkStream bool) (bool, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s := ss.get(k)
+	exist := s != nil
+	if !exist {
+		if !mkStream {
+			return false, ErrStreamKey
+		}
+		s = newStream()
+	}
+	if _, ok := s.groups[group]; ok {
+		return exist, ErrGroupExists
+	}
+	lastID := s.lastID
+	if id != "$" {
+		var err error
+		if lastID, err = parseStreamID(id, 0); err != nil {
+			return exist, err
+		}
+	}
+	s.groups[group] = newConsumerGroup(lastID)
+	ss.items[k] = s
+	return exist, nil
+}
+
+// XReadGroup 以消费者组中消费者的身份读取消息
+// 读取新消息时没有新消息且args.Block为true则阻塞等待，超时返回 ErrTimeout
+func (ss *Streams) XReadGroup(ctx context.Context, args *XReadGroupArgs) ([]XStream, error) {
+	if len(args.Keys) == 0 || len(args.Keys) != len(args.IDs) {
+		return nil, ErrStreamArgs
+	}
+	ids := make([]streamID, len(args.IDs))
+	for i, id := range args.IDs {
+		if id == ">" {
+			continue
+		}
+		var err error
+		if ids[i], err = parseStreamID(id, 0); err != nil {
+			return nil, err
+		}
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	timeoutC, stop := blockTimeout(args.Block, args.Timeout)
+	defer stop()
+	for {
+		var result []XStream
+		var history bool
+		for i, k := range args.Keys {
+			s, g, err := ss.group(k, args.Group)
+			if err != nil {
+				return nil, err
+			}
+			var entries []StreamEntry
+			if args.IDs[i] == ">" {
+				entries = g.deliver(s, args.Consumer, args.Count, args.NoAck)
+			} else {
+				history = true
+				entries = g.history(s, args.Consumer, ids[i], args.Count)
+			}
+			if len(entries) > 0 || args.IDs[i] != ">" {
+				result = append(result, XStream{Key: k, Entries: entries})
+			}
+		}
+		// 读取待确认的消息时不会阻塞
+		if len(result) > 0 || history || !args.Block {
+			return result, nil
+		}
+		if err := ss.wait(ctx, args.Keys, timeoutC); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// XAck 确认消费者组中的消息，将其从待确认列表中删除
+// return int 为确认成功的消息数量
+func (ss *Streams) XAck(k, group string, ids ...string) (int, error) {
+	parsed, err := parseStreamIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	_, g, err := ss.group(k, group)
+	if err != nil {
+		return 0, err
+	}
+	var acked int
+	for _, id := range parsed {
+		if g.ack(id) {
+			acked++
+		}
+	}
+	return acked, nil
+}
+
+// XPending 获取消费者组中待确认消息的概况
+func (ss *Streams) XPending(k, group string) (*XPendingSummary, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	_, g, err := ss.group(k, group)
+	if err != nil {
+		return nil, err
+	}
+	summary := &XPendingSummary{
+		Count:     len(g.pendingIDs),
+		Consumers: make(map[string]int),
+	}
+	if summary.Count > 0 {
+		summary.Lower = g.pendingIDs[0].String()
+		summary.Higher = g.pendingIDs[summary.Count-1].String()
+	}
+	for name, c := range g.consumers {
+		if c.pending > 0 {
+			summary.Consumers[name] = c.pending
+		}
+	}
+	return summary, nil
+}
+
+// XPendingExt 获取消费者组中待确认消息的详情
+func (ss *Streams) XPendingExt(k string, args *XPendingArgs) ([]XPendingEntry, error) {
+	start, end, ok, err := parseStreamRange(args.Start, args.End)
+	if err != nil {
+		return nil, err
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	_, g, err := ss.group(k, args.Group)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]XPendingEntry, 0)
+	if !ok {
+		return result, nil
+	}
+	now := time.Now().UnixNano()
+	for i := g.seekPending(start); i < len(g.pendingIDs); i++ {
+		id := g.pendingIDs[i]
+		if end.less(id) || (args.Count > 0 && len(result) >= args.Count) {
+			break
+		}
+		p := g.pending[id]
+		idle := time.Duration(now - p.deliveryTime)
+		if (args.Consumer != "" && p.consumer != args.Consumer) || idle < args.Idle {
+			continue
+		}
+		result = append(result, XPendingEntry{
+			ID:         id.String(),
+			Consumer:   p.consumer,
+			Idle:       idle,
+			RetryCount: p.deliveryCount,
+		})
+	}
+	return result, nil
+}
+
+// XClaim 将空闲时间不小于MinIdle的待确认消息转移给消费者，并返回这些消息
+// 已经从流中删除的消息会从待确认列表中删除，不会返回
+func (ss *Streams) XClaim(k string, args *XClaimArgs) ([]StreamEntry, error) {
+	ids, err := parseStreamIDs(args.IDs)
+	if err != nil {
+		return nil, err
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s, g, err := ss.group(k, args.Group)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixNano()
+	entries := make([]StreamEntry, 0, len(ids))
+	for _, id := range ids {
+		if e, ok := g.claim(s, id, args.Consumer, args.MinIdle, now); ok {
+			entries = append(entries, e)
+		}
+	}
+	g.consumer(args.Consumer, now)
+	return entries, nil
+}
+
+// XAutoClaim 从Start开始扫描待确认列表，将空闲时间不小于MinIdle的消息转移给消费者
+// return next string 为下一次扫描的起始ID，为"0-0"时表示扫描结束
+func (ss *Streams) XAutoClaim(k string, args *XAutoClaimArgs) (entries []StreamEntry, next string, err error) {
+	start := minStreamID
+	if args.Start != "" {
+		if start, err = parseStreamID(args.Start, 0); err != nil {
+			return nil, "", err
+		}
+	}
+	count := args.Count
+	if count <= 0 {
+		count = defaultAutoClaimCount
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s, g, err := ss.group(k, args.Group)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now().UnixNano()
+	entries = make([]StreamEntry, 0)
+	i := g.seekPending(start)
+	for i < len(g.pendingIDs) && len(entries) < count {
+		id := g.pendingIDs[i]
+		if e, ok := g.claim(s, id, args.Consumer, args.MinIdle, now); ok {
+			entries = append(entries, e)
+		}
+		// 已删除的消息会从待确认列表中移除，此时下标不变
+		if i < len(g.pendingIDs) && g.pendingIDs[i] == id {
+			i++
+		}
+	}
+	g.consumer(args.Consumer, now)
+	next = minStreamID.String()
+	if i < len(g.pendingIDs) {
+		next = g.pendingIDs[i].String()
+	}
+	return entries, next, nil
+}
+
+// get 获取k对应的未过期流，不存在时返回nil
+func (ss *Streams) get(k string) *Stream {
+	if !ss.exist(k) {
+		return nil
+	}
+	return ss.items[k]
+}
+
+// group 获取k对应的流和消费者组，不存在时返回 ErrNoGroup
+func (ss *Streams) group(k, group string) (*Stream, *consumerGroup, error) {
+	s := ss.get(k)
+	if s == nil {
+		return nil, nil, ErrNoGroup
+	}
+	g, exist := s.groups[group]
+	if !exist {
+		return nil, nil, ErrNoGroup
+	}
+	return s, g, nil
+}
+
+// notify 通知等待k的阻塞读取
+func (ss *Streams) notify(k string) {
+	for _, ch := range ss.waiters[k] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	delete(ss.waiters, k)
+}
+
+// wait 阻塞等待keys中任意一个流有新的消息
+// 调用时需持有锁，等待期间释放锁，返回时重新持有锁
+func (ss *Streams) wait(ctx context.Context, keys []string, timeoutC <-chan time.Time) error {
+	ch := make(chan struct{}, 1)
+	for _, k := range keys {
+		ss.waiters[k] = append(ss.waiters[k], ch)
+	}
+	ss.mu.Unlock()
+	var err error
+	select {
+	case <-ch:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeoutC:
+		err = ErrTimeout
+	}
+	ss.mu.Lock()
+	ss.removeWaiter(keys, ch)
+	return err
+}
+
+// removeWaiter 将ch从keys的等待列表中移除
+func (ss *Streams) removeWaiter(keys []string, ch chan struct{}) {
+	for _, k := range keys {
+		queue := ss.waiters[k]
+		for i := 0; i < len(queue); i++ {
+			if queue[i] == ch {
+				queue = append(queue[:i], queue[i+1:]...)
+				i--
+			}
+		}
+		if len(queue) == 0 {
+			delete(ss.waiters, k)
+		} else {
+			ss.waiters[k] = queue
+		}
+	}
+}
+
+// Del 删除一个key
+func (ss *Streams) Del(k string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.del(k)
+}
+
+func (ss *Streams) del(k string) {
+	delete(ss.items, k)
+}
+
+// Expiration 设置超时时间
+func (ss *Streams) Expiration(k string, d time.Duration) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if !ss.exist(k) {
+		return ErrKeyNotExist
+	}
+	ss.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (ss *Streams) ClearExpiration() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for key, item := range ss.items {
+		if item.isExpired() {
+			delete(ss.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (ss *Streams) RandomClearExpiration() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	var counter int
+	for key, item := range ss.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(ss.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (ss *Streams) Flush() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.items = make(map[string]*Stream)
+}
+
+// newStream 创建一个流的实例
+func newStream() *Stream {
+	return &Stream{
+		groups:     make(map[string]*consumerGroup),
+		expiration: DefaultExpiration,
+	}
+}
+
+// Stream 缓存流，只能在尾部追加消息
+// chunks 按ID从小到大分块存储消息，每块最多 streamChunkSize 条，删除旧消息时整块释放
+// lastID 为添加过的最大的ID，消息被删除后也不会变小
+type Stream struct {
+	chunks     []*streamChunk
+	length     int
+	lastID     streamID
+	groups     map[string]*consumerGroup
+	expiration int64
+}
+
+// streamChunk 存储连续消息的分块
+type streamChunk struct {
+	entries []streamEntry
+}
+
+// streamEntry 流中的一条消息
+type streamEntry struct {
+	id     streamID
+	values map[string]any
+}
+
+// entry 转换为返回给调用方的消息，Values 为副本
+func (e *streamEntry) entry() StreamEntry {
+	values := make(map[string]any, len(e.values))
+	for field, v := range e.values {
+		values[field] = v
+	}
+	return StreamEntry{ID: e.id.String(), Values: values}
+}
+
+// nextID 根据XAdd的ID参数生成新消息的ID，新ID需大于lastID
+func (s *Stream) nextID(id string) (streamID, error) {
+	last := s.lastID
+	if id == "" || id == "*" {
+		if ms := uint64(time.Now().UnixMilli()); ms > last.ms {
+			return streamID{ms: ms}, nil
+		}
+		next, ok := last.next()
+		if !ok {
+			return streamID{}, ErrStreamID
+		}
+		return next, nil
+	}
+	if msPart, ok := strings.CutSuffix(id, "-*"); ok {
+		ms, err := strconv.ParseUint(msPart, 10, 64)
+		if err != nil || ms < last.ms {
+			return streamID{}, ErrStreamID
+		}
+		if ms > last.ms {
+			return streamID{ms: ms}, nil
+		}
+		if last.seq == math.MaxUint64 {
+			return streamID{}, ErrStreamID
+		}
+		return streamID{ms: ms, seq: last.seq + 1}, nil
+	}
+	next, err := parseStreamID(id, 0)
+	if err != nil || !last.less(next) {
+		return streamID{}, ErrStreamID
+	}
+	return next, nil
+}
+
+// append 在尾部追加一条消息
+func (s *Stream) append(id streamID, values map[string]any) {
+	n := len(s.chunks)
+	if n == 0 || len(s.chunks[n-1].entries) >= streamChunkSize {
+		s.chunks = append(s.chunks, &streamChunk{entries: make([]streamEntry, 0, streamChunkSize)})
+		n++
+	}
+	c := s.chunks[n-1]
+	c.entries = append(c.entries, streamEntry{id: id, values: values})
+	s.length++
+	s.lastID = id
+}
+
+// trim 从头部删除消息，直到数量不超过maxLen，并且第一条消息的ID不小于minID
+// approx 为true时只删除完整的分块
+// return int 为删除的消息数量
+func (s *Stream) trim(maxLen int, minID streamID, approx bool) int {
+	var removed int
+	for len(s.chunks) > 0 {
+		c := s.chunks[0]
+		n := 0
+		for n < len(c.entries) && (s.length-n > maxLen || c.entries[n].id.less(minID)) {
+			n++
+		}
+		if n == 0 {
+			break
+		}
+		if n == len(c.entries) {
+			s.chunks[0] = nil
+			s.chunks = s.chunks[1:]
+		} else if approx {
+			break
+		} else {
+			for i := 0; i < n; i++ {
+				c.entries[i] = streamEntry{}
+			}
+			c.entries = c.entries[n:]
+		}
+		s.length -= n
+		removed += n
+	}
+	return removed
+}
+
+// seek 查找第一条ID不小于id的消息所在的分块和位置
+func (s *Stream) seek(id streamID) (ci, ei int) {
+	ci = sort.Search(len(s.chunks), func(i int) bool {
+		entries := s.chunks[i].entries
+		return !entries[len(entries)-1].id.less(id)
+	})
+	if ci == len(s.chunks) {
+		return ci, 0
+	}
+	entries := s.chunks[ci].entries
+	ei = sort.Search(len(entries), func(i int) bool {
+		return !entries[i].id.less(id)
+	})
+	return ci, ei
+}
+
+// lookup 获取ID为id的消息，不存在时返回nil
+func (s *Stream) lookup(id streamID) *streamEntry {
+	ci, ei := s.seek(id)
+	if ci == len(s.chunks) {
+		return nil
+	}
+	if e := &s.chunks[ci].entries[ei]; e.id == id {
+		return e
+	}
+	return nil
+}
+
+// rangeAsc 按ID从小到大遍历[start, end]范围内的消息，fn 返回false时停止
+func (s *Stream) rangeAsc(start, end streamID, fn func(e *streamEntry) bool) {
+	ci, ei := s.seek(start)
+	for ; ci < len(s.chunks); ci, ei = ci+1, 0 {
+		entries := s.chunks[ci].entries
+		for ; ei < len(entries); ei++ {
+			if end.less(entries[ei].id) || !fn(&entries[ei]) {
+				return
+			}
+		}
+	}
+}
+
+// rangeDesc 按ID从大到小遍历[start, end]范围内的消息，fn 返回false时停止
+func (s *Stream) rangeDesc(start, end streamID, fn func(e *streamEntry) bool) {
+	ci, ei := len(s.chunks), 0
+	if next, ok := end.next(); ok {
+		ci, ei = s.seek(next)
+	}
+	for {
+		if ei == 0 {
+			if ci == 0 {
+				return
+			}
+			ci--
+			ei = len(s.chunks[ci].entries)
+		}
+		ei--
+		e := &s.chunks[ci].entries[ei]
+		if e.id.less(start) || !fn(e) {
+			return
+		}
+	}
+}
+
+// read 读取ID大于id的最多count条消息
+func (s *Stream) read(id streamID, count int) []StreamEntry {
+	start, ok := id.next()
+	if !ok {
+		return nil
+	}
+	var entries []StreamEntry
+	s.rangeAsc(start, maxStreamID, func(e *streamEntry) bool {
+		entries = append(entries, e.entry())
+		return count <= 0 || len(entries) < count
+	})
+	return entries
+}
+
+// isExpired 判断一个元素是否过期
+func (s *Stream) isExpired() bool {
+	if s.expiration != DefaultExpiration && time.Now().UnixNano() > s.expiration {
+		return true
+	}
+	return false
+}
+
+// newConsumerGroup 创建一个消费者组
+func newConsumerGroup(lastID streamID) *consumerGroup {
+	return &consumerGroup{
+		lastID:    lastID,
+		pending:   make(map[streamID]*pendingEntry),
+		consumers: make(map[string]*streamConsumer),
+	}
+}
+
+// consumerGroup 消费者组
+// lastID 为已分配给组内消费者的最后一条消息的ID
+// pending 为已分配但未确认的消息，pendingIDs 为按ID排序的待确认消息
+type consumerGroup struct {
+	lastID     streamID
+	pending    map[streamID]*pendingEntry
+	pendingIDs []streamID
+	consumers  map[string]*streamConsumer
+}
+
+// pendingEntry 一条待确认的消息
+// deliveryTime 为最近一次分配的时间，deliveryCount 为分配的次数
+type pendingEntry struct {
+	consumer      string
+	deliveryTime  int64
+	deliveryCount int
+}
+
+// streamConsumer 消费者组中的消费者
+// seenTime 为最近一次读取或转移消息的时间，pending 为待确认的消息数量
+type streamConsumer struct {
+	seenTime int64
+	pending  int
+}
+
+// consumer 获取消费者，不存在时创建，并更新最近活动的时间
+func (g *consumerGroup) consumer(name string, now int64) *streamConsumer {
+	c, exist := g.consumers[name]
+	if !exist {
+		c = &streamConsumer{}
+		g.consumers[name] = c
+	}
+	c.seenTime = now
+	return c
+}
+
+// deliver 将ID大于lastID的最多count条新消息分配给消费者
+func (g *consumerGroup) deliver(s *Stream, name string, count int, noAck bool) []StreamEntry {
+	now := time.Now().UnixNano()
+	c := g.consumer(name, now)
+	entries := make([]StreamEntry, 0)
+	start, ok := g.lastID.next()
+	if !ok {
+		return entries
+	}
+	s.rangeAsc(start, maxStreamID, func(e *streamEntry) bool {
+		entries = append(entries, e.entry())
+		g.lastID = e.id
+		if !noAck {
+			// 新消息的ID总是大于已分配的消息，追加后仍然有序
+			g.pending[e.id] = &pendingEntry{consumer: name, deliveryTime: now, deliveryCount: 1}
+			g.pendingIDs = append(g.pendingIDs, e.id)
+			c.pending++
+		}
+		return count <= 0 || len(entries) < count
+	})
+	return entries
+}
+
+// history 获取消费者待确认的ID大于id的最多count条消息，已删除的消息 Values 为nil
+func (g *consumerGroup) history(s *Stream, name string, id streamID, count int) []StreamEntry {
+	g.consumer(name, time.Now().UnixNano())
+	entries := make([]StreamEntry, 0)
+	start, ok := id.next()
+	if !ok {
+		return entries
+	}
+	for i := g.seekPending(start); i < len(g.pendingIDs); i++ {
+		if count > 0 && len(entries) >= count {
+			break
+		}
+		pid := g.pendingIDs[i]
+		if g.pending[pid].consumer != name {
+			continue
+		}
+		if e := s.lookup(pid); e != nil {
+			entries = append(entries, e.entry())
+		} else {
+			entries = append(entries, StreamEntry{ID: pid.String()})
+		}
+	}
+	return entries
+}
+
+// ack 确认一条消息
+// return bool 表示消息是否在待确认列表中
+func (g *consumerGroup) ack(id streamID) bool {
+	p, exist := g.pending[id]
+	if !exist {
+		return false
+	}
+	delete(g.pending, id)
+	if i := g.seekPending(id); i < len(g.pendingIDs) && g.pendingIDs[i] == id {
+		g.pendingIDs = append(g.pendingIDs[:i], g.pendingIDs[i+1:]...)
+	}
+	if c, ok := g.consumers[p.consumer]; ok {
+		c.pending--
+	}
+	return true
+}
+
+// claim 空闲时间不小于minIdle时，将待确认的消息转移给消费者
+// 消息已从流中删除时，将其从待确认列表中删除
+func (g *consumerGroup) claim(s *Stream, id streamID, name string, minIdle time.Duration, now int64) (StreamEntry, bool) {
+	p, exist := g.pending[id]
+	if !exist || time.Duration(now-p.deliveryTime) < minIdle {
+		return StreamEntry{}, false
+	}
+	e := s.lookup(id)
+	if e == nil {
+		g.ack(id)
+		return StreamEntry{}, false
+	}
+	if p.consumer != name {
+		if c, ok := g.consumers[p.consumer]; ok {
+			c.pending--
+		}
+		g.consumer(name, now).pending++
+		p.consumer = name
+	}
+	p.deliveryTime = now
+	p.deliveryCount++
+	return e.entry(), true
+}
+
+// seekPending 查找第一条ID不小于id的待确认消息的位置
+func (g *consumerGroup) seekPending(id streamID) int {
+	return sort.Search(len(g.pendingIDs), func(i int) bool {
+		return !g.pendingIDs[i].less(id)
+	})
+}
+
+// streamID 消息ID，由毫秒时间戳和同一毫秒内的序号组成
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+var (
+	minStreamID = streamID{}
+	maxStreamID = streamID{ms: math.MaxUint64, seq: math.MaxUint64}
+)
+
+// String 按"ms-seq"的格式输出
+func (id streamID) String() string {
+	return strconv.FormatUint(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
+
+// less 判断id是否小于other
+func (id streamID) less(other streamID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+// next 获取大于id的最小ID，id为最大值时ok为false
+func (id streamID) next() (streamID, bool) {
+	switch {
+	case id.seq < math.MaxUint64:
+		return streamID{ms: id.ms, seq: id.seq + 1}, true
+	case id.ms < math.MaxUint64:
+		return streamID{ms: id.ms + 1}, true
+	}
+	return id, false
+}
+
+// prev 获取小于id的最大ID，id为最小值时ok为false
+func (id streamID) prev() (streamID, bool) {
+	switch {
+	case id.seq > 0:
+		return streamID{ms: id.ms, seq: id.seq - 1}, true
+	case id.ms > 0:
+		return streamID{ms: id.ms - 1, seq: math.MaxUint64}, true
+	}
+	return id, false
+}
+
+// parseStreamID 解析"ms-seq"格式的ID，只有毫秒时间戳时序号为seq
+// "-" 为最小的ID，"+" 为最大的ID
+func parseStreamID(s string, seq uint64) (streamID, error) {
+	switch s {
+	case "-":
+		return minStreamID, nil
+	case "+":
+		return maxStreamID, nil
+	}
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return streamID{}, ErrStreamID
+	}
+	if hasSeq {
+		if seq, err = strconv.ParseUint(seqPart, 10, 64); err != nil {
+			return streamID{}, ErrStreamID
+		}
+	}
+	return streamID{ms: ms, seq: seq}, nil
+}
+
+// parseStreamIDs 解析多个ID
+func parseStreamIDs(ids []string) ([]streamID, error) {
+	parsed := make([]streamID, len(ids))
+	for i, id := range ids {
+		var err error
+		if parsed[i], err = parseStreamID(id, 0); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// parseStreamRange 解析范围查询的起止ID，"(" 前缀表示不包含该ID
+// 只有毫秒时间戳时，start的序号为0，end的序号为最大值
+// ok 为false时范围为空
+func parseStreamRange(start, end string) (from, to streamID, ok bool, err error) {
+	fromOK, toOK := true, true
+	if s, exclusive := strings.CutPrefix(start, "("); exclusive {
+		if s == "-" || s == "+" {
+			return from, to, false, ErrStreamID
+		}
+		if from, err = parseStreamID(s, 0); err != nil {
+			return from, to, false, err
+		}
+		from, fromOK = from.next()
+	} else if from, err = parseStreamID(start, 0); err != nil {
+		return from, to, false, err
+	}
+	if e, exclusive := strings.CutPrefix(end, "("); exclusive {
+		if e == "-" || e == "+" {
+			return from, to, false, ErrStreamID
+		}
+		if to, err = parseStreamID(e, math.MaxUint64); err != nil {
+			return from, to, false, err
+		}
+		to, toOK = to.prev()
+	} else if to, err = parseStreamID(end, math.MaxUint64); err != nil {
+		return from, to, false, err
+	}
+	return from, to, fromOK && toOK && !to.less(from), nil
+}
+
+// blockTimeout 获取阻塞读取的超时通知，timeout 为0时一直等待
+// stop 用于释放定时器
+func blockTimeout(block bool, timeout time.Duration) (<-chan time.Time, func()) {
+	if !block || timeout <= 0 {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(timeout)
+	return timer.C, func() { timer.Stop() }
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+	"math"
 	"sync"
 	"time"
 )
@@ -8,14 +10,17 @@ import (
 // NewStrings 创建字符串类型实例
 func NewStrings() *Strings {
 	return &Strings{
-		items: make(map[string]*Item),
+		items:   make(map[string]*Item),
+		waiters: make(map[string][]chan struct{}),
 	}
 }
 
 // Strings string类型数据结构
+// waiters 为每个key上等待k被删除的调用方
 type Strings struct {
-	mu    sync.Mutex
-	items map[string]*Item
+	mu      sync.Mutex
+	items   map[string]*Item
+	waiters map[string][]chan struct{}
 }
 
 // Exist 判断k是否存在
@@ -38,104 +43,284 @@ func (s *Strings) exist(k string) bool {
 	return true
 }
 
-// Set 设置一个字符串类型
+// Set 设置一个字符串类型，并清除原有的过期时间
+// return exist bool 表示写入前k是否存在
 func (s *Strings) Set(k string, v any) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	i, exist := s.items[k]
-	if !exist {
-		i = newItem()
-	}
-	i.Set(v)
-	s.items[k] = i
-	return exist
+	return s.set(k, v)
 }
 
 // SetEx 缓存k的值为v,并且设置超时时间d
 func (s *Strings) SetEx(k string, v any, d time.Duration) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	i, exist := s.items[k]
-	if !exist {
-		i = newItem()
-	}
-	i.SetEx(v, d)
-	s.items[k] = i
+	exist := s.set(k, v)
+	s.items[k].expiration = time.Now().Add(d).UnixNano()
 	return exist
 }
 
+// SetNX k不存在时，缓存k的值为v
+// return bool 表示是否写入
+func (s *Strings) SetNX(k string, v any) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.exist(k) {
+		return false
+	}
+	s.set(k, v)
+	return true
+}
+
+// SetArgs 按照args中的条件和过期时间缓存k的值为v
+// return old any 为args.Get时写入前的值，set bool 表示是否写入，exist bool 表示写入前k是否存在
+func (s *Strings) SetArgs(k string, v any, args SetArgs) (old any, set, exist bool, err error) {
+	expiration, err := args.expiration()
+	if err != nil {
+		return nil, false, false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	if exist = i != nil; exist && args.Get {
+		old = i.Get()
+	}
+	if (args.Mode == SetModeNX && exist) || (args.Mode == SetModeXX && !exist) {
+		return old, false, exist, nil
+	}
+	if args.KeepTTL && exist {
+		expiration = i.expiration
+	}
+	s.set(k, v)
+	s.items[k].expiration = expiration
+	return old, true, exist, nil
+}
+
 // Get 获取一个string类型值
 func (s *Strings) Get(k string) (any, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	i, exist := s.items[k]
-	if !exist {
+	i := s.get(k)
+	if i == nil {
 		return nil, ErrKeyNotExist
-	} else if i.isExpired() {
-		s.del(k)
+	}
+	return i.Get(), nil
+}
+
+// GetSet 缓存k的值为v，并返回原有的值，k不存在时返回nil
+// return exist bool 表示写入前k是否存在
+func (s *Strings) GetSet(k string, v any) (old any, exist bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.get(k); i != nil {
+		old = i.Get()
+	}
+	exist = s.set(k, v)
+	return old, exist
+}
+
+// GetDel 获取k的值并删除k
+func (s *Strings) GetDel(k string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	if i == nil {
 		return nil, ErrKeyNotExist
 	}
+	s.del(k)
 	return i.Get(), nil
 }
 
-// Incr 对k计数+1
-func (s *Strings) Incr(k string) {
+// GetEx 获取k的值，并按照args修改过期时间，args为空时不修改
+func (s *Strings) GetEx(k string, args GetExArgs) (any, error) {
+	expiration, err := args.expiration()
+	if err != nil {
+		return nil, err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	i, exist := s.items[k]
-	if !exist {
-		i = newItem()
-	} else if i.isExpired() {
-		s.del(k)
-		return
+	i := s.get(k)
+	if i == nil {
+		return nil, ErrKeyNotExist
 	}
-	i.Incr()
-	s.items[k] = i
+	if args.Persist || expiration != DefaultExpiration {
+		i.expiration = expiration
+	}
+	return i.Get(), nil
 }
 
-// Decr 对k计数-1
-func (s *Strings) Decr(k string) {
+// MSet 在一次操作中缓存多个k的值，并清除原有的过期时间
+// return []string 为写入前不存在的k
+func (s *Strings) MSet(values map[string]any) []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	i, exist := s.items[k]
-	if !exist {
-		i = newItem()
-	} else if i.isExpired() {
-		s.del(k)
-		return
+	var created []string
+	for k, v := range values {
+		if !s.set(k, v) {
+			created = append(created, k)
+		}
 	}
-	i.Decr()
-	s.items[k] = i
+	return created
 }
 
-// IncrBy 对k计数+v
-func (s *Strings) IncrBy(k string, v int64) {
+// MSetNX 所有k都不存在时，在一次操作中缓存多个k的值
+// return bool 表示是否写入
+func (s *Strings) MSetNX(values map[string]any) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	i, exist := s.items[k]
+	for k := range values {
+		if s.exist(k) {
+			return false
+		}
+	}
+	for k, v := range values {
+		s.set(k, v)
+	}
+	return true
+}
+
+// MGet 在一次操作中获取多个k的值，不存在的k对应的值为nil
+func (s *Strings) MGet(keys ...string) []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vals := make([]any, len(keys))
+	for idx, k := range keys {
+		if i := s.get(k); i != nil {
+			vals[idx] = i.Get()
+		}
+	}
+	return vals
+}
+
+// Append 在k的值末尾追加v，k不存在时缓存k的值为v
+// v 和原有的值需为string或[]byte
+// return n int 为追加后的长度，exist bool 表示追加前k是否存在
+func (s *Strings) Append(k string, v any) (n int, exist bool, err error) {
+	b, err := toBytes(v)
+	if err != nil {
+		return 0, false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	if exist = i != nil; !exist {
+		s.set(k, cloneBytes(v))
+		return len(b), false, nil
+	}
+	n, err = i.Append(b)
+	return n, true, err
+}
+
+// StrLen 获取k的值的长度，k不存在时返回0
+func (s *Strings) StrLen(k string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	if i == nil {
+		return 0, nil
+	}
+	return i.StrLen()
+}
+
+// GetRange 获取k的值中[start, end]区间的内容，支持负数下标，k不存在时返回空字符串
+func (s *Strings) GetRange(k string, start, end int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	if i == nil {
+		return "", nil
+	}
+	return i.GetRange(start, end)
+}
+
+// SetRange 从offset开始使用v覆盖k的值，长度不足时使用0字节填充
+// k不存在且v为空时不创建k
+// return n int 为修改后的长度，exist bool 表示修改前k是否存在
+func (s *Strings) SetRange(k string, offset int, v any) (n int, exist bool, err error) {
+	b, err := toBytes(v)
+	if err != nil {
+		return 0, false, err
+	}
+	if offset < 0 || offset+len(b) > maxStringLen {
+		return 0, false, ErrOffset
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	if exist = i != nil; !exist {
+		if len(b) == 0 {
+			return 0, false, nil
+		}
+		_, isString := v.(string)
+		buf := make([]byte, offset+len(b))
+		copy(buf[offset:], b)
+		if isString {
+			s.set(k, string(buf))
+		} else {
+			s.set(k, buf)
+		}
+		return len(buf), false, nil
+	}
+	n, err = i.SetRange(offset, b)
+	return n, true, err
+}
+
+// Incr 对k计数+1
+// return exist bool 表示操作前k是否存在
+func (s *Strings) Incr(k string) (int64, bool, error) {
+	return s.IncrBy(k, 1)
+}
+
+// Decr 对k计数-1
+// return exist bool 表示操作前k是否存在
+func (s *Strings) Decr(k string) (int64, bool, error) {
+	return s.IncrBy(k, -1)
+}
+
+// IncrBy 对k计数+v，k不存在或已过期时从0开始，结果按int64存储
+// 原有的值需为整数或可以解析为整数的字符串
+// return exist bool 表示操作前k是否存在
+func (s *Strings) IncrBy(k string, v int64) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	exist := i != nil
 	if !exist {
 		i = newItem()
-	} else if i.isExpired() {
-		s.del(k)
-		return
 	}
-	i.IncrBy(v)
+	n, err := i.IncrBy(v)
+	if err != nil {
+		return 0, exist, err
+	}
 	s.items[k] = i
+	return n, exist, nil
 }
 
 // DecrBy 对k计数-v
-func (s *Strings) DecrBy(k string, v int64) {
+// return exist bool 表示操作前k是否存在
+func (s *Strings) DecrBy(k string, v int64) (int64, bool, error) {
+	if v == math.MinInt64 {
+		return 0, s.Exist(k), ErrOverflow
+	}
+	return s.IncrBy(k, -v)
+}
+
+// IncrByFloat 对k的浮点数计数+v，k不存在或已过期时从0开始，结果按float64存储
+// return exist bool 表示操作前k是否存在
+func (s *Strings) IncrByFloat(k string, v float64) (float64, bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	i, exist := s.items[k]
+	i := s.get(k)
+	exist := i != nil
 	if !exist {
 		i = newItem()
-	} else if i.isExpired() {
-		s.del(k)
-		return
 	}
-	i.DecrBy(v)
+	f, err := i.IncrByFloat(v)
+	if err != nil {
+		return 0, exist, err
+	}
 	s.items[k] = i
+	return f, exist, nil
 }
 
 // Del 删除一个key
@@ -145,8 +330,131 @@ func (s *Strings) Del(k string) {
 	s.del(k)
 }
 
+// get 获取k对应的未过期元素，不存在时返回nil
+func (s *Strings) get(k string) *Item {
+	if !s.exist(k) {
+		return nil
+	}
+	return s.items[k]
+}
+
+// set 缓存k的值为v，并清除原有的过期时间
+// return exist bool 表示写入前k是否存在
+func (s *Strings) set(k string, v any) bool {
+	i := s.get(k)
+	exist := i != nil
+	if !exist {
+		i = newItem()
+		s.items[k] = i
+	}
+	i.Set(v)
+	return exist
+}
+
 func (s *Strings) del(k string) {
 	delete(s.items, k)
+	s.notify(k)
+}
+
+// SetNXWait k不存在时缓存k的值为v并设置超时时间ttl，k已存在时阻塞等待k被删除或过期后重试
+// timeout 为0时一直等待，超时返回 ErrTimeout，ctx 取消时返回 ctx.Err()
+func (s *Strings) SetNXWait(ctx context.Context, k string, v any, ttl, timeout time.Duration) error {
+	if ttl <= 0 || timeout < 0 {
+		return ErrExpireArgs
+	}
+	timeoutC, stop := blockTimeout(true, timeout)
+	defer stop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		i := s.get(k)
+		if i == nil {
+			s.set(k, v)
+			s.items[k].expiration = time.Now().Add(ttl).UnixNano()
+			return nil
+		}
+		if err := s.wait(ctx, k, timeoutC, i.expiration); err != nil {
+			return err
+		}
+	}
+}
+
+// CompareAndDelete k的值为字符串v时删除k
+// return bool 表示是否删除
+func (s *Strings) CompareAndDelete(k, v string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.get(k); i == nil || i.object != any(v) {
+		return false
+	}
+	s.del(k)
+	return true
+}
+
+// CompareAndExpire k的值为字符串v时设置超时时间d
+// return bool 表示是否设置
+func (s *Strings) CompareAndExpire(k, v string, d time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.get(k)
+	if i == nil || i.object != any(v) {
+		return false
+	}
+	i.expiration = time.Now().Add(d).UnixNano()
+	return true
+}
+
+// notify 唤醒等待k被删除的调用方
+func (s *Strings) notify(k string) {
+	for _, ch := range s.waiters[k] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	delete(s.waiters, k)
+}
+
+// wait 阻塞等待k被删除或到达过期时间expiration
+// 调用时需持有锁，等待期间释放锁，返回时重新持有锁
+func (s *Strings) wait(ctx context.Context, k string, timeoutC <-chan time.Time, expiration int64) error {
+	ch := make(chan struct{}, 1)
+	s.waiters[k] = append(s.waiters[k], ch)
+	var expireC <-chan time.Time
+	if expiration != DefaultExpiration {
+		timer := time.NewTimer(time.Until(time.Unix(0, expiration)))
+		defer timer.Stop()
+		expireC = timer.C
+	}
+	s.mu.Unlock()
+	var err error
+	select {
+	case <-ch:
+	case <-expireC:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeoutC:
+		err = ErrTimeout
+	}
+	s.mu.Lock()
+	s.removeWaiter(k, ch)
+	return err
+}
+
+// removeWaiter 将ch从k的等待列表中移除
+func (s *Strings) removeWaiter(k string, ch chan struct{}) {
+	queue := s.waiters[k]
+	for i := 0; i < len(queue); i++ {
+		if queue[i] == ch {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(s.waiters, k)
+	} else {
+		s.waiters[k] = queue
+	}
 }
 
 // Expiration 设置超时时间
@@ -166,7 +474,7 @@ func (s *Strings) ClearExpiration() {
 	defer s.mu.Unlock()
 	for key, item := range s.items {
 		if item.isExpired() {
-			delete(s.items, key)
+			s.del(key)
 		}
 	}
 }
@@ -181,7 +489,7 @@ func (s *Strings) RandomClearExpiration() {
 			return
 		}
 		if item.isExpired() {
-			delete(s.items, key)
+			s.del(key)
 		}
 		counter++
 	}
@@ -192,6 +500,9 @@ func (s *Strings) Flush() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.items = make(map[string]*Item)
+	for k := range s.waiters {
+		s.notify(k)
+	}
 }
 
 // newItem 创建一个字符串存储单元的实例
@@ -204,58 +515,134 @@ func newItem() *Item {
 // Item 每一条string类型数据
 // object 数据的具体内容
 // expiration 过期时间
+// shared 表示[]byte类型的object可能被外部持有，位图操作修改前需要先复制
 type Item struct {
 	object     any
 	expiration int64
+	shared     bool
 }
 
 func (i *Item) Set(v any) {
 	i.object = v
+	i.expiration = DefaultExpiration
+	i.shared = true
 }
 
 func (i *Item) SetEx(v any, d time.Duration) {
-	i.object = v
+	i.Set(v)
 	i.expiration = time.Now().Add(d).UnixNano()
 }
 
 func (i *Item) Get() any {
+	if _, ok := i.object.([]byte); ok {
+		i.shared = true
+	}
 	return i.object
 }
 
-func (i *Item) Incr() {
-	if i.object == nil {
-		i.object = int64(0)
+// IncrBy 对值计数+v，结果按int64存储
+func (i *Item) IncrBy(v int64) (int64, error) {
+	n, err := toInt64(i.object)
+	if err != nil {
+		return 0, err
+	}
+	if n, err = addInt64(n, v); err != nil {
+		return 0, err
+	}
+	i.object = n
+	return n, nil
+}
+
+// IncrByFloat 对值的浮点数计数+v，结果按float64存储
+func (i *Item) IncrByFloat(v float64) (float64, error) {
+	f, err := toFloat64(i.object)
+	if err != nil {
+		return 0, err
+	}
+	if f, err = addFloat64(f, v); err != nil {
+		return 0, err
+	}
+	i.object = f
+	return f, nil
+}
+
+// Append 在值的末尾追加b
+// 原值为string时结果为string，为[]byte时结果为新的[]byte，不修改原有的切片
+func (i *Item) Append(b []byte) (int, error) {
+	switch cur := i.object.(type) {
+	case string:
+		i.object = cur + string(b)
+		return len(cur) + len(b), nil
+	case []byte:
+		buf := make([]byte, len(cur)+len(b))
+		copy(buf, cur)
+		copy(buf[len(cur):], b)
+		i.object = buf
+		return len(buf), nil
 	}
-	num := i.object.(int64)
-	num++
-	i.object = num
+	return 0, ErrNotString
 }
 
-func (i *Item) IncrBy(v int64) {
-	if i.object == nil {
-		i.object = int64(0)
+// StrLen 获取值的长度
+func (i *Item) StrLen() (int, error) {
+	switch cur := i.object.(type) {
+	case string:
+		return len(cur), nil
+	case []byte:
+		return len(cur), nil
 	}
-	num := i.object.(int64)
-	num += v
-	i.object = num
+	return 0, ErrNotString
 }
 
-func (i *Item) Decr() {
-	if i.object == nil {
-		i.object = int64(0)
+// GetRange 获取值中[start, end]区间的内容
+func (i *Item) GetRange(start, end int) (string, error) {
+	var cur string
+	switch o := i.object.(type) {
+	case string:
+		cur = o
+	case []byte:
+		cur = string(o)
+	default:
+		return "", ErrNotString
 	}
-	num := i.object.(int64)
-	num--
-	i.object = num
+	start, end, ok := rangeIndex(start, end, len(cur))
+	if !ok {
+		return "", nil
+	}
+	return cur[start : end+1], nil
 }
 
-func (i *Item) DecrBy(v int64) {
-	if i.object == nil {
-		i.object = int64(0)
+// SetRange 从offset开始使用b覆盖值，长度不足时使用0字节填充
+// 原值为string时结果为string，为[]byte时结果为新的[]byte，不修改原有的切片
+func (i *Item) SetRange(offset int, b []byte) (int, error) {
+	var cur []byte
+	switch o := i.object.(type) {
+	case string:
+		if len(b) == 0 {
+			return len(o), nil
+		}
+		cur = []byte(o)
+	case []byte:
+		if len(b) == 0 {
+			return len(o), nil
+		}
+		cur = o
+	default:
+		return 0, ErrNotString
+	}
+	n := offset + len(b)
+	if n < len(cur) {
+		n = len(cur)
 	}
-	num := i.object.(int64)
-	num -= v
-	i.object = num
+	buf := make([]byte, n)
+	copy(buf, cur)
+	copy(buf[offset:], b)
+	if _, isString := i.object.(string); isString {
+		i.object = string(buf)
+	} else {
+		i.object = buf
+	}
+	return n, nil
 }
 
 // isExpired 判断一个元素是否过期
@@ -265,3 +652,74 @@ func (i *Item) isExpired() bool {
 	}
 	return false
 }
+
+// SetArgs Set的可选参数
+// Mode 为写入条件，为空时总是写入
+// TTL 为相对的过期时间，对应EX/PX；ExpireAt 为绝对的过期时间，对应EXAT
+// KeepTTL 保留k原有的过期时间，Get 返回写入前的值
+type SetArgs struct {
+	Mode     SetMode
+	TTL      time.Duration
+	ExpireAt time.Time
+	KeepTTL  bool
+	Get      bool
+}
+
+// expiration 校验参数并计算过期时间
+func (a SetArgs) expiration() (int64, error) {
+	if a.Mode != "" && a.Mode != SetModeNX && a.Mode != SetModeXX {
+		return 0, ErrSetMode
+	}
+	if a.KeepTTL && (a.TTL != 0 || !a.ExpireAt.IsZero()) {
+		return 0, ErrExpireArgs
+	}
+	return expirationOf(a.TTL, a.ExpireAt)
+}
+
+// GetExArgs GetEx的可选参数
+// TTL 为相对的过期时间，ExpireAt 为绝对的过期时间，Persist 移除过期时间
+type GetExArgs struct {
+	TTL      time.Duration
+	ExpireAt time.Time
+	Persist  bool
+}
+
+// expiration 校验参数并计算过期时间
+func (a GetExArgs) expiration() (int64, error) {
+	if a.Persist && (a.TTL != 0 || !a.ExpireAt.IsZero()) {
+		return 0, ErrExpireArgs
+	}
+	return expirationOf(a.TTL, a.ExpireAt)
+}
+
+// expirationOf 根据相对或绝对的过期时间计算UnixNano，都未设置时返回 DefaultExpiration
+func expirationOf(ttl time.Duration, at time.Time) (int64, error) {
+	switch {
+	case ttl < 0, ttl > 0 && !at.IsZero():
+		return 0, ErrExpireArgs
+	case ttl > 0:
+		return time.Now().Add(ttl).UnixNano(), nil
+	case !at.IsZero():
+		return at.UnixNano(), nil
+	}
+	return DefaultExpiration, nil
+}
+
+// toBytes 获取string或[]byte的内容，其他类型返回 ErrNotString
+func toBytes(v any) ([]byte, error) {
+	switch b := v.(type) {
+	case string:
+		return []byte(b), nil
+	case []byte:
+		return b, nil
+	}
+	return nil, ErrNotString
+}
+
+// cloneBytes 复制[]byte，避免与调用方共享底层数组，其他类型原样返回
+func cloneBytes(v any) any {
+	if b, ok := v.([]byte); ok {
+		return append([]byte{}, b...)
+	}
+	return v
+}
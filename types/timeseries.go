@@ -0,0 +1,694 @@
+package types
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewTimeSeriesSet 创建时间序列类型实例
+func NewTimeSeriesSet() *TimeSeriesSet {
+	return &TimeSeriesSet{
+		items: make(map[string]*TimeSeries),
+	}
+}
+
+// TimeSeriesSet 时间序列类型数据结构
+type TimeSeriesSet struct {
+	mu    sync.Mutex
+	items map[string]*TimeSeries
+}
+
+// TSCreateArgs 创建时间序列的参数
+// Retention 为保留时长，早于最新样本时间戳减去Retention的样本会被清理，为0时不清理
+// DuplicatePolicy 为时间戳已存在时的处理方式，为空时为BLOCK；Labels 为用于TSMRange过滤的标签
+type TSCreateArgs struct {
+	Retention       time.Duration
+	DuplicatePolicy TSDuplicatePolicy
+	Labels          map[string]string
+}
+
+// TSAddArgs 添加样本的参数，k不存在时按TSCreateArgs创建
+// OnDuplicate 不为空时覆盖本次添加的DuplicatePolicy
+type TSAddArgs struct {
+	TSCreateArgs
+	OnDuplicate TSDuplicatePolicy
+}
+
+// TSRangeArgs 查询时间序列的参数
+// Aggregation 不为空时按Bucket宽度的时间桶聚合，样本的时间戳为时间桶的起始时间；Count 大于0时限制返回的样本数量
+type TSRangeArgs struct {
+	Aggregation TSAggregation
+	Bucket      time.Duration
+	Count       int
+}
+
+// TSSample 时间序列的样本，Timestamp 为毫秒时间戳
+type TSSample struct {
+	Timestamp int64
+	Value     float64
+}
+
+// TSKeySample TSMAdd添加的样本
+type TSKeySample struct {
+	Key       string
+	Timestamp int64
+	Value     float64
+}
+
+// TSSeries TSMRange返回的时间序列
+type TSSeries struct {
+	Key     string
+	Labels  map[string]string
+	Samples []TSSample
+}
+
+// Exist 判断k是否存在
+func (ts *TimeSeriesSet) Exist(k string) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.exist(k)
+}
+
+// exist 判断k是否存在
+func (ts *TimeSeriesSet) exist(k string) bool {
+	s, exist := ts.items[k]
+	if !exist {
+		return false
+	}
+	if s.isExpired() {
+		ts.del(k)
+		return false
+	}
+	return true
+}
+
+// TSCreate 按照args创建一个空的时间序列，k已存在时返回 ErrTSExists
+func (ts *TimeSeriesSet) TSCreate(k string, args TSCreateArgs) error {
+	s, err := newTimeSeries(args)
+	if err != nil {
+		return err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.exist(k) {
+		return ErrTSExists
+	}
+	ts.items[k] = s
+	return nil
+}
+
+// TSAdd 添加一个样本，timestamp 为毫秒时间戳，为0时使用当前时间，k不存在时按args创建
+// return int64 为样本的时间戳，exist bool 表示添加前k是否存在
+func (ts *TimeSeriesSet) TSAdd(k string, timestamp int64, value float64, args *TSAddArgs) (int64, bool, error) {
+	if args == nil {
+		args = &TSAddArgs{}
+	}
+	if !validDuplicatePolicy(args.OnDuplicate) {
+		return 0, false, ErrTSArgs
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	s := ts.get(k)
+	exist := s != nil
+	if !exist {
+		var err error
+		if s, err = newTimeSeries(args.TSCreateArgs); err != nil {
+			return 0, false, err
+		}
+	}
+	timestamp, err := ts.add(s, timestamp, value, args.OnDuplicate)
+	if err != nil {
+		return 0, exist, err
+	}
+	if !exist {
+		ts.items[k] = s
+	}
+	return timestamp, exist, nil
+}
+
+// TSMAdd 向多个已存在的时间序列添加样本
+// return []int64 为样本的时间戳，[]error 为每个样本添加的错误，k不存在时为 ErrTSKey
+func (ts *TimeSeriesSet) TSMAdd(samples ...TSKeySample) ([]int64, []error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	timestamps := make([]int64, len(samples))
+	errs := make([]error, len(samples))
+	for i, sample := range samples {
+		s := ts.get(sample.Key)
+		if s == nil {
+			errs[i] = ErrTSKey
+			continue
+		}
+		timestamps[i], errs[i] = ts.add(s, sample.Timestamp, sample.Value, "")
+	}
+	return timestamps, errs
+}
+
+// TSGet 获取最新的样本，时间序列为空时返回nil
+func (ts *TimeSeriesSet) TSGet(k string) (*TSSample, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	s := ts.get(k)
+	if s == nil {
+		return nil, ErrTSKey
+	}
+	if len(s.samples) == 0 {
+		return nil, nil
+	}
+	sample := s.samples[len(s.samples)-1]
+	return &sample, nil
+}
+
+// TSRange 获取时间戳在[from, to]之间的样本，按时间戳从小到大排列
+func (ts *TimeSeriesSet) TSRange(k string, from, to int64, args *TSRangeArgs) ([]TSSample, error) {
+	return ts.tsRange(k, from, to, args, false)
+}
+
+// TSRevRange 获取时间戳在[from, to]之间的样本，按时间戳从大到小排列
+func (ts *TimeSeriesSet) TSRevRange(k string, from, to int64, args *TSRangeArgs) ([]TSSample, error) {
+	return ts.tsRange(k, from, to, args, true)
+}
+
+func (ts *TimeSeriesSet) tsRange(k string, from, to int64, args *TSRangeArgs, rev bool) ([]TSSample, error) {
+	if args == nil {
+		args = &TSRangeArgs{}
+	}
+	if !validRangeArgs(args) {
+		return nil, ErrTSArgs
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	s := ts.get(k)
+	if s == nil {
+		return nil, ErrTSKey
+	}
+	return s.rangeSamples(from, to, args, rev), nil
+}
+
+// TSMRange 获取标签满足所有filters的时间序列中时间戳在[from, to]之间的样本，按key排列
+// filter 与RedisTimeSeries一致：label=value、label!=value、label=(v1,v2)、label!=(v1,v2)、label=（不存在）、label!=（存在）
+// filters 中至少需要一个label=value或label=(v1,v2)
+func (ts *TimeSeriesSet) TSMRange(from, to int64, filters []string, args *TSRangeArgs) ([]TSSeries, error) {
+	return ts.tsMRange(from, to, filters, args, false)
+}
+
+// TSMRevRange 与TSMRange相同，每个时间序列的样本按时间戳从大到小排列
+func (ts *TimeSeriesSet) TSMRevRange(from, to int64, filters []string, args *TSRangeArgs) ([]TSSeries, error) {
+	return ts.tsMRange(from, to, filters, args, true)
+}
+
+func (ts *TimeSeriesSet) tsMRange(from, to int64, filters []string, args *TSRangeArgs, rev bool) ([]TSSeries, error) {
+	if args == nil {
+		args = &TSRangeArgs{}
+	}
+	if !validRangeArgs(args) {
+		return nil, ErrTSArgs
+	}
+	matchers, err := parseTSFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	var series []TSSeries
+	for k, s := range ts.items {
+		if s.isExpired() || !s.match(matchers) {
+			continue
+		}
+		labels := make(map[string]string, len(s.labels))
+		for name, value := range s.labels {
+			labels[name] = value
+		}
+		series = append(series, TSSeries{Key: k, Labels: labels, Samples: s.rangeSamples(from, to, args, rev)})
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Key < series[j].Key
+	})
+	return series, nil
+}
+
+// TSCreateRule 创建压缩规则，src中每个Bucket宽度的时间桶结束后，按aggregation聚合写入dst
+// src和dst需要已经创建，dst不能是其他规则的dst，也不能有自己的规则
+func (ts *TimeSeriesSet) TSCreateRule(src, dst string, aggregation TSAggregation, bucket time.Duration) error {
+	if !validRangeArgs(&TSRangeArgs{Aggregation: aggregation, Bucket: bucket}) || aggregation == "" {
+		return ErrTSArgs
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	s, d := ts.get(src), ts.get(dst)
+	if s == nil || d == nil {
+		return ErrTSKey
+	}
+	if src == dst || s.source != "" || d.source != "" || len(d.rules) > 0 {
+		return ErrTSRule
+	}
+	s.rules = append(s.rules, &tsRule{dst: dst, aggregation: aggregation, bucket: bucket.Milliseconds()})
+	d.source = src
+	return nil
+}
+
+// TSDeleteRule 删除src到dst的压缩规则
+func (ts *TimeSeriesSet) TSDeleteRule(src, dst string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	s := ts.get(src)
+	if s == nil {
+		return ErrTSKey
+	}
+	for i, r := range s.rules {
+		if r.dst == dst {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			if d := ts.items[dst]; d != nil {
+				d.source = ""
+			}
+			return nil
+		}
+	}
+	return ErrTSRule
+}
+
+// add 向s添加一个样本，并按规则压缩结束的时间桶
+func (ts *TimeSeriesSet) add(s *TimeSeries, timestamp int64, value float64, policy TSDuplicatePolicy) (int64, error) {
+	if timestamp == 0 {
+		timestamp = time.Now().UnixMilli()
+	}
+	if timestamp < 0 {
+		return 0, ErrTSArgs
+	}
+	if policy == "" {
+		policy = s.policy
+	}
+	if err := s.upsert(timestamp, value, policy); err != nil {
+		return 0, err
+	}
+	// 过期的dst会在get时删除，同时修改s.rules
+	for _, r := range append([]*tsRule{}, s.rules...) {
+		d := ts.get(r.dst)
+		if d == nil {
+			continue
+		}
+		start := tsBucketStart(timestamp, r.bucket)
+		switch {
+		case !r.started:
+			r.started, r.current = true, start
+		case start > r.current:
+			// 当前时间桶结束，写入聚合结果
+			ts.compact(s, d, r, r.current)
+			r.current = start
+		case start < r.current:
+			// 已结束的时间桶中有新样本，重新聚合
+			ts.compact(s, d, r, start)
+		}
+	}
+	return timestamp, nil
+}
+
+// compact 将s中从start开始的时间桶聚合后写入d
+func (ts *TimeSeriesSet) compact(s, d *TimeSeries, r *tsRule, start int64) {
+	args := &TSRangeArgs{Aggregation: r.aggregation, Bucket: time.Duration(r.bucket) * time.Millisecond}
+	samples := s.rangeSamples(start, start+r.bucket-1, args, false)
+	if len(samples) == 0 {
+		return
+	}
+	if samples[0].Timestamp >= d.cutoff() {
+		_ = d.upsert(samples[0].Timestamp, samples[0].Value, TSDuplicateLast)
+	}
+}
+
+// get 获取k对应的未过期时间序列，不存在时返回nil
+func (ts *TimeSeriesSet) get(k string) *TimeSeries {
+	if !ts.exist(k) {
+		return nil
+	}
+	return ts.items[k]
+}
+
+// Del 删除一个key
+func (ts *TimeSeriesSet) Del(k string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.del(k)
+}
+
+// del 删除k，同时删除以k为src或dst的压缩规则
+func (ts *TimeSeriesSet) del(k string) {
+	s, exist := ts.items[k]
+	if !exist {
+		return
+	}
+	delete(ts.items, k)
+	if src := ts.items[s.source]; src != nil {
+		for i, r := range src.rules {
+			if r.dst == k {
+				src.rules = append(src.rules[:i], src.rules[i+1:]...)
+				break
+			}
+		}
+	}
+	for _, r := range s.rules {
+		if d := ts.items[r.dst]; d != nil {
+			d.source = ""
+		}
+	}
+}
+
+// Expiration 设置超时时间
+func (ts *TimeSeriesSet) Expiration(k string, d time.Duration) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if !ts.exist(k) {
+		return ErrKeyNotExist
+	}
+	ts.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key，并清理超出保留时长的样本
+func (ts *TimeSeriesSet) ClearExpiration() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for key, item := range ts.items {
+		if item.isExpired() {
+			ts.del(key)
+			continue
+		}
+		item.trim()
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key，并清理超出保留时长的样本
+func (ts *TimeSeriesSet) RandomClearExpiration() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	var counter int
+	for key, item := range ts.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			ts.del(key)
+		} else {
+			item.trim()
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (ts *TimeSeriesSet) Flush() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.items = make(map[string]*TimeSeries)
+}
+
+// newTimeSeries 按照参数创建一个空的时间序列
+func newTimeSeries(args TSCreateArgs) (*TimeSeries, error) {
+	if args.Retention < 0 || !validDuplicatePolicy(args.DuplicatePolicy) {
+		return nil, ErrTSArgs
+	}
+	if args.DuplicatePolicy == "" {
+		args.DuplicatePolicy = TSDuplicateBlock
+	}
+	labels := make(map[string]string, len(args.Labels))
+	for name, value := range args.Labels {
+		labels[name] = value
+	}
+	return &TimeSeries{
+		retention:  args.Retention.Milliseconds(),
+		policy:     args.DuplicatePolicy,
+		labels:     labels,
+		expiration: DefaultExpiration,
+	}, nil
+}
+
+// TimeSeries 按时间戳排列的样本
+// retention 为毫秒保留时长，超出的样本在读写时忽略，由GC清理
+// rules 为以该序列为src的压缩规则，source 为以该序列为dst的规则的src
+type TimeSeries struct {
+	samples    []TSSample
+	retention  int64
+	policy     TSDuplicatePolicy
+	labels     map[string]string
+	rules      []*tsRule
+	source     string
+	expiration int64
+}
+
+// tsRule 压缩规则，current 为正在写入的时间桶的起始时间
+type tsRule struct {
+	dst         string
+	aggregation TSAggregation
+	bucket      int64
+	current     int64
+	started     bool
+}
+
+// upsert 添加样本，时间戳已存在时按policy处理
+func (s *TimeSeries) upsert(timestamp int64, value float64, policy TSDuplicatePolicy) error {
+	if timestamp < s.cutoff() {
+		return ErrTSRetention
+	}
+	n := len(s.samples)
+	if n == 0 || timestamp > s.samples[n-1].Timestamp {
+		s.samples = append(s.samples, TSSample{Timestamp: timestamp, Value: value})
+		return nil
+	}
+	i := sort.Search(n, func(i int) bool {
+		return s.samples[i].Timestamp >= timestamp
+	})
+	if s.samples[i].Timestamp != timestamp {
+		s.samples = append(s.samples, TSSample{})
+		copy(s.samples[i+1:], s.samples[i:])
+		s.samples[i] = TSSample{Timestamp: timestamp, Value: value}
+		return nil
+	}
+	old := &s.samples[i].Value
+	switch policy {
+	case TSDuplicateBlock:
+		return ErrTSDuplicate
+	case TSDuplicateLast:
+		*old = value
+	case TSDuplicateMin:
+		if value < *old {
+			*old = value
+		}
+	case TSDuplicateMax:
+		if value > *old {
+			*old = value
+		}
+	case TSDuplicateSum:
+		*old += value
+	}
+	return nil
+}
+
+// cutoff 获取保留的最早时间戳
+func (s *TimeSeries) cutoff() int64 {
+	if s.retention == 0 || len(s.samples) == 0 {
+		return 0
+	}
+	return s.samples[len(s.samples)-1].Timestamp - s.retention
+}
+
+// trim 清理超出保留时长的样本，清理的样本较多时重新分配空间
+func (s *TimeSeries) trim() {
+	cutoff := s.cutoff()
+	if len(s.samples) == 0 || s.samples[0].Timestamp >= cutoff {
+		return
+	}
+	i := sort.Search(len(s.samples), func(i int) bool {
+		return s.samples[i].Timestamp >= cutoff
+	})
+	if i > cap(s.samples)/2 {
+		s.samples = append([]TSSample{}, s.samples[i:]...)
+	} else {
+		s.samples = s.samples[i:]
+	}
+}
+
+// rangeSamples 获取时间戳在[from, to]之间的样本，按args聚合
+func (s *TimeSeries) rangeSamples(from, to int64, args *TSRangeArgs, rev bool) []TSSample {
+	if cutoff := s.cutoff(); from < cutoff {
+		from = cutoff
+	}
+	lo := sort.Search(len(s.samples), func(i int) bool {
+		return s.samples[i].Timestamp >= from
+	})
+	hi := sort.Search(len(s.samples), func(i int) bool {
+		return s.samples[i].Timestamp > to
+	})
+	if lo >= hi {
+		return []TSSample{}
+	}
+	samples := s.samples[lo:hi]
+	result := make([]TSSample, 0, len(samples))
+	if args.Aggregation == "" {
+		result = append(result, samples...)
+	} else {
+		bucket := args.Bucket.Milliseconds()
+		var agg tsAggregator
+		for i, sample := range samples {
+			start := tsBucketStart(sample.Timestamp, bucket)
+			if i > 0 && start != agg.start {
+				result = append(result, TSSample{Timestamp: agg.start, Value: agg.value(args.Aggregation)})
+			}
+			if i == 0 || start != agg.start {
+				agg = tsAggregator{start: start}
+			}
+			agg.add(sample.Value)
+		}
+		result = append(result, TSSample{Timestamp: agg.start, Value: agg.value(args.Aggregation)})
+	}
+	if rev {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	if args.Count > 0 && len(result) > args.Count {
+		result = result[:args.Count]
+	}
+	return result
+}
+
+// match 判断标签是否满足所有过滤条件
+func (s *TimeSeries) match(matchers []tsMatcher) bool {
+	for _, m := range matchers {
+		value, exist := s.labels[m.label]
+		in := false
+		for _, v := range m.values {
+			if exist && v == value {
+				in = true
+				break
+			}
+		}
+		switch {
+		case len(m.values) == 0 && exist == !m.not:
+			return false
+		case len(m.values) > 0 && in == m.not:
+			return false
+		}
+	}
+	return true
+}
+
+// isExpired 判断一个元素是否过期
+func (s *TimeSeries) isExpired() bool {
+	if s.expiration != DefaultExpiration && time.Now().UnixNano() > s.expiration {
+		return true
+	}
+	return false
+}
+
+// tsAggregator 一个时间桶的聚合状态
+type tsAggregator struct {
+	start       int64
+	count       int
+	sum         float64
+	min, max    float64
+	first, last float64
+}
+
+// add 添加一个样本的值
+func (a *tsAggregator) add(v float64) {
+	if a.count == 0 {
+		a.min, a.max, a.first = v, v, v
+	}
+	if v < a.min {
+		a.min = v
+	}
+	if v > a.max {
+		a.max = v
+	}
+	a.last = v
+	a.sum += v
+	a.count++
+}
+
+// value 获取聚合的结果
+func (a *tsAggregator) value(aggregation TSAggregation) float64 {
+	switch aggregation {
+	case TSAggAvg:
+		return a.sum / float64(a.count)
+	case TSAggSum:
+		return a.sum
+	case TSAggMin:
+		return a.min
+	case TSAggMax:
+		return a.max
+	case TSAggCount:
+		return float64(a.count)
+	case TSAggFirst:
+		return a.first
+	}
+	return a.last
+}
+
+// tsMatcher 解析后的标签过滤条件
+// values 为空时，not 为false表示标签不存在，为true表示标签存在
+type tsMatcher struct {
+	label  string
+	not    bool
+	values []string
+}
+
+// parseTSFilters 解析标签过滤条件
+func parseTSFilters(filters []string) ([]tsMatcher, error) {
+	matchers := make([]tsMatcher, 0, len(filters))
+	var positive bool
+	for _, filter := range filters {
+		label, value, found := strings.Cut(filter, "=")
+		if !found {
+			return nil, ErrTSFilter
+		}
+		m := tsMatcher{label: label}
+		if m.label, m.not = strings.CutSuffix(label, "!"); m.label == "" {
+			return nil, ErrTSFilter
+		}
+		if list, ok := strings.CutPrefix(value, "("); ok {
+			list, ok = strings.CutSuffix(list, ")")
+			if !ok {
+				return nil, ErrTSFilter
+			}
+			for _, v := range strings.Split(list, ",") {
+				m.values = append(m.values, strings.TrimSpace(v))
+			}
+		} else if value != "" {
+			m.values = []string{value}
+		}
+		if !m.not && len(m.values) > 0 {
+			positive = true
+		}
+		matchers = append(matchers, m)
+	}
+	if !positive {
+		return nil, ErrTSFilter
+	}
+	return matchers, nil
+}
+
+// validDuplicatePolicy 判断是否为有效的DuplicatePolicy，为空时使用默认值
+func validDuplicatePolicy(policy TSDuplicatePolicy) bool {
+	switch policy {
+	case "", TSDuplicateBlock, TSDuplicateFirst, TSDuplicateLast, TSDuplicateMin, TSDuplicateMax, TSDuplicateSum:
+		return true
+	}
+	return false
+}
+
+// validRangeArgs 判断查询参数是否有效，聚合时Bucket需至少为1毫秒
+func validRangeArgs(args *TSRangeArgs) bool {
+	switch args.Aggregation {
+	case "":
+		return args.Count >= 0
+	case TSAggAvg, TSAggSum, TSAggMin, TSAggMax, TSAggCount, TSAggFirst, TSAggLast:
+		return args.Count >= 0 && args.Bucket >= time.Millisecond
+	}
+	return false
+}
+
+// tsBucketStart 获取时间戳所在时间桶的起始时间
+func tsBucketStart(timestamp, bucket int64) int64 {
+	return timestamp - ((timestamp%bucket)+bucket)%bucket
+}
@@ -0,0 +1,407 @@
+package types
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Top-K的默认参数与RedisBloom一致
+const (
+	topKDefaultWidth = 8      // 每行的桶数量
+	topKDefaultDepth = 7      // 行数
+	topKDefaultDecay = 0.9    // 指纹不同时计数衰减的概率底数
+	topKSeed         = 0x77e5 // 计算元素指纹的种子
+)
+
+// NewTopKs 创建Top-K类型实例
+func NewTopKs() *TopKs {
+	return &TopKs{
+		items: make(map[string]*TopK),
+	}
+}
+
+// TopKs Top-K类型数据结构
+type TopKs struct {
+	mu    sync.Mutex
+	items map[string]*TopK
+}
+
+// TopKReserveArgs 创建Top-K的参数
+// TopK 为保留的元素数量；Width、Depth 为HeavyKeeper每行的桶数量和行数，为0时为8和7
+// Decay 为计数衰减的概率底数，取值范围(0, 1]，为0时为0.9
+type TopKReserveArgs struct {
+	TopK  int
+	Width int
+	Depth int
+	Decay float64
+}
+
+// TopKItem Top-K中的元素和估算的计数
+type TopKItem struct {
+	Item  string
+	Count int64
+}
+
+// Exist 判断k是否存在
+func (ts *TopKs) Exist(k string) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.exist(k)
+}
+
+// exist 判断k是否存在
+func (ts *TopKs) exist(k string) bool {
+	t, exist := ts.items[k]
+	if !exist {
+		return false
+	}
+	if t.isExpired() {
+		ts.del(k)
+		return false
+	}
+	return true
+}
+
+// TopKReserve 按照args创建Top-K，k已存在时返回 ErrSketchExists
+func (ts *TopKs) TopKReserve(k string, args TopKReserveArgs) error {
+	if args.Width == 0 {
+		args.Width = topKDefaultWidth
+	}
+	if args.Depth == 0 {
+		args.Depth = topKDefaultDepth
+	}
+	if args.Decay == 0 {
+		args.Decay = topKDefaultDecay
+	}
+	if args.TopK <= 0 || args.Width < 0 || args.Depth < 0 || args.Decay < 0 || args.Decay > 1 {
+		return ErrSketchArgs
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.exist(k) {
+		return ErrSketchExists
+	}
+	ts.items[k] = newTopK(args)
+	return nil
+}
+
+// TopKAdd 向Top-K中添加元素，每个元素计数加1
+// return []string 为每个元素添加后被挤出Top-K的元素，没有被挤出时为空字符串
+func (ts *TopKs) TopKAdd(k string, items ...any) ([]string, error) {
+	members, err := toMembers(items)
+	if err != nil {
+		return nil, err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	t := ts.get(k)
+	if t == nil {
+		return nil, ErrSketchKey
+	}
+	expelled := make([]string, len(members))
+	for i, m := range members {
+		expelled[i] = t.add(m, 1)
+	}
+	return expelled, nil
+}
+
+// TopKIncrBy 将元素的计数增加increment，increment 需大于0
+// return string 为被挤出Top-K的元素，没有被挤出时为空字符串
+func (ts *TopKs) TopKIncrBy(k string, item any, increment int64) (string, error) {
+	m, err := toMember(item)
+	if err != nil {
+		return "", err
+	}
+	if increment <= 0 {
+		return "", ErrSketchArgs
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	t := ts.get(k)
+	if t == nil {
+		return "", ErrSketchKey
+	}
+	return t.add(m, increment), nil
+}
+
+// TopKQuery 判断元素是否在Top-K中
+func (ts *TopKs) TopKQuery(k string, items ...any) ([]bool, error) {
+	members, err := toMembers(items)
+	if err != nil {
+		return nil, err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	t := ts.get(k)
+	if t == nil {
+		return nil, ErrSketchKey
+	}
+	results := make([]bool, len(members))
+	for i, m := range members {
+		_, results[i] = t.index[m]
+	}
+	return results, nil
+}
+
+// TopKCount 获取元素计数的估算值，元素不在Top-K中时也可以估算
+func (ts *TopKs) TopKCount(k string, items ...any) ([]int64, error) {
+	members, err := toMembers(items)
+	if err != nil {
+		return nil, err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	t := ts.get(k)
+	if t == nil {
+		return nil, ErrSketchKey
+	}
+	counts := make([]int64, len(members))
+	for i, m := range members {
+		counts[i] = t.count(m)
+	}
+	return counts, nil
+}
+
+// TopKList 获取Top-K中的元素，按计数从高到低排列
+func (ts *TopKs) TopKList(k string) ([]TopKItem, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	t := ts.get(k)
+	if t == nil {
+		return nil, ErrSketchKey
+	}
+	list := make([]TopKItem, len(t.heap))
+	for i, e := range t.heap {
+		list[i] = TopKItem{Item: e.item, Count: e.count}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Item < list[j].Item
+	})
+	return list, nil
+}
+
+// get 获取k对应的未过期Top-K，不存在时返回nil
+func (ts *TopKs) get(k string) *TopK {
+	if !ts.exist(k) {
+		return nil
+	}
+	return ts.items[k]
+}
+
+// Del 删除一个key
+func (ts *TopKs) Del(k string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.del(k)
+}
+
+func (ts *TopKs) del(k string) {
+	delete(ts.items, k)
+}
+
+// Expiration 设置超时时间
+func (ts *TopKs) Expiration(k string, d time.Duration) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if !ts.exist(k) {
+		return ErrKeyNotExist
+	}
+	ts.items[k].expiration = time.Now().Add(d).UnixNano()
+	return nil
+}
+
+// ClearExpiration 清理过期的key
+func (ts *TopKs) ClearExpiration() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for key, item := range ts.items {
+		if item.isExpired() {
+			delete(ts.items, key)
+		}
+	}
+}
+
+// RandomClearExpiration 随机清理过期的key
+func (ts *TopKs) RandomClearExpiration() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	var counter int
+	for key, item := range ts.items {
+		if counter > DefaultCleanItems {
+			return
+		}
+		if item.isExpired() {
+			delete(ts.items, key)
+		}
+		counter++
+	}
+}
+
+// Flush 清空缓存
+func (ts *TopKs) Flush() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.items = make(map[string]*TopK)
+}
+
+// newTopK 创建一个Top-K
+func newTopK(args TopKReserveArgs) *TopK {
+	return &TopK{
+		k:          args.TopK,
+		width:      args.Width,
+		depth:      args.Depth,
+		decay:      args.Decay,
+		buckets:    make([]topKBucket, args.Width*args.Depth),
+		index:      make(map[string]int, args.TopK),
+		expiration: DefaultExpiration,
+	}
+}
+
+// TopK 使用HeavyKeeper算法统计计数最高的k个元素
+// buckets 为depth行、每行width个桶，每个桶记录一个指纹和计数
+// 元素的指纹与桶中不同时，桶的计数以 decay^count 的概率衰减，衰减为0后由新元素占据
+// heap 为计数最小的元素在堆顶的小顶堆，index 为元素在堆中的位置
+type TopK struct {
+	k          int
+	width      int
+	depth      int
+	decay      float64
+	buckets    []topKBucket
+	heap       topKHeap
+	index      map[string]int
+	expiration int64
+}
+
+// topKBucket HeavyKeeper的桶
+type topKBucket struct {
+	fp    uint32
+	count int64
+}
+
+// add 增加元素的计数，并更新堆
+// return string 为被挤出堆的元素，没有被挤出时为空字符串
+func (t *TopK) add(m string, increment int64) string {
+	fp := topKFingerprint(m)
+	var maxCount int64
+	for i := 0; i < t.depth; i++ {
+		b := &t.buckets[t.bucket(m, i)]
+		switch {
+		case b.count == 0:
+			b.fp, b.count = fp, increment
+		case b.fp == fp:
+			b.count += increment
+		default:
+			for n := increment; n > 0; n-- {
+				if rand.Float64() >= math.Pow(t.decay, float64(b.count)) {
+					continue
+				}
+				if b.count--; b.count == 0 {
+					b.fp, b.count = fp, n
+					break
+				}
+			}
+		}
+		if b.fp == fp && b.count > maxCount {
+			maxCount = b.count
+		}
+	}
+	if i, exist := t.index[m]; exist {
+		if maxCount > t.heap[i].count {
+			t.heap[i].count = maxCount
+			heap.Fix(t, i)
+		}
+		return ""
+	}
+	if len(t.heap) < t.k {
+		heap.Push(t, topKEntry{item: m, count: maxCount})
+		return ""
+	}
+	if maxCount < t.heap[0].count {
+		return ""
+	}
+	expelled := t.heap[0].item
+	delete(t.index, expelled)
+	t.heap[0] = topKEntry{item: m, count: maxCount}
+	t.index[m] = 0
+	heap.Fix(t, 0)
+	return expelled
+}
+
+// count 获取元素计数的估算值，为指纹相同的桶中的最大计数
+func (t *TopK) count(m string) int64 {
+	fp := topKFingerprint(m)
+	var count int64
+	for i := 0; i < t.depth; i++ {
+		if b := t.buckets[t.bucket(m, i)]; b.fp == fp && b.count > count {
+			count = b.count
+		}
+	}
+	return count
+}
+
+// bucket 获取元素在第row行的桶位置
+func (t *TopK) bucket(m string, row int) int {
+	return row*t.width + int(murmurHash64A([]byte(m), uint64(row))%uint64(t.width))
+}
+
+// isExpired 判断一个元素是否过期
+func (t *TopK) isExpired() bool {
+	if t.expiration != DefaultExpiration && time.Now().UnixNano() > t.expiration {
+		return true
+	}
+	return false
+}
+
+// topKEntry 堆中的元素
+type topKEntry struct {
+	item  string
+	count int64
+}
+
+// topKHeap 按计数排列的小顶堆
+type topKHeap []topKEntry
+
+// Len 实现 heap.Interface
+func (t *TopK) Len() int {
+	return len(t.heap)
+}
+
+// Less 实现 heap.Interface
+func (t *TopK) Less(i, j int) bool {
+	return t.heap[i].count < t.heap[j].count
+}
+
+// Swap 实现 heap.Interface，同时更新元素在堆中的位置
+func (t *TopK) Swap(i, j int) {
+	t.heap[i], t.heap[j] = t.heap[j], t.heap[i]
+	t.index[t.heap[i].item] = i
+	t.index[t.heap[j].item] = j
+}
+
+// Push 实现 heap.Interface
+func (t *TopK) Push(x any) {
+	e := x.(topKEntry)
+	t.index[e.item] = len(t.heap)
+	t.heap = append(t.heap, e)
+}
+
+// Pop 实现 heap.Interface
+func (t *TopK) Pop() any {
+	n := len(t.heap)
+	e := t.heap[n-1]
+	t.heap = t.heap[:n-1]
+	delete(t.index, e.item)
+	return e
+}
+
+// topKFingerprint 计算元素的指纹
+func topKFingerprint(m string) uint32 {
+	return uint32(murmurHash64A([]byte(m), topKSeed))
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"math"
 	"sort"
 	"sync"
 	"time"
@@ -40,7 +41,12 @@ func (zs *ZSets) exist(k string) bool {
 }
 
 // ZAdd 向有序集合中添加一个元素
-func (zs *ZSets) ZAdd(key, element string, score float64) bool {
+// return exist bool 表示添加前key是否存在
+func (zs *ZSets) ZAdd(key string, e any, score float64) (bool, error) {
+	element, err := toMember(e)
+	if err != nil {
+		return false, err
+	}
 	zs.mu.Lock()
 	defer zs.mu.Unlock()
 	z, exist := zs.items[key]
@@ -49,21 +55,30 @@ func (zs *ZSets) ZAdd(key, element string, score float64) bool {
 	}
 	z.ZAdd(element, score)
 	zs.items[key] = z
-	return exist
+	return exist, nil
 }
 
 // ZRem 从有序集合中，删除一个元素
-func (zs *ZSets) ZRem(key, element string) {
+func (zs *ZSets) ZRem(key string, e any) error {
+	element, err := toMember(e)
+	if err != nil {
+		return err
+	}
 	zs.mu.Lock()
 	defer zs.mu.Unlock()
 	z, exist := zs.items[key]
 	if exist {
 		z.ZRem(element)
 	}
+	return nil
 }
 
 // ZIncrBy 向有序集合中一个元素,增加score
-func (zs *ZSets) ZIncrBy(key, element string, score float64) float64 {
+func (zs *ZSets) ZIncrBy(key string, e any, score float64) (float64, error) {
+	element, err := toMember(e)
+	if err != nil {
+		return DefaultScore, err
+	}
 	zs.mu.Lock()
 	defer zs.mu.Unlock()
 	z, exist := zs.items[key]
@@ -72,11 +87,15 @@ func (zs *ZSets) ZIncrBy(key, element string, score float64) float64 {
 	}
 	res := z.ZIncrBy(element, score)
 	zs.items[key] = z
-	return res
+	return res, nil
 }
 
 // ZDecrBy 向有序集合中一个元素,减少score
-func (zs *ZSets) ZDecrBy(key, element string, score float64) float64 {
+func (zs *ZSets) ZDecrBy(key string, e any, score float64) (float64, error) {
+	element, err := toMember(e)
+	if err != nil {
+		return DefaultScore, err
+	}
 	zs.mu.Lock()
 	defer zs.mu.Unlock()
 	z, exist := zs.items[key]
@@ -85,7 +104,7 @@ func (zs *ZSets) ZDecrBy(key, element string, score float64) float64 {
 	}
 	res := z.ZDecrBy(element, score)
 	zs.items[key] = z
-	return res
+	return res, nil
 }
 
 // ZCard 获取有序集合的元素数量
@@ -103,56 +122,60 @@ func (zs *ZSets) ZCard(key string) int {
 }
 
 // ZRank 获取有序集合的元素排名
-func (zs *ZSets) ZRank(key, element string) int {
+func (zs *ZSets) ZRank(key string, e any) int {
+	element, err := toMember(e)
+	if err != nil {
+		return ErrorRank
+	}
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
-		return ErrorRank
-	} else if z.isExpired() {
-		zs.del(key)
+	z := zs.lookup(key)
+	if z == nil {
 		return ErrorRank
 	}
 	return z.ZRank(element)
 }
 
 // ZRankWithScore 获取有序集合的元素排名和score
-func (zs *ZSets) ZRankWithScore(key, element string) (int, float64) {
+func (zs *ZSets) ZRankWithScore(key string, e any) (int, float64) {
+	element, err := toMember(e)
+	if err != nil {
+		return ErrorRank, DefaultScore
+	}
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
-		return ErrorRank, DefaultScore
-	} else if z.isExpired() {
-		zs.del(key)
+	z := zs.lookup(key)
+	if z == nil {
 		return ErrorRank, DefaultScore
 	}
 	return z.ZRankWithScore(element)
 }
 
 // ZRevRank 获取有序集合的元素倒数排名
-func (zs *ZSets) ZRevRank(key, element string) int {
+func (zs *ZSets) ZRevRank(key string, e any) int {
+	element, err := toMember(e)
+	if err != nil {
+		return ErrorRank
+	}
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
-		return ErrorRank
-	} else if z.isExpired() {
-		zs.del(key)
+	z := zs.lookup(key)
+	if z == nil {
 		return ErrorRank
 	}
 	return z.ZRevRank(element)
 }
 
 // ZRevRankWithScore 获取有序集合的元素倒数排名和score
-func (zs *ZSets) ZRevRankWithScore(key, element string) (int, float64) {
+func (zs *ZSets) ZRevRankWithScore(key string, e any) (int, float64) {
+	element, err := toMember(e)
+	if err != nil {
+		return ErrorRank, DefaultScore
+	}
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
-		return ErrorRank, DefaultScore
-	} else if z.isExpired() {
-		zs.del(key)
+	z := zs.lookup(key)
+	if z == nil {
 		return ErrorRank, DefaultScore
 	}
 	return z.ZRevRankWithScore(element)
@@ -162,16 +185,10 @@ func (zs *ZSets) ZRevRankWithScore(key, element string) (int, float64) {
 func (zs *ZSets) ZRange(key string, start, stop int) ([]string, error) {
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
-		return nil, ErrZSetKey
-	} else if z.isExpired() {
-		zs.del(key)
+	z := zs.lookup(key)
+	if z == nil {
 		return nil, ErrZSetKey
 	}
-	if start > stop {
-		return nil, ErrStartStop
-	}
 	return z.ZRange(start, stop), nil
 }
 
@@ -179,16 +196,10 @@ func (zs *ZSets) ZRange(key string, start, stop int) ([]string, error) {
 func (zs *ZSets) ZRangeWithScore(key string, start, stop int) (map[string]float64, error) {
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
-		return nil, ErrZSetKey
-	} else if z.isExpired() {
-		zs.del(key)
+	z := zs.lookup(key)
+	if z == nil {
 		return nil, ErrZSetKey
 	}
-	if start > stop {
-		return nil, ErrStartStop
-	}
 	return z.ZRangeWithScores(start, stop), nil
 }
 
@@ -196,16 +207,10 @@ func (zs *ZSets) ZRangeWithScore(key string, start, stop int) (map[string]float6
 func (zs *ZSets) ZRevRange(key string, start, stop int) ([]string, error) {
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
-		return nil, ErrZSetKey
-	} else if z.isExpired() {
-		zs.del(key)
+	z := zs.lookup(key)
+	if z == nil {
 		return nil, ErrZSetKey
 	}
-	if start > stop {
-		return nil, ErrStartStop
-	}
 	return z.ZRevRange(start, stop), nil
 }
 
@@ -213,19 +218,199 @@ func (zs *ZSets) ZRevRange(key string, start, stop int) ([]string, error) {
 func (zs *ZSets) ZRevRangeWithScore(key string, start, stop int) (map[string]float64, error) {
 	zs.mu.RLock()
 	defer zs.mu.RUnlock()
-	z, exist := zs.items[key]
-	if !exist {
+	z := zs.lookup(key)
+	if z == nil {
 		return nil, ErrZSetKey
-	} else if z.isExpired() {
-		zs.del(key)
-		return nil, ErrZSetKey
-	}
-	if start > stop {
-		return nil, ErrStartStop
 	}
 	return z.ZRevRangeWithScore(start, stop), nil
 }
 
+// ZScan 按游标遍历有序集合中的元素和score
+// match 为元素需匹配的glob模式，为空时不过滤；count 为本次检查的元素数量
+// 返回本次遍历到的元素和score，以及下一次的游标，游标为0表示遍历结束
+func (zs *ZSets) ZScan(key string, cursor uint64, match string, count int) (map[string]float64, uint64, error) {
+	zs.mu.RLock()
+	defer zs.mu.RUnlock()
+	z := zs.lookup(key)
+	if z == nil {
+		return nil, 0, ErrZSetKey
+	}
+	result := make(map[string]float64)
+	next := z.elements.scanMatch(cursor, match, count, func(e *dictEntry[float64]) {
+		result[e.key] = e.value
+	})
+	return result, next, nil
+}
+
+// ZStore 有序集合运算参数
+// Keys 参与运算的key，普通集合中的元素score视为1
+// Weights 每个key的权重，为空时权重均为1
+// Aggregate 相同元素score的聚合方式，为空时为SUM
+type ZStore struct {
+	Keys      []string
+	Weights   []float64
+	Aggregate Aggregate
+}
+
+// ZUnion 获取多个有序集合的并集
+func (zs *ZSets) ZUnion(store *ZStore, sets *Sets) (map[string]float64, error) {
+	scores := setScores(sets, store.Keys)
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	return zs.zUnion(store, scores)
+}
+
+// ZUnionStore 计算多个有序集合的并集，并存储到dst中
+// return int 为dst中元素的数量
+func (zs *ZSets) ZUnionStore(dst string, store *ZStore, sets *Sets) (int, error) {
+	scores := setScores(sets, store.Keys)
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	elements, err := zs.zUnion(store, scores)
+	if err != nil {
+		return 0, err
+	}
+	return zs.store(dst, elements), nil
+}
+
+// ZInter 获取多个有序集合的交集
+func (zs *ZSets) ZInter(store *ZStore, sets *Sets) (map[string]float64, error) {
+	scores := setScores(sets, store.Keys)
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	return zs.zInter(store, scores)
+}
+
+// ZInterStore 计算多个有序集合的交集，并存储到dst中
+// return int 为dst中元素的数量
+func (zs *ZSets) ZInterStore(dst string, store *ZStore, sets *Sets) (int, error) {
+	scores := setScores(sets, store.Keys)
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	elements, err := zs.zInter(store, scores)
+	if err != nil {
+		return 0, err
+	}
+	return zs.store(dst, elements), nil
+}
+
+// ZDiff 获取第一个有序集合与其他集合的差集
+func (zs *ZSets) ZDiff(keys []string, sets *Sets) (map[string]float64, error) {
+	scores := setScores(sets, keys)
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	return zs.zDiff(keys, scores)
+}
+
+// ZDiffStore 计算第一个有序集合与其他集合的差集，并存储到dst中
+// return int 为dst中元素的数量
+func (zs *ZSets) ZDiffStore(dst string, keys []string, sets *Sets) (int, error) {
+	scores := setScores(sets, keys)
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	elements, err := zs.zDiff(keys, scores)
+	if err != nil {
+		return 0, err
+	}
+	return zs.store(dst, elements), nil
+}
+
+// zUnion -
+func (zs *ZSets) zUnion(store *ZStore, setScores map[string]map[string]float64) (map[string]float64, error) {
+	if err := store.validate(); err != nil {
+		return nil, err
+	}
+	result := make(map[string]float64)
+	for i, input := range zs.inputs(store.Keys, setScores) {
+		for e, score := range input {
+			score = store.weighted(i, score)
+			if old, exist := result[e]; exist {
+				score = store.aggregate(old, score)
+			}
+			result[e] = score
+		}
+	}
+	return result, nil
+}
+
+// zInter -
+func (zs *ZSets) zInter(store *ZStore, setScores map[string]map[string]float64) (map[string]float64, error) {
+	if err := store.validate(); err != nil {
+		return nil, err
+	}
+	inputs := zs.inputs(store.Keys, setScores)
+	result := make(map[string]float64)
+	for e, score := range inputs[0] {
+		score = store.weighted(0, score)
+		inAll := true
+		for i := 1; i < len(inputs); i++ {
+			other, exist := inputs[i][e]
+			if !exist {
+				inAll = false
+				break
+			}
+			score = store.aggregate(score, store.weighted(i, other))
+		}
+		if inAll {
+			result[e] = score
+		}
+	}
+	return result, nil
+}
+
+// zDiff -
+func (zs *ZSets) zDiff(keys []string, setScores map[string]map[string]float64) (map[string]float64, error) {
+	if len(keys) == 0 {
+		return nil, ErrZStoreKeys
+	}
+	inputs := zs.inputs(keys, setScores)
+	result := make(map[string]float64)
+	for e, score := range inputs[0] {
+		inOther := false
+		for i := 1; i < len(inputs); i++ {
+			if _, exist := inputs[i][e]; exist {
+				inOther = true
+				break
+			}
+		}
+		if !inOther {
+			result[e] = score
+		}
+	}
+	return result, nil
+}
+
+// inputs 获取参与运算的各个集合的元素和score，key不是有序集合时使用setScores中普通集合的元素，不存在的key视为空集合
+func (zs *ZSets) inputs(keys []string, setScores map[string]map[string]float64) []map[string]float64 {
+	inputs := make([]map[string]float64, len(keys))
+	for i, k := range keys {
+		if z, exist := zs.items[k]; exist && !z.isExpired() {
+			inputs[i] = z.scores()
+		} else {
+			inputs[i] = setScores[k]
+		}
+	}
+	return inputs
+}
+
+// setScores 在锁定有序集合之前获取keys中普通集合的元素，避免同时持有ZSets和Sets的锁
+func setScores(sets *Sets, keys []string) map[string]map[string]float64 {
+	if sets == nil {
+		return nil
+	}
+	return sets.scores(keys)
+}
+
+// store 将运算结果存储到dst中，结果为空时删除dst
+func (zs *ZSets) store(dst string, elements map[string]float64) int {
+	if len(elements) == 0 {
+		zs.del(dst)
+		return 0
+	}
+	zs.items[dst] = newZSetWithElements(elements)
+	return len(elements)
+}
+
 // Del 删除一个key
 func (zs *ZSets) Del(k string) {
 	zs.mu.Lock()
@@ -233,6 +418,16 @@ func (zs *ZSets) Del(k string) {
 	zs.del(k)
 }
 
+// lookup 获取key对应的未过期有序集合，不存在时返回nil
+// 只持有读锁时使用，过期的key交由写操作或GC清理
+func (zs *ZSets) lookup(key string) *ZSet {
+	z, exist := zs.items[key]
+	if !exist || z.isExpired() {
+		return nil
+	}
+	return z
+}
+
 // del 删除一个key
 func (zs *ZSets) del(k string) {
 	delete(zs.items, k)
@@ -286,57 +481,90 @@ func (zs *ZSets) Flush() {
 // NewZSet 创建一个集合的实例
 func newZSet() *ZSet {
 	return &ZSet{
-		elements:   make(map[string]float64),
+		elements:   newDict[float64](),
 		expiration: DefaultExpiration,
 	}
 }
 
+// newZSetWithElements 使用已有的元素创建一个集合的实例
+func newZSetWithElements(elements map[string]float64) *ZSet {
+	z := &ZSet{
+		elements:   newDict[float64](),
+		sorted:     make([]string, 0, len(elements)),
+		expiration: DefaultExpiration,
+	}
+	for e, score := range elements {
+		z.elements.set(e, score)
+		z.sorted = append(z.sorted, e)
+	}
+	z.sort()
+	return z
+}
+
 // ZSet 缓存集合
+// elements 存储元素和score，支持游标遍历
+// sorted 为按score从高到低排列的元素，score相同时按元素从大到小排列，可以二分查找
 type ZSet struct {
-	elements   map[string]float64
+	elements   *dict[float64]
 	sorted     []string
 	expiration int64
 }
 
-// ZAdd 向有序集合中添加一个元素
+// ZAdd 向有序集合中添加一个元素，元素已存在时更新score
 func (z *ZSet) ZAdd(e string, score float64) {
-	if _, exist := z.elements[e]; exist {
-		z.ZRevRank(e)
+	if old, exist := z.elements.get(e); exist {
+		if old == score {
+			return
+		}
+		z.remove(e, old)
 	}
-	z.elements[e] = score
-	z.sorted = append(z.sorted, e)
-	sort.Slice(z.sorted, func(i, j int) bool {
-		return z.elements[z.sorted[i]] > z.elements[z.sorted[j]]
-	})
+	z.elements.set(e, score)
+	i := z.search(e, score)
+	z.sorted = append(z.sorted, "")
+	copy(z.sorted[i+1:], z.sorted[i:])
+	z.sorted[i] = e
+}
+
+// addMany 批量添加元素，元素已存在时更新score
+// 数量较多时不再逐个插入，添加后统一排序
+func (z *ZSet) addMany(elements []string, scores []float64) {
+	if len(elements) < zSetBatchSort {
+		for i, e := range elements {
+			z.ZAdd(e, scores[i])
+		}
+		return
+	}
+	for i, e := range elements {
+		if z.elements.set(e, scores[i]) {
+			z.sorted = append(z.sorted, e)
+		}
+	}
+	z.sort()
 }
 
 // ZRem 从有序集合中，删除一个元素
 func (z *ZSet) ZRem(e string) {
-	delete(z.elements, e)
-	for i, m := range z.sorted {
-		if m == e {
-			z.sorted = append(z.sorted[:i], z.sorted[i+1:]...)
-			break
-		}
+	score, exist := z.elements.get(e)
+	if !exist {
+		return
 	}
+	z.remove(e, score)
+	z.elements.del(e)
 }
 
 // ZIncrBy 向有序集合中一个元素,增加score
 func (z *ZSet) ZIncrBy(e string, score float64) float64 {
-	if _, exist := z.elements[e]; !exist {
-		z.elements[e] = DefaultScore
+	current, exist := z.elements.get(e)
+	if !exist {
+		current = DefaultScore
 	}
-	z.elements[e] += score
-	return z.elements[e]
+	z.ZAdd(e, current+score)
+	return current + score
 }
 
 // ZDecrBy 向有序集合中一个元素,减少score
 func (z *ZSet) ZDecrBy(e string, score float64) float64 {
-	if _, exist := z.elements[e]; !exist {
-		z.elements[e] = DefaultScore
-	}
-	z.elements[e] -= score
-	return z.elements[e]
+	return z.ZIncrBy(e, -score)
 }
 
 // ZCard 获取有序集合的元素数量
@@ -346,59 +574,45 @@ func (z *ZSet) ZCard() int {
 
 // ZRank 获取有序集合的元素排名
 func (z *ZSet) ZRank(e string) int {
-	for rank, element := range z.sorted {
-		if element == e {
-			return rank + 1
-		}
+	if i := z.index(e); i >= 0 {
+		return i + 1
 	}
 	return ErrorRank
 }
 
 // ZRankWithScore 获取有序集合的元素排名和score
 func (z *ZSet) ZRankWithScore(e string) (int, float64) {
-	for rank, element := range z.sorted {
-		if e == element {
-			return rank + 1, z.elements[e]
-		}
+	if i := z.index(e); i >= 0 {
+		return i + 1, z.score(e)
 	}
 	return ErrorRank, DefaultScore
 }
 
 // ZRevRank 获取有序集合的元素倒数排名
 func (z *ZSet) ZRevRank(e string) int {
-	for i := len(z.sorted) - 1; i >= 0; i-- {
-		if z.sorted[i] == e {
-			return len(z.sorted) - i
-		}
+	if i := z.index(e); i >= 0 {
+		return len(z.sorted) - i
 	}
 	return ErrorRank
 }
 
 // ZRevRankWithScore 获取有序集合的元素倒数排名和score
 func (z *ZSet) ZRevRankWithScore(e string) (int, float64) {
-	for i := len(z.sorted) - 1; i >= 0; i-- {
-		if z.sorted[i] == e {
-			return len(z.sorted) - i, z.elements[e]
-		}
+	if i := z.index(e); i >= 0 {
+		return len(z.sorted) - i, z.score(e)
 	}
 	return ErrorRank, DefaultScore
 }
 
-// ZRange 获取有序集合区间元素
+// ZRange 获取有序集合区间元素，返回的切片为副本
 func (z *ZSet) ZRange(start, stop int) []string {
-	if start < 0 {
-		start = len(z.sorted) + start
+	start, stop, ok := rangeIndex(start, stop, len(z.sorted))
+	if !ok {
+		return []string{}
 	}
-	if stop < 0 {
-		stop = len(z.sorted) + stop
-	}
-	if start > stop || start >= len(z.sorted) {
-		return nil
-	}
-	if stop >= len(z.sorted) {
-		stop = len(z.sorted) - 1
-	}
-	return z.sorted[start : stop+1]
+	elements := make([]string, stop-start+1)
+	copy(elements, z.sorted[start:stop+1])
+	return elements
 }
 
 // ZRangeWithScores 获取有序集合区间元素包含Score
@@ -406,32 +620,23 @@ func (z *ZSet) ZRangeWithScores(start, stop int) map[string]float64 {
 	elements := z.ZRange(start, stop)
 	result := make(map[string]float64, len(elements))
 	for _, element := range elements {
-		result[element] = z.elements[element]
+		result[element] = z.score(element)
 	}
 	return result
 }
 
-// ZRevRange 获取有序集合倒排区间元素
+// ZRevRange 获取有序集合倒排区间元素，返回的切片为副本
 func (z *ZSet) ZRevRange(start, stop int) []string {
-	if start < 0 {
-		start = len(z.sorted) + start
+	n := len(z.sorted)
+	start, stop, ok := rangeIndex(start, stop, n)
+	if !ok {
+		return []string{}
 	}
-	if stop < 0 {
-		stop = len(z.sorted) + stop
+	elements := make([]string, stop-start+1)
+	for i := range elements {
+		elements[i] = z.sorted[n-1-start-i]
 	}
-	if start > stop || start >= len(z.sorted) {
-		return nil
-	}
-	if stop >= len(z.sorted) {
-		stop = len(z.sorted) - 1
-	}
-	// Reverse the slice
-	rev := make([]string, len(z.sorted))
-	copy(rev, z.sorted)
-	for i, j := 0, len(rev)-1; i < j; i, j = i+1, j-1 {
-		rev[i], rev[j] = rev[j], rev[i]
-	}
-	return rev[start : stop+1]
+	return elements
 }
 
 // ZRevRangeWithScore 获取有序集合倒排区间元素包含Score
@@ -439,11 +644,82 @@ func (z *ZSet) ZRevRangeWithScore(start, stop int) map[string]float64 {
 	elements := z.ZRevRange(start, stop)
 	result := make(map[string]float64, len(elements))
 	for _, element := range elements {
-		result[element] = z.elements[element]
+		result[element] = z.score(element)
 	}
 	return result
 }
 
+// sort 按score从高到低排列元素
+func (z *ZSet) sort() {
+	sort.Slice(z.sorted, func(i, j int) bool {
+		return zSetBefore(z.sorted[i], z.score(z.sorted[i]), z.sorted[j], z.score(z.sorted[j]))
+	})
+}
+
+// search 二分查找元素e按score应在sorted中的位置
+func (z *ZSet) search(e string, score float64) int {
+	return sort.Search(len(z.sorted), func(i int) bool {
+		return !zSetBefore(z.sorted[i], z.score(z.sorted[i]), e, score)
+	})
+}
+
+// index 获取元素在sorted中的位置，元素不存在时返回-1
+func (z *ZSet) index(e string) int {
+	score, exist := z.elements.get(e)
+	if !exist {
+		return -1
+	}
+	if i := z.search(e, score); i < len(z.sorted) && z.sorted[i] == e {
+		return i
+	}
+	return -1
+}
+
+// remove 从sorted中删除score为原有score的元素，需在elements更新前调用
+func (z *ZSet) remove(e string, score float64) {
+	if i := z.search(e, score); i < len(z.sorted) && z.sorted[i] == e {
+		z.sorted = append(z.sorted[:i], z.sorted[i+1:]...)
+	}
+}
+
+// rangeByScore 按score从低到高遍历score在[min, max]范围内的元素，fn 返回false时停止
+func (z *ZSet) rangeByScore(min, max float64, fn func(e string, score float64) bool) {
+	start := sort.Search(len(z.sorted), func(i int) bool {
+		return z.score(z.sorted[i]) <= max
+	})
+	end := sort.Search(len(z.sorted), func(i int) bool {
+		return z.score(z.sorted[i]) < min
+	})
+	for i := end - 1; i >= start; i-- {
+		if !fn(z.sorted[i], z.score(z.sorted[i])) {
+			return
+		}
+	}
+}
+
+// zSetBefore 判断元素a是否排在元素b之前
+func zSetBefore(a string, scoreA float64, b string, scoreB float64) bool {
+	if scoreA != scoreB {
+		return scoreA > scoreB
+	}
+	return a > b
+}
+
+// score 获取元素的score，元素不存在时返回 DefaultScore
+func (z *ZSet) score(e string) float64 {
+	score, _ := z.elements.get(e)
+	return score
+}
+
+// scores 获取所有元素和score，返回的map为副本
+func (z *ZSet) scores() map[string]float64 {
+	scores := make(map[string]float64, z.elements.len())
+	for _, e := range z.elements.entries {
+		scores[e.key] = e.value
+	}
+	return scores
+}
+
 // isExpired 判断一个元素是否过期
 func (z *ZSet) isExpired() bool {
 	if z.expiration != DefaultExpiration && time.Now().UnixNano() > z.expiration {
@@ -451,3 +727,47 @@ func (z *ZSet) isExpired() bool {
 	}
 	return false
 }
+
+// validate 校验运算参数
+func (s *ZStore) validate() error {
+	if len(s.Keys) == 0 {
+		return ErrZStoreKeys
+	}
+	if len(s.Weights) > 0 && len(s.Weights) != len(s.Keys) {
+		return ErrWeights
+	}
+	switch s.Aggregate {
+	case "", AggregateSum, AggregateMin, AggregateMax:
+		return nil
+	}
+	return ErrAggregate
+}
+
+// weighted 计算第i个key中score乘以权重后的值
+func (s *ZStore) weighted(i int, score float64) float64 {
+	if len(s.Weights) == 0 {
+		return score
+	}
+	score *= s.Weights[i]
+	if math.IsNaN(score) {
+		// inf * 0 按Redis的处理方式视为0
+		return 0
+	}
+	return score
+}
+
+// aggregate 聚合两个score
+func (s *ZStore) aggregate(a, b float64) float64 {
+	switch s.Aggregate {
+	case AggregateMin:
+		return math.Min(a, b)
+	case AggregateMax:
+		return math.Max(a, b)
+	}
+	sum := a + b
+	if math.IsNaN(sum) {
+		// +inf 与 -inf 相加按Redis的处理方式视为0
+		return 0
+	}
+	return sum
+}